@@ -2,13 +2,15 @@ package main
 
 import (
 	"context"
+	"fmt"
 	"log"
 	"os"
 
+	"vn-admin-api/internal/cache"
 	"vn-admin-api/internal/config"
 	"vn-admin-api/internal/crawler"
-	"vn-admin-api/internal/database"
 	"vn-admin-api/internal/logger"
+	"vn-admin-api/internal/storage"
 )
 
 func main() {
@@ -22,24 +24,39 @@ func main() {
 	appLog := logger.New("logs/crawler.log", false) // Set debug=true if needed
 	appLog.Info("Starting Application")
 
-	// 3. Connect DB
-	repo, err := database.Connect(cfg)
+	// 3. Open Storage (Postgres with schema version check, or in-memory)
+	repo, err := storage.Open(context.Background(), cfg)
 	if err != nil {
-		appLog.Error("Failed to connect to database", "error", err)
+		appLog.Error("Failed to open storage", "driver", cfg.StorageDriver, "error", err)
 		os.Exit(1)
 	}
 	defer repo.Close()
 
-	// 4. Init Schema (using embedded SQL)
-	if err := repo.InitSchema(database.SchemaSQL); err != nil {
-		appLog.Error("Failed to init schema", "error", err)
-		os.Exit(1)
+	// 4. Run Crawler, flushing the shared Redis cache once the crawl is published
+	var opts []crawler.Option
+	if cfg.RedisURL != "" {
+		redisCache, err := cache.NewRedisCache(cfg.RedisURL, cfg.CacheTTL)
+		if err != nil {
+			appLog.Warn("Redis connection failed, cached responses expire with their TTL", "error", err)
+		} else {
+			defer redisCache.Close()
+			opts = append(opts, crawler.WithCache(redisCache))
+		}
 	}
-
-	// 5. Run Crawler
-	c := crawler.New(repo, appLog, cfg)
-	if err := c.Run(context.Background()); err != nil {
+	c := crawler.New(repo, appLog, cfg, opts...)
+	report, err := c.Run(context.Background())
+	if err != nil {
 		appLog.Error("Crawler failed", "error", err)
 		os.Exit(1)
 	}
+	fmt.Print(report.Summary())
+
+	// 5. Persist snapshot when crawling into the memory store
+	if mem, ok := repo.(*storage.MemoryStore); ok {
+		if err := mem.SaveFile(cfg.DataFile); err != nil {
+			appLog.Error("Failed to save data file", "path", cfg.DataFile, "error", err)
+			os.Exit(1)
+		}
+		appLog.Info("Saved data file", "path", cfg.DataFile)
+	}
 }
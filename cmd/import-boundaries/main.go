@@ -0,0 +1,112 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+
+	"vn-admin-api/internal/config"
+	"vn-admin-api/internal/geo"
+	"vn-admin-api/internal/logger"
+	"vn-admin-api/internal/models"
+	"vn-admin-api/internal/storage"
+)
+
+const usage = `Usage: import-boundaries -kind province|unit [-match code|id|name] [-property name]
+                         [-crs wgs84|vn2000] [-central-meridian deg] file.geojson...
+
+Imports Polygon/MultiPolygon features from local GeoJSON FeatureCollections
+as province or unit boundaries. Features are linked by the given property
+(default: mahc/ma for code, id for id, name for name).
+
+With -crs vn2000, positions are VN-2000 TM3 [easting, northing] metres and are
+converted to WGS84 using the TM3 zone of each record's province, or the given
+-central-meridian.`
+
+func main() {
+	kind := flag.String("kind", "", "boundary kind: province or unit")
+	matchBy := flag.String("match", geo.MatchCode, "link features by code, id or name")
+	property := flag.String("property", "", "feature property holding the code/id/name")
+	crs := flag.String("crs", geo.CRSWGS84, "coordinate system of the input: wgs84 or vn2000")
+	meridian := flag.Float64("central-meridian", 0, "VN-2000 TM3 central meridian (default: per province)")
+	flag.Usage = func() { fmt.Fprintln(os.Stderr, usage) }
+	flag.Parse()
+
+	if (*kind != models.BoundaryProvince && *kind != models.BoundaryUnit) || flag.NArg() == 0 {
+		flag.Usage()
+		os.Exit(2)
+	}
+	if *matchBy != geo.MatchCode && *matchBy != geo.MatchID && *matchBy != geo.MatchName {
+		fmt.Fprintf(os.Stderr, "Unknown -match %q\n\n%s\n", *matchBy, usage)
+		os.Exit(2)
+	}
+	if *crs != geo.CRSWGS84 && *crs != geo.CRSVN2000 {
+		fmt.Fprintf(os.Stderr, "Unknown -crs %q\n\n%s\n", *crs, usage)
+		os.Exit(2)
+	}
+
+	// 1. Load Config
+	cfg, err := config.Load()
+	if err != nil {
+		fmt.Printf("Failed to load config: %v\n", err)
+		os.Exit(1)
+	}
+
+	// 2. Init Logger
+	appLog := logger.New("logs/import-boundaries.log", false)
+
+	// 3. Open Storage (Postgres with schema version check, or in-memory)
+	ctx := context.Background()
+	repo, err := storage.Open(ctx, cfg)
+	if err != nil {
+		appLog.Error("Failed to open storage", "driver", cfg.StorageDriver, "error", err)
+		os.Exit(1)
+	}
+	defer repo.Close()
+
+	// 4. Import Files
+	opts := geo.ImportOptions{Kind: *kind, MatchBy: *matchBy, Property: *property, CRS: *crs, CentralMeridian: *meridian}
+	failed := false
+	for _, path := range flag.Args() {
+		if err := importFile(ctx, repo, path, opts, appLog); err != nil {
+			appLog.Error("Import failed", "file", path, "error", err)
+			failed = true
+		}
+	}
+
+	// 5. Persist snapshot when importing into the memory store
+	if mem, ok := repo.(*storage.MemoryStore); ok {
+		if err := mem.SaveFile(cfg.DataFile); err != nil {
+			appLog.Error("Failed to save data file", "path", cfg.DataFile, "error", err)
+			os.Exit(1)
+		}
+		appLog.Info("Saved data file", "path", cfg.DataFile)
+	}
+	if failed {
+		os.Exit(1)
+	}
+}
+
+func importFile(ctx context.Context, store geo.ImportStore, path string, opts geo.ImportOptions, log *logger.Logger) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	report, err := geo.Import(ctx, store, f, opts)
+	if err != nil {
+		return err
+	}
+	for _, key := range report.Unmatched {
+		log.Warn("Feature not linked to any record", "file", path, "key", key)
+	}
+	for _, msg := range report.Invalid {
+		log.Warn("Invalid feature geometry", "file", path, "feature", msg)
+	}
+	log.Info("Imported boundaries", "file", path, "kind", opts.Kind,
+		"features", report.Features, "imported", report.Imported,
+		"unmatched", len(report.Unmatched), "invalid", len(report.Invalid))
+	return nil
+}
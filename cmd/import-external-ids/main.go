@@ -0,0 +1,90 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+
+	"vn-admin-api/internal/config"
+	"vn-admin-api/internal/crosswalk"
+	"vn-admin-api/internal/logger"
+	"vn-admin-api/internal/storage"
+)
+
+const usage = `Usage: import-external-ids -system name [-replace] file.csv
+
+Imports an outside system's identifiers (e.g. a shipping carrier: ghn, ghtk,
+viettel_post) for provinces and units. The CSV header must hold external_id
+and unit_id and/or province_id; each row fills exactly one of the two. An
+optional system column must match -system.
+
+Rows naming unknown records, or mapping an external ID twice, are logged and
+skipped. With -replace the system's stored IDs are replaced by the file's,
+and any invalid row aborts the import.`
+
+func main() {
+	system := flag.String("system", "", "outside system the IDs belong to")
+	replace := flag.Bool("replace", false, "replace every stored ID of the system")
+	flag.Usage = func() { fmt.Fprintln(os.Stderr, usage) }
+	flag.Parse()
+
+	if *system == "" || flag.NArg() != 1 {
+		flag.Usage()
+		os.Exit(2)
+	}
+	if _, err := crosswalk.NormalizeSystem(*system); err != nil {
+		fmt.Fprintf(os.Stderr, "%v\n\n%s\n", err, usage)
+		os.Exit(2)
+	}
+	path := flag.Arg(0)
+
+	// 1. Load Config
+	cfg, err := config.Load()
+	if err != nil {
+		fmt.Printf("Failed to load config: %v\n", err)
+		os.Exit(1)
+	}
+
+	// 2. Init Logger
+	appLog := logger.New("logs/import-external-ids.log", false)
+
+	// 3. Open Storage (Postgres with schema version check, or in-memory)
+	ctx := context.Background()
+	repo, err := storage.Open(ctx, cfg)
+	if err != nil {
+		appLog.Error("Failed to open storage", "driver", cfg.StorageDriver, "error", err)
+		os.Exit(1)
+	}
+	defer repo.Close()
+
+	// 4. Import
+	f, err := os.Open(path)
+	if err != nil {
+		appLog.Error("Failed to open file", "file", path, "error", err)
+		os.Exit(1)
+	}
+	defer f.Close()
+
+	report, err := crosswalk.Import(ctx, repo, f, *system, *replace)
+	if report != nil {
+		for _, msg := range report.Invalid {
+			appLog.Warn("Skipped row", "file", path, "row", msg)
+		}
+	}
+	if err != nil {
+		appLog.Error("Import failed", "file", path, "error", err)
+		os.Exit(1)
+	}
+	appLog.Info("Imported external IDs", "file", path, "system", *system, "replace", *replace,
+		"rows", report.Rows, "imported", report.Imported, "invalid", len(report.Invalid))
+
+	// 5. Persist snapshot when importing into the memory store
+	if mem, ok := repo.(*storage.MemoryStore); ok {
+		if err := mem.SaveFile(cfg.DataFile); err != nil {
+			appLog.Error("Failed to save data file", "path", cfg.DataFile, "error", err)
+			os.Exit(1)
+		}
+		appLog.Info("Saved data file", "path", cfg.DataFile)
+	}
+}
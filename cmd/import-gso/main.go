@@ -0,0 +1,86 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+
+	"vn-admin-api/internal/config"
+	"vn-admin-api/internal/gso"
+	"vn-admin-api/internal/logger"
+	"vn-admin-api/internal/storage"
+)
+
+const usage = `Usage: import-gso [-dry-run] file.csv|file.xlsx
+
+Imports the official GSO administrative code list and stores each code as the
+gso_code of the province or unit it reconciles with: by code when the
+accent-folded names agree, otherwise by name. Entries that cannot be
+reconciled, and stored records no entry links to, are logged.
+
+The file needs a header row with the province code and name columns
+("Mã TP", "Tỉnh / Thành Phố") and, for units, "Mã PX" and "Phường Xã".`
+
+func main() {
+	dryRun := flag.Bool("dry-run", false, "report mismatches without storing codes")
+	flag.Usage = func() { fmt.Fprintln(os.Stderr, usage) }
+	flag.Parse()
+
+	if flag.NArg() != 1 {
+		flag.Usage()
+		os.Exit(2)
+	}
+	path := flag.Arg(0)
+
+	// 1. Load Config
+	cfg, err := config.Load()
+	if err != nil {
+		fmt.Printf("Failed to load config: %v\n", err)
+		os.Exit(1)
+	}
+
+	// 2. Init Logger
+	appLog := logger.New("logs/import-gso.log", false)
+
+	// 3. Read the code list before touching storage
+	entries, err := gso.ReadFile(path)
+	if err != nil {
+		appLog.Error("Failed to read GSO code list", "file", path, "error", err)
+		os.Exit(1)
+	}
+
+	// 4. Open Storage (Postgres with schema version check, or in-memory)
+	ctx := context.Background()
+	repo, err := storage.Open(ctx, cfg)
+	if err != nil {
+		appLog.Error("Failed to open storage", "driver", cfg.StorageDriver, "error", err)
+		os.Exit(1)
+	}
+	defer repo.Close()
+
+	// 5. Reconcile
+	report, err := gso.Import(ctx, repo, entries, *dryRun)
+	if err != nil {
+		appLog.Error("Import failed", "file", path, "error", err)
+		os.Exit(1)
+	}
+	for _, m := range report.Mismatches {
+		appLog.Warn("GSO entry not reconciled", "kind", m.Kind, "gso_code", m.GSOCode, "name", m.Name, "reason", m.Reason)
+	}
+	for _, record := range report.Missing {
+		appLog.Warn("Record has no GSO entry", "record", record)
+	}
+	appLog.Info("Imported GSO codes", "file", path, "dry_run", *dryRun,
+		"entries", report.Entries, "provinces", report.Provinces, "units", report.Units,
+		"by_name", report.ByName, "mismatches", len(report.Mismatches), "missing", len(report.Missing))
+
+	// 6. Persist snapshot when importing into the memory store
+	if mem, ok := repo.(*storage.MemoryStore); ok && !*dryRun {
+		if err := mem.SaveFile(cfg.DataFile); err != nil {
+			appLog.Error("Failed to save data file", "path", cfg.DataFile, "error", err)
+			os.Exit(1)
+		}
+		appLog.Info("Saved data file", "path", cfg.DataFile)
+	}
+}
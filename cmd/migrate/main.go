@@ -0,0 +1,104 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strconv"
+	"text/tabwriter"
+	"time"
+
+	"vn-admin-api/internal/config"
+	"vn-admin-api/internal/database"
+	"vn-admin-api/internal/logger"
+)
+
+const usage = `Usage: migrate <command>
+
+Commands:
+  up          Apply all pending migrations
+  down [n]    Roll back the last n migrations (default 1)
+  status      Show applied and pending migrations`
+
+func main() {
+	if len(os.Args) < 2 {
+		fmt.Println(usage)
+		os.Exit(2)
+	}
+
+	// 1. Load Config
+	cfg, err := config.Load()
+	if err != nil {
+		fmt.Printf("Failed to load config: %v\n", err)
+		os.Exit(1)
+	}
+
+	// 2. Init Logger
+	appLog := logger.New("logs/migrate.log", false)
+
+	// 3. Connect DB
+	repo, err := database.Connect(cfg)
+	if err != nil {
+		appLog.Error("Failed to connect to database", "error", err)
+		os.Exit(1)
+	}
+	defer repo.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Minute)
+	defer cancel()
+
+	// 4. Run Command
+	switch cmd := os.Args[1]; cmd {
+	case "up":
+		ran, err := repo.MigrateUp(ctx)
+		for _, m := range ran {
+			appLog.Info("Applied migration", "version", m.Version, "name", m.Name)
+		}
+		if err != nil {
+			appLog.Error("Migration failed", "error", err)
+			os.Exit(1)
+		}
+		if len(ran) == 0 {
+			appLog.Info("Schema already up to date")
+		}
+
+	case "down":
+		steps := 1
+		if len(os.Args) > 2 {
+			steps, err = strconv.Atoi(os.Args[2])
+			if err != nil || steps < 1 {
+				fmt.Printf("Invalid step count %q\n", os.Args[2])
+				os.Exit(2)
+			}
+		}
+		ran, err := repo.MigrateDown(ctx, steps)
+		for _, m := range ran {
+			appLog.Info("Rolled back migration", "version", m.Version, "name", m.Name)
+		}
+		if err != nil {
+			appLog.Error("Rollback failed", "error", err)
+			os.Exit(1)
+		}
+
+	case "status":
+		status, err := repo.MigrationStatus(ctx)
+		if err != nil {
+			appLog.Error("Failed to read migration status", "error", err)
+			os.Exit(1)
+		}
+		tw := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+		fmt.Fprintln(tw, "VERSION\tNAME\tAPPLIED AT")
+		for _, s := range status {
+			applied := "pending"
+			if s.AppliedAt != nil {
+				applied = s.AppliedAt.Format(time.RFC3339)
+			}
+			fmt.Fprintf(tw, "%04d\t%s\t%s\n", s.Version, s.Name, applied)
+		}
+		tw.Flush()
+
+	default:
+		fmt.Printf("Unknown command %q\n\n%s\n", cmd, usage)
+		os.Exit(2)
+	}
+}
@@ -10,10 +10,13 @@ import (
 	"time"
 
 	"vn-admin-api/internal/api"
+	"vn-admin-api/internal/autocomplete"
 	"vn-admin-api/internal/cache"
 	"vn-admin-api/internal/config"
-	"vn-admin-api/internal/database"
+	"vn-admin-api/internal/geo"
+	"vn-admin-api/internal/jobs"
 	"vn-admin-api/internal/logger"
+	"vn-admin-api/internal/storage"
 )
 
 func main() {
@@ -27,14 +30,18 @@ func main() {
 	// 2. Init Logger
 	appLog := logger.New("logs/server.log", false)
 	appLog.Info("Starting API Server", "port", cfg.ServerPort)
+	for _, w := range cfg.Warnings {
+		appLog.Warn("Deprecated configuration", "warning", w)
+	}
 
-	// 3. Connect DB
-	repo, err := database.Connect(cfg)
+	// 3. Open Storage (Postgres with schema version check, or in-memory)
+	repo, err := storage.Open(context.Background(), cfg)
 	if err != nil {
-		appLog.Error("Failed to connect to database", "error", err)
+		appLog.Error("Failed to open storage", "driver", cfg.StorageDriver, "error", err)
 		os.Exit(1)
 	}
 	defer repo.Close()
+	appLog.Info("Storage ready", "driver", cfg.StorageDriver)
 
 	// 4. Initialize Cache (Redis or Memory fallback)
 	var appCache cache.Cache
@@ -52,10 +59,49 @@ func main() {
 		appCache = cache.NewMemoryCache(cfg.CacheTTL)
 	}
 
-	// 5. Create Router
-	router := api.NewRouterWithCache(repo, appLog, appCache)
+	// 5. Start Bulk Conversion Workers
+	bgCtx, stopBackground := context.WithCancel(context.Background())
+	jobManager := jobs.NewManager(repo, appLog, cfg.JobWorkers)
+	jobManager.Start(bgCtx)
+
+	// 6. Build In-Memory Indexes (refreshed in the background when data changes)
+	suggest := autocomplete.NewIndex(repo)
+	if _, err := suggest.Rebuild(context.Background()); err != nil {
+		appLog.Warn("Failed to build autocomplete index, will build on first request", "error", err)
+	} else {
+		appLog.Info("Autocomplete index built", "entries", suggest.Len())
+	}
+	go suggest.Run(bgCtx, cfg.IndexRefresh, appLog)
+
+	spatial := geo.NewIndex(repo)
+	if _, err := spatial.Rebuild(context.Background()); err != nil {
+		appLog.Warn("Failed to build spatial index, will build on first request", "error", err)
+	} else {
+		appLog.Info("Spatial index built", "units", spatial.Len())
+	}
+	go spatial.Run(bgCtx, cfg.IndexRefresh, appLog)
 
-	// 6. Configure Server with Production Timeouts
+	outlines := geo.NewBoundaryIndex(repo)
+	if _, err := outlines.Rebuild(context.Background()); err != nil {
+		appLog.Warn("Failed to build boundary index, will build on first request", "error", err)
+	} else {
+		provinces, units := outlines.Len()
+		appLog.Info("Boundary index built", "provinces", provinces, "units", units)
+	}
+	go outlines.Run(bgCtx, cfg.IndexRefresh, appLog)
+
+	// Cached responses go stale once a crawl is published; flush them when the data changes
+	go cache.Watch(bgCtx, appCache, repo, cfg.IndexRefresh, appLog)
+
+	// 7. Create Router
+	router := api.NewRouterWithCache(repo, appLog, appCache,
+		api.WithJobs(jobManager),
+		api.WithAutocomplete(suggest),
+		api.WithSpatialIndex(spatial),
+		api.WithBoundaryIndex(outlines),
+	)
+
+	// 8. Configure Server with Production Timeouts
 	server := &http.Server{
 		Addr:         ":" + cfg.ServerPort,
 		Handler:      router,
@@ -73,7 +119,7 @@ func main() {
 		}
 	}()
 
-	// 7. Graceful Shutdown
+	// 9. Graceful Shutdown
 	stop := make(chan os.Signal, 1)
 	signal.Notify(stop, os.Interrupt, syscall.SIGTERM)
 
@@ -87,5 +133,9 @@ func main() {
 		appLog.Error("Server forced to shutdown", "error", err)
 	}
 
+	// Running jobs are put back in the queue and resumed on next start
+	stopBackground()
+	jobManager.Wait()
+
 	appLog.Info("Server exited properly")
 }
@@ -0,0 +1,300 @@
+// Package address parses free-text Vietnamese addresses such as
+// "123 Lê Lợi, P. Bến Thành, Q.1, TP.HCM" into street, ward, district and province,
+// and links the administrative parts to stored provinces and units.
+package address
+
+import (
+	"strings"
+	"unicode"
+
+	"vn-admin-api/internal/lineage"
+	"vn-admin-api/internal/vntext"
+)
+
+// Component is one administrative part of an address
+type Component struct {
+	Level    string `json:"level,omitempty"`        // "Phường", "Quận", "Thành phố", ... (empty when not written)
+	Name     string `json:"name"`                   // Name as written, without the level word
+	ID       int    `json:"id,omitempty"`           // provinces.id / admin_units.id once resolved
+	Matched  string `json:"matched_name,omitempty"` // Canonical name of the linked record
+	Inferred bool   `json:"inferred,omitempty"`     // Not in the input; derived from the resolved ward
+}
+
+// String returns the level and name ("Phường Bến Thành")
+func (c *Component) String() string {
+	if c == nil {
+		return ""
+	}
+	if c.Level == "" {
+		return c.Name
+	}
+	return c.Level + " " + c.Name
+}
+
+// Address is a parsed free-text address
+type Address struct {
+	Input    string     `json:"input"`
+	Street   string     `json:"street,omitempty"` // House number, street, hamlet... everything below ward level
+	Ward     *Component `json:"ward,omitempty"`
+	District *Component `json:"district,omitempty"` // Pre-2025 district; districts no longer exist, so never linked
+	Province *Component `json:"province,omitempty"`
+}
+
+// Slots, ordered from the smallest unit up
+const (
+	slotStreet = iota
+	slotWard
+	slotDistrict
+	slotProvince
+	slotCount
+)
+
+// Abbreviations, longest first. digits allows the dot-less "Q1" / "P12" form.
+var abbreviations = []struct {
+	abbr   string
+	level  string
+	digits bool
+}{
+	{"t.p", "Thành phố", false},
+	{"tp", "Thành phố", false},
+	{"tx", "Thị xã", false},
+	{"tt", "Thị trấn", false},
+	{"p", "Phường", true},
+	{"f", "Phường", true}, // Saigon shorthand: "F12"
+	{"q", "Quận", true},
+	{"h", "Huyện", false},
+	{"x", "Xã", false},
+	{"t", "Tỉnh", false},
+}
+
+// Common province shorthands, keyed by folded text
+var provinceAliases = map[string]string{
+	"hcm":         "Hồ Chí Minh",
+	"tphcm":       "Hồ Chí Minh",
+	"tp hcm":      "Hồ Chí Minh",
+	"ho chi minh": "Hồ Chí Minh",
+	"sai gon":     "Hồ Chí Minh",
+	"saigon":      "Hồ Chí Minh",
+	"sg":          "Hồ Chí Minh",
+	"hn":          "Hà Nội",
+	"ha noi":      "Hà Nội",
+	"hanoi":       "Hà Nội",
+}
+
+// Words that start a below-ward part ("Số 5", "Ngõ 10", "Ấp 3")
+var streetWords = []string{
+	"số", "đường", "phố", "ngõ", "ngách", "hẻm", "kiệt", "tổ", "thôn", "ấp", "xóm",
+	"khu phố", "kp", "khóm", "bản", "tòa", "lô", "chung cư", "khu",
+}
+
+// Trailing parts that carry no information
+var countryNames = map[string]bool{"viet nam": true, "vietnam": true, "vn": true}
+
+type part struct {
+	text  string
+	level string
+	name  string
+	slot  int // slotStreet when the part has no level word
+}
+
+// Parse splits a free-text address into its components. It accepts the usual
+// abbreviations (P., Q., TP., TX., H.), text typed without diacritics and the
+// reversed "province, district, ward, street" order. Untyped parts are assigned by position.
+func Parse(raw string) Address {
+	addr := Address{Input: raw}
+
+	var parts []part
+	for _, text := range strings.FieldsFunc(raw, func(r rune) bool { return r == ',' || r == ';' || r == '\n' }) {
+		text = strings.Join(strings.Fields(text), " ")
+		if text == "" || countryNames[vntext.Fold(text)] {
+			continue
+		}
+		parts = append(parts, classify(text))
+	}
+	if len(parts) == 0 {
+		return addr
+	}
+	if isReversed(parts) {
+		for i, j := 0, len(parts)-1; i < j; i, j = i+1, j-1 {
+			parts[i], parts[j] = parts[j], parts[i]
+		}
+	}
+
+	assigned := make([]int, len(parts)) // slot per part, slotStreet = street detail
+	var filled [slotCount]bool
+	for i, p := range parts {
+		if p.slot != slotStreet && !filled[p.slot] {
+			assigned[i], filled[p.slot] = p.slot, true
+		}
+	}
+
+	// An untyped last part is the province ("..., Đà Nẵng")
+	last := len(parts) - 1
+	if !filled[slotProvince] && parts[last].slot == slotStreet && len(parts) > 1 && !looksLikeStreet(parts[last].text) {
+		assigned[last], filled[slotProvince] = slotProvince, true
+	}
+	assignGaps(parts, assigned, &filled)
+
+	var street []string
+	for i, p := range parts {
+		c := &Component{Level: p.level, Name: p.name}
+		switch assigned[i] {
+		case slotWard:
+			addr.Ward = c
+		case slotDistrict:
+			addr.District = c
+		case slotProvince:
+			addr.Province = c
+		default:
+			street = append(street, p.text)
+		}
+	}
+	addr.Street = strings.Join(street, ", ")
+	return addr
+}
+
+// assignGaps fills free ward/district slots with untyped parts lying between typed ones.
+// Within a gap the parts closest to the typed part above are used; with fewer parts than
+// free slots the ward wins, since post-2025 addresses have no district.
+func assignGaps(parts []part, assigned []int, filled *[slotCount]bool) {
+	lower := slotStreet
+	var gap []int
+	for i := 0; i <= len(parts); i++ {
+		upper := slotCount
+		if i < len(parts) {
+			if assigned[i] == slotStreet {
+				if !looksLikeStreet(parts[i].text) {
+					gap = append(gap, i)
+				}
+				continue
+			}
+			upper = assigned[i]
+		}
+
+		var free []int
+		for s := lower + 1; s < upper && s < slotProvince; s++ {
+			if !filled[s] {
+				free = append(free, s)
+			}
+		}
+		if len(gap) > len(free) {
+			gap = gap[len(gap)-len(free):]
+		}
+		for k, idx := range gap {
+			assigned[idx], filled[free[k]] = free[k], true
+		}
+
+		gap = nil
+		if i < len(parts) {
+			lower = upper
+		}
+	}
+}
+
+// classify expands abbreviations and reads the level word of one part
+func classify(text string) part {
+	p := part{text: text}
+	expanded := expandAbbreviation(text)
+
+	level, name := lineage.SplitLevel(expanded)
+	if canonical, ok := provinceAliases[vntext.Fold(name)]; ok && (level == "" || level == "Thành phố") {
+		p.level, p.name, p.slot = "Thành phố", canonical, slotProvince
+		return p
+	}
+
+	p.level, p.name = level, name
+	switch level {
+	case "Phường", "Xã", "Thị trấn", "Đặc khu":
+		p.slot = slotWard
+	case "Quận", "Huyện", "Thị xã":
+		p.slot = slotDistrict
+	case "Tỉnh":
+		p.slot = slotProvince
+	case "Thành phố":
+		p.slot = slotDistrict
+		if lineage.IsCentralCity(name) {
+			p.slot = slotProvince
+		}
+	default:
+		p.name = text
+	}
+	return p
+}
+
+// isReversed detects "province, district, ward, street" ordering
+func isReversed(parts []part) bool {
+	first, last := -1, -1
+	for _, p := range parts {
+		if p.slot == slotStreet {
+			continue
+		}
+		if first < 0 {
+			first = p.slot
+		}
+		last = p.slot
+	}
+	if first >= 0 && first != last {
+		return first > last
+	}
+	// Only one kind of typed part: the house number tells which end is the street
+	return len(parts) > 1 && startsWithDigit(parts[len(parts)-1].text) && !startsWithDigit(parts[0].text)
+}
+
+// expandAbbreviation rewrites a leading abbreviation ("Q.1", "TP.HCM", "TX Sơn Tây") as the full level word
+func expandAbbreviation(text string) string {
+	for _, a := range abbreviations {
+		if len(text) <= len(a.abbr) || !strings.EqualFold(text[:len(a.abbr)], a.abbr) {
+			continue
+		}
+		rest := text[len(a.abbr):]
+		switch {
+		case rest[0] == '.':
+			rest = rest[1:]
+		case rest[0] == ' ' && len(a.abbr) > 1:
+		case a.digits && rest[0] >= '0' && rest[0] <= '9':
+		default:
+			continue
+		}
+		if rest = strings.TrimSpace(rest); rest != "" {
+			return a.level + " " + rest
+		}
+	}
+	return text
+}
+
+// looksLikeStreet reports whether an untyped part is below ward level
+func looksLikeStreet(text string) bool {
+	if startsWithDigit(text) {
+		return true
+	}
+	words := strings.Fields(text)
+	folded := !vntext.HasDiacritics(text)
+	for _, sw := range streetWords {
+		pw := strings.Fields(sw)
+		if len(words) <= len(pw) {
+			continue
+		}
+		matched := true
+		for i, w := range pw {
+			got := strings.ToLower(words[i])
+			if folded {
+				got, w = vntext.Fold(got), vntext.Fold(w)
+			}
+			if got != w {
+				matched = false
+				break
+			}
+		}
+		if matched {
+			return true
+		}
+	}
+	return false
+}
+
+func startsWithDigit(s string) bool {
+	for _, r := range s {
+		return unicode.IsDigit(r)
+	}
+	return false
+}
@@ -0,0 +1,87 @@
+package address
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  Address
+	}{
+		{
+			name:  "abbreviations",
+			input: "123 Lê Lợi, P. Bến Thành, Q.1, TP.HCM",
+			want: Address{
+				Street:   "123 Lê Lợi",
+				Ward:     &Component{Level: "Phường", Name: "Bến Thành"},
+				District: &Component{Level: "Quận", Name: "1"},
+				Province: &Component{Level: "Thành phố", Name: "Hồ Chí Minh"},
+			},
+		},
+		{
+			name:  "reversed order",
+			input: "TP. Hồ Chí Minh, Quận 1, Phường Bến Thành, 123 Lê Lợi",
+			want: Address{
+				Street:   "123 Lê Lợi",
+				Ward:     &Component{Level: "Phường", Name: "Bến Thành"},
+				District: &Component{Level: "Quận", Name: "1"},
+				Province: &Component{Level: "Thành phố", Name: "Hồ Chí Minh"},
+			},
+		},
+		{
+			name:  "without diacritics",
+			input: "so 5 ngo 10 pho hue, phuong hang bai, quan hai ba trung, ha noi, Viet Nam",
+			want: Address{
+				Street:   "so 5 ngo 10 pho hue",
+				Ward:     &Component{Level: "Phường", Name: "hang bai"},
+				District: &Component{Level: "Quận", Name: "hai ba trung"},
+				Province: &Component{Level: "Thành phố", Name: "Hà Nội"},
+			},
+		},
+		{
+			name:  "untyped ward and province",
+			input: "12 Lê Lợi, Bến Thành, HCM",
+			want: Address{
+				Street:   "12 Lê Lợi",
+				Ward:     &Component{Name: "Bến Thành"},
+				Province: &Component{Level: "Thành phố", Name: "Hồ Chí Minh"},
+			},
+		},
+		{
+			name:  "district-level city and street that starts like a level word",
+			input: "Xa Lộ Hà Nội, F.Thảo Điền, TP Thủ Đức, Sài Gòn",
+			want: Address{
+				Street:   "Xa Lộ Hà Nội",
+				Ward:     &Component{Level: "Phường", Name: "Thảo Điền"},
+				District: &Component{Level: "Thành phố", Name: "Thủ Đức"},
+				Province: &Component{Level: "Thành phố", Name: "Hồ Chí Minh"},
+			},
+		},
+		{
+			name:  "town and province",
+			input: "Thôn 3, X. Đường Lâm, TX. Sơn Tây, T. Hà Tây",
+			want: Address{
+				Street:   "Thôn 3",
+				Ward:     &Component{Level: "Xã", Name: "Đường Lâm"},
+				District: &Component{Level: "Thị xã", Name: "Sơn Tây"},
+				Province: &Component{Level: "Tỉnh", Name: "Hà Tây"},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Parse(tt.input)
+			tt.want.Input = tt.input
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Parse(%q)\n got  %s\n want %s", tt.input, describe(got), describe(tt.want))
+			}
+		})
+	}
+}
+
+func describe(a Address) string {
+	return "street=" + a.Street + " ward=" + a.Ward.String() + " district=" + a.District.String() + " province=" + a.Province.String()
+}
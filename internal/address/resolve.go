@@ -0,0 +1,92 @@
+package address
+
+import (
+	"context"
+
+	"vn-admin-api/internal/lineage"
+	"vn-admin-api/internal/models"
+	"vn-admin-api/internal/search"
+	"vn-admin-api/internal/vntext"
+)
+
+// Store is the subset of storage.Store the resolver reads from
+type Store interface {
+	GetProvinces(ctx context.Context) ([]models.Province, error)
+	SearchAdminUnits(ctx context.Context, params models.SearchParams) ([]models.SearchResult, error)
+}
+
+// Resolver links parsed components to stored provinces and units
+type Resolver struct {
+	store Store
+}
+
+func NewResolver(store Store) *Resolver {
+	return &Resolver{store: store}
+}
+
+// Resolve sets ID and Matched on the province and ward when they match exactly one record.
+// A resolved ward also fills in a missing province.
+func (r *Resolver) Resolve(ctx context.Context, addr *Address) error {
+	provinces, err := r.store.GetProvinces(ctx)
+	if err != nil {
+		return err
+	}
+	byID := make(map[int]models.Province, len(provinces))
+	for _, p := range provinces {
+		byID[p.ID] = p
+		if addr.Province != nil && sameName(p.Name, addr.Province.Name) {
+			addr.Province.ID, addr.Province.Matched = p.ID, p.Name
+		}
+	}
+
+	if addr.Ward == nil {
+		return nil
+	}
+	unit, err := r.findWard(ctx, addr.Ward, addr.Province)
+	if err != nil || unit == nil {
+		return err
+	}
+	addr.Ward.ID, addr.Ward.Matched = unit.ID, unit.Name
+
+	if p, ok := byID[unit.ProvinceID]; ok && addr.Province == nil {
+		level, name := lineage.SplitLevel(p.Name)
+		addr.Province = &Component{Level: level, Name: name, ID: p.ID, Matched: p.Name, Inferred: true}
+	}
+	return nil
+}
+
+// findWard returns the only current unit named like the ward, or nil when none or several match
+func (r *Resolver) findWard(ctx context.Context, ward, province *Component) (*models.AdminUnit, error) {
+	results, err := r.store.SearchAdminUnits(ctx, models.SearchParams{Query: ward.String(), Limit: search.DefaultLimit})
+	if err != nil {
+		return nil, err
+	}
+
+	var matches, sameLevel []models.AdminUnit
+	for _, res := range results {
+		if !sameName(res.Name, ward.Name) {
+			continue
+		}
+		if province != nil && province.ID != 0 && res.ProvinceID != province.ID {
+			continue
+		}
+		matches = append(matches, res.AdminUnit)
+		if res.Level == ward.Level {
+			sameLevel = append(sameLevel, res.AdminUnit)
+		}
+	}
+	if len(sameLevel) > 0 {
+		matches = sameLevel
+	}
+	if len(matches) != 1 {
+		return nil, nil
+	}
+	return &matches[0], nil
+}
+
+// sameName compares a stored name with a parsed one, ignoring level words, case and diacritics
+func sameName(stored, parsed string) bool {
+	_, stored = lineage.SplitLevel(stored)
+	_, parsed = lineage.SplitLevel(parsed)
+	return parsed != "" && vntext.Fold(stored) == vntext.Fold(parsed)
+}
@@ -1,36 +1,65 @@
 package api
 
 import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
+	"errors"
 	"net/http"
 	"strconv"
+	"strings"
 	"time"
 
+	"vn-admin-api/internal/address"
+	"vn-admin-api/internal/autocomplete"
 	"vn-admin-api/internal/cache"
-	"vn-admin-api/internal/database"
+	"vn-admin-api/internal/convert"
+	"vn-admin-api/internal/geo"
+	"vn-admin-api/internal/jobs"
+	"vn-admin-api/internal/levels"
 	"vn-admin-api/internal/logger"
+	"vn-admin-api/internal/models"
+	"vn-admin-api/internal/search"
+	"vn-admin-api/internal/storage"
 )
 
 type Handler struct {
-	repo  *database.Repository
-	log   *logger.Logger
-	cache cache.Cache // Interface - can be MemoryCache or RedisCache
+	repo      storage.Store
+	log       *logger.Logger
+	cache     cache.Cache // Interface - can be MemoryCache or RedisCache
+	converter *convert.Converter
+	resolver  *address.Resolver
+	suggest   *autocomplete.Index
+	spatial   *geo.Index
+	outlines  *geo.BoundaryIndex
+	jobs      *jobs.Manager // nil disables the job endpoints
 }
 
-func NewHandler(repo *database.Repository, log *logger.Logger) *Handler {
+func NewHandler(repo storage.Store, log *logger.Logger) *Handler {
 	return &Handler{
-		repo:  repo,
-		log:   log,
-		cache: cache.NewMemoryCache(5 * time.Minute), // Default: in-memory
+		repo:      repo,
+		log:       log,
+		cache:     cache.NewMemoryCache(5 * time.Minute), // Default: in-memory
+		converter: convert.New(repo),
+		resolver:  address.NewResolver(repo),
+		suggest:   autocomplete.NewIndex(repo),
+		spatial:   geo.NewIndex(repo),
+		outlines:  geo.NewBoundaryIndex(repo),
 	}
 }
 
 // NewHandlerWithCache allows injecting custom cache (e.g., Redis)
-func NewHandlerWithCache(repo *database.Repository, log *logger.Logger, c cache.Cache) *Handler {
+func NewHandlerWithCache(repo storage.Store, log *logger.Logger, c cache.Cache) *Handler {
 	return &Handler{
-		repo:  repo,
-		log:   log,
-		cache: c,
+		repo:      repo,
+		log:       log,
+		cache:     c,
+		converter: convert.New(repo),
+		resolver:  address.NewResolver(repo),
+		suggest:   autocomplete.NewIndex(repo),
+		spatial:   geo.NewIndex(repo),
+		outlines:  geo.NewBoundaryIndex(repo),
 	}
 }
 
@@ -49,6 +78,55 @@ func (h *Handler) respondSuccess(w http.ResponseWriter, data interface{}) {
 	}
 }
 
+// respondWithETag writes data like respondSuccess with a strong ETag over the body, and answers
+// 304 Not Modified when If-None-Match already holds it
+func (h *Handler) respondWithETag(w http.ResponseWriter, r *http.Request, data interface{}) {
+	body, err := json.Marshal(map[string]interface{}{"data": data})
+	if err != nil {
+		h.log.Error("Failed to encode response", "error", err)
+		h.respondError(w, http.StatusInternalServerError, "Internal Server Error")
+		return
+	}
+	sum := sha256.Sum256(body)
+	etag := `"` + hex.EncodeToString(sum[:16]) + `"`
+
+	w.Header().Set("ETag", etag)
+	if etagMatches(r.Header.Get("If-None-Match"), etag) {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	if _, err := w.Write(append(body, '\n')); err != nil {
+		h.log.Error("Failed to write response", "error", err)
+	}
+}
+
+// etagMatches implements the weak comparison If-None-Match uses
+func etagMatches(header, etag string) bool {
+	for _, candidate := range strings.Split(header, ",") {
+		candidate = strings.TrimPrefix(strings.TrimSpace(candidate), "W/")
+		if candidate == "*" || candidate == etag {
+			return true
+		}
+	}
+	return false
+}
+
+func (h *Handler) respondSuccessWithMeta(w http.ResponseWriter, data interface{}, meta map[string]interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+
+	response := map[string]interface{}{
+		"data": data,
+		"meta": meta,
+	}
+
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		h.log.Error("Failed to encode response", "error", err)
+	}
+}
+
 func (h *Handler) respondError(w http.ResponseWriter, code int, message string) {
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(code)
@@ -73,7 +151,7 @@ func (h *Handler) HealthCheck(w http.ResponseWriter, r *http.Request) {
 
 // ReadyCheck checks if the service is ready (readiness probe)
 func (h *Handler) ReadyCheck(w http.ResponseWriter, r *http.Request) {
-	if err := h.repo.DB().Ping(); err != nil {
+	if err := h.repo.Ping(r.Context()); err != nil {
 		h.log.Error("Readiness check failed", "error", err)
 		w.Header().Set("Content-Type", "application/json")
 		w.WriteHeader(http.StatusServiceUnavailable)
@@ -100,30 +178,43 @@ func (h *Handler) Root(w http.ResponseWriter, r *http.Request) {
 
 // API Handlers
 
-// GetProvinces handles GET /api/v1/provinces
-func (h *Handler) GetProvinces(w http.ResponseWriter, r *http.Request) {
-	ctx := r.Context()
+// GetLevels handles GET /api/v1/levels: the administrative level taxonomy
+func (h *Handler) GetLevels(w http.ResponseWriter, r *http.Request) {
+	h.respondSuccess(w, levels.All)
+}
 
-	// Check cache first
-	if cached, ok := h.cache.GetProvinces(ctx); ok {
-		h.respondSuccess(w, cached)
+// GetProvinces handles GET /api/v1/provinces (?format=geojson for a FeatureCollection)
+func (h *Handler) GetProvinces(w http.ResponseWriter, r *http.Request) {
+	geojson, ok := h.geoJSONFormat(w, r)
+	if !ok {
+		return
+	}
+	asOf, err := parseAsOf(r.URL.Query())
+	if err != nil {
+		h.respondError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	includeRetired, err := parseIncludeRetired(r.URL.Query(), asOf)
+	if err != nil {
+		h.respondError(w, http.StatusBadRequest, err.Error())
 		return
 	}
 
-	provinces, err := h.repo.GetProvinces(ctx)
+	provinces, err := h.provincesAt(r.Context(), asOf, includeRetired)
 	if err != nil {
 		h.log.Error("Failed to get provinces", "error", err)
 		h.respondError(w, http.StatusInternalServerError, "Internal Server Error")
 		return
 	}
 
-	// Store in cache (ignore error for cache)
-	_ = h.cache.SetProvinces(ctx, provinces)
-
+	if geojson {
+		h.respondProvincesGeoJSON(w, r, provinces)
+		return
+	}
 	h.respondSuccess(w, provinces)
 }
 
-// GetUnitsByProvince handles GET /api/v1/provinces/{id}/units
+// GetUnitsByProvince handles GET /api/v1/provinces/{id}/units (?format=geojson for a FeatureCollection)
 func (h *Handler) GetUnitsByProvince(w http.ResponseWriter, r *http.Request) {
 	idStr := r.PathValue("id")
 	id, err := strconv.Atoi(idStr)
@@ -131,29 +222,115 @@ func (h *Handler) GetUnitsByProvince(w http.ResponseWriter, r *http.Request) {
 		h.respondError(w, http.StatusBadRequest, "Invalid Province ID")
 		return
 	}
+	geojson, ok := h.geoJSONFormat(w, r)
+	if !ok {
+		return
+	}
 
-	ctx := r.Context()
-
-	// Check cache first
-	if cached, ok := h.cache.GetUnits(ctx, id); ok {
-		h.respondSuccess(w, cached)
+	level, err := parseLevel(r.URL.Query().Get("level"))
+	if err != nil {
+		h.respondError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	asOf, err := parseAsOf(r.URL.Query())
+	if err != nil {
+		h.respondError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	includeRetired, err := parseIncludeRetired(r.URL.Query(), asOf)
+	if err != nil {
+		h.respondError(w, http.StatusBadRequest, err.Error())
 		return
 	}
 
-	units, err := h.repo.GetUnitsByProvince(ctx, id)
+	ctx := r.Context()
+	province, err := h.findProvinceAt(ctx, asOf, includeRetired, func(p models.Province) bool { return p.ID == id })
 	if err != nil {
-		h.log.Error("Failed to get units", "province_id", id, "error", err)
+		h.log.Error("Failed to get provinces", "error", err)
 		h.respondError(w, http.StatusInternalServerError, "Internal Server Error")
 		return
 	}
+	if province == nil {
+		h.respondError(w, http.StatusNotFound, "Province not found")
+		return
+	}
+
+	// Check cache first; past states are read from the history
+	units, cached := h.cache.GetUnits(ctx, id)
+	if !asOf.IsZero() {
+		units, err = h.repo.GetUnitsAsOf(ctx, asOf, id)
+		if err != nil {
+			h.log.Error("Failed to get units", "province_id", id, "as_of", asOf, "error", err)
+			h.respondError(w, http.StatusInternalServerError, "Internal Server Error")
+			return
+		}
+	} else if !cached {
+		units, err = h.repo.GetUnitsByProvince(ctx, id)
+		if err != nil {
+			h.log.Error("Failed to get units", "province_id", id, "error", err)
+			h.respondError(w, http.StatusInternalServerError, "Internal Server Error")
+			return
+		}
 
-	// Store in cache
-	_ = h.cache.SetUnits(ctx, id, units)
+		// Store in cache
+		_ = h.cache.SetUnits(ctx, id, units)
+	}
+	if includeRetired {
+		if units, err = h.withRetiredUnits(ctx, id, units); err != nil {
+			h.log.Error("Failed to get retired units", "province_id", id, "error", err)
+			h.respondError(w, http.StatusInternalServerError, "Internal Server Error")
+			return
+		}
+	}
+	if level != "" {
+		// Filter into a new slice: the cached one may be shared
+		filtered := make([]models.AdminUnit, 0, len(units))
+		for _, u := range units {
+			if u.LevelCode == level {
+				filtered = append(filtered, u)
+			}
+		}
+		units = filtered
+	}
 
+	if geojson {
+		h.respondUnitsGeoJSON(w, r, units)
+		return
+	}
 	h.respondSuccess(w, units)
 }
 
-// Search handles GET /api/v1/search?q=...
+// GetPredecessors handles GET /api/v1/units/{id}/predecessors
+func (h *Handler) GetPredecessors(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		h.respondError(w, http.StatusBadRequest, "Invalid Unit ID")
+		return
+	}
+
+	ctx := r.Context()
+	if _, err := h.repo.GetAdminUnit(ctx, id); err != nil {
+		if errors.Is(err, storage.ErrNotFound) {
+			h.respondError(w, http.StatusNotFound, "Unit not found")
+			return
+		}
+		h.log.Error("Failed to get unit", "unit_id", id, "error", err)
+		h.respondError(w, http.StatusInternalServerError, "Internal Server Error")
+		return
+	}
+
+	preds, err := h.repo.GetPredecessors(ctx, id)
+	if err != nil {
+		h.log.Error("Failed to get predecessors", "unit_id", id, "error", err)
+		h.respondError(w, http.StatusInternalServerError, "Internal Server Error")
+		return
+	}
+	h.respondSuccess(w, preds)
+}
+
+// Search handles GET /api/v1/search?q=...&fuzzy=true|false&min_score=0.3&as_of=YYYY-MM-DD&include_retired=true
+// Results are accent-insensitive and ranked by score; when nothing matches exactly,
+// meta.did_you_mean carries the closest unit name. as_of searches the units as they were then.
 func (h *Handler) Search(w http.ResponseWriter, r *http.Request) {
 	query := r.URL.Query().Get("q")
 	if len(query) < 2 {
@@ -161,12 +338,301 @@ func (h *Handler) Search(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	params := models.SearchParams{Query: query, Limit: search.DefaultLimit, MinScore: search.DefaultMinScore}
+	level, err := parseLevel(r.URL.Query().Get("level"))
+	if err != nil {
+		h.respondError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	params.Level = level
+	asOf, err := parseAsOf(r.URL.Query())
+	if err != nil {
+		h.respondError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if params.IncludeRetired, err = parseIncludeRetired(r.URL.Query(), asOf); err != nil {
+		h.respondError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if v := r.URL.Query().Get("fuzzy"); v != "" {
+		fuzzy, err := strconv.ParseBool(v)
+		if err != nil {
+			h.respondError(w, http.StatusBadRequest, "Invalid fuzzy (must be true or false)")
+			return
+		}
+		params.Fuzzy = fuzzy
+	}
+	if v := r.URL.Query().Get("min_score"); v != "" {
+		minScore, err := strconv.ParseFloat(v, 64)
+		if err != nil || minScore <= 0 || minScore > 1 {
+			h.respondError(w, http.StatusBadRequest, "Invalid min_score (must be in (0, 1])")
+			return
+		}
+		params.MinScore = minScore
+	}
+
 	ctx := r.Context()
-	units, err := h.repo.SearchAdminUnits(ctx, query)
+	if !asOf.IsZero() {
+		results, suggestion, err := h.searchAsOf(ctx, params, asOf)
+		if err != nil {
+			h.log.Error("Failed to search units", "query", query, "as_of", asOf, "error", err)
+			h.respondError(w, http.StatusInternalServerError, "Internal Server Error")
+			return
+		}
+		h.respondSearch(w, results, suggestion)
+		return
+	}
+
+	results, err := h.repo.SearchAdminUnits(ctx, params)
 	if err != nil {
 		h.log.Error("Failed to search units", "query", query, "error", err)
 		h.respondError(w, http.StatusInternalServerError, "Internal Server Error")
 		return
 	}
-	h.respondSuccess(w, units)
+
+	if hasExactMatch(results) {
+		h.respondSuccess(w, results)
+		return
+	}
+
+	suggestion, err := h.repo.SuggestUnitName(ctx, query)
+	if err != nil {
+		// Suggestions are best-effort; still return the results
+		h.log.Warn("Failed to suggest unit name", "query", query, "error", err)
+	}
+	h.respondSearch(w, results, suggestion)
+}
+
+// respondSearch writes search results, with meta.did_you_mean when there is a suggestion
+func (h *Handler) respondSearch(w http.ResponseWriter, results []models.SearchResult, suggestion string) {
+	if suggestion == "" {
+		h.respondSuccess(w, results)
+		return
+	}
+	h.respondSuccessWithMeta(w, results, map[string]interface{}{"did_you_mean": suggestion})
+}
+
+func hasExactMatch(results []models.SearchResult) bool {
+	for _, r := range results {
+		if r.Match == models.MatchExact {
+			return true
+		}
+	}
+	return false
+}
+
+// Autocomplete handles GET /api/v1/autocomplete?q=...&province_id=...&limit=...
+// Served from the in-memory prefix index; built on first use when the server has not built it yet.
+func (h *Handler) Autocomplete(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query().Get("q")
+	if strings.TrimSpace(query) == "" {
+		h.respondError(w, http.StatusBadRequest, "Query is required")
+		return
+	}
+
+	provinceID := 0
+	if v := r.URL.Query().Get("province_id"); v != "" {
+		id, err := strconv.Atoi(v)
+		if err != nil || id <= 0 {
+			h.respondError(w, http.StatusBadRequest, "Invalid province_id")
+			return
+		}
+		provinceID = id
+	}
+	limit := autocomplete.DefaultLimit
+	if v := r.URL.Query().Get("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n <= 0 || n > autocomplete.MaxLimit {
+			h.respondError(w, http.StatusBadRequest, "Invalid limit (must be 1-50)")
+			return
+		}
+		limit = n
+	}
+
+	if !h.suggest.Ready() {
+		if _, err := h.suggest.Rebuild(r.Context()); err != nil {
+			h.log.Error("Failed to build autocomplete index", "error", err)
+			h.respondError(w, http.StatusInternalServerError, "Internal Server Error")
+			return
+		}
+	}
+	h.respondSuccess(w, h.suggest.Lookup(query, provinceID, limit))
+}
+
+// Number of units returned by GET /api/v1/reverse
+const (
+	defaultReverseLimit = 5
+	maxReverseLimit     = 50
+)
+
+// Reverse handles GET /api/v1/reverse?lat=...&lng=...&limit=...
+// Returns the units whose centroids are nearest to the coordinate, with their distance in km.
+func (h *Handler) Reverse(w http.ResponseWriter, r *http.Request) {
+	lat, errLat := strconv.ParseFloat(r.URL.Query().Get("lat"), 64)
+	lng, errLng := strconv.ParseFloat(r.URL.Query().Get("lng"), 64)
+	if errLat != nil || errLng != nil || !geo.ValidCoordinate(lat, lng) {
+		h.respondError(w, http.StatusBadRequest, "Invalid lat/lng")
+		return
+	}
+	limit := defaultReverseLimit
+	if v := r.URL.Query().Get("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n <= 0 || n > maxReverseLimit {
+			h.respondError(w, http.StatusBadRequest, "Invalid limit (must be 1-50)")
+			return
+		}
+		limit = n
+	}
+
+	if !h.spatial.Ready() {
+		if _, err := h.spatial.Rebuild(r.Context()); err != nil {
+			h.log.Error("Failed to build spatial index", "error", err)
+			h.respondError(w, http.StatusInternalServerError, "Internal Server Error")
+			return
+		}
+	}
+	h.respondSuccess(w, h.spatial.Nearest(lat, lng, limit))
+}
+
+// Locate handles GET /api/v1/locate?lat=...&lng=...
+// Returns the province and unit whose imported boundary polygons contain the coordinate.
+func (h *Handler) Locate(w http.ResponseWriter, r *http.Request) {
+	lat, errLat := strconv.ParseFloat(r.URL.Query().Get("lat"), 64)
+	lng, errLng := strconv.ParseFloat(r.URL.Query().Get("lng"), 64)
+	if errLat != nil || errLng != nil || !geo.ValidCoordinate(lat, lng) {
+		h.respondError(w, http.StatusBadRequest, "Invalid lat/lng")
+		return
+	}
+
+	ctx := r.Context()
+	if !h.outlines.Ready() {
+		if _, err := h.outlines.Rebuild(ctx); err != nil {
+			h.log.Error("Failed to build boundary index", "error", err)
+			h.respondError(w, http.StatusInternalServerError, "Internal Server Error")
+			return
+		}
+	}
+	provinceID, unitID := h.outlines.Locate(lat, lng)
+	if provinceID == 0 && unitID == 0 {
+		h.respondError(w, http.StatusNotFound, "No boundary contains this point")
+		return
+	}
+
+	var loc models.Location
+	if unitID != 0 {
+		unit, err := h.repo.GetAdminUnit(ctx, unitID)
+		if err != nil {
+			h.log.Error("Failed to get unit", "unit_id", unitID, "error", err)
+			h.respondError(w, http.StatusInternalServerError, "Internal Server Error")
+			return
+		}
+		loc.Unit = unit
+		if provinceID == 0 {
+			// Only ward outlines loaded: the unit still tells the province
+			provinceID = unit.ProvinceID
+		}
+	}
+	province, err := h.findProvince(ctx, provinceID)
+	if err != nil {
+		h.log.Error("Failed to get provinces", "error", err)
+		h.respondError(w, http.StatusInternalServerError, "Internal Server Error")
+		return
+	}
+	loc.Province = province
+	h.respondSuccess(w, loc)
+}
+
+// findProvince returns the province with the given ID from the cached list, or nil
+func (h *Handler) findProvince(ctx context.Context, id int) (*models.Province, error) {
+	return h.findProvinceWhere(ctx, func(p models.Province) bool { return p.ID == id })
+}
+
+// findProvinceWhere returns the first cached province matching, or nil when none does
+func (h *Handler) findProvinceWhere(ctx context.Context, match func(models.Province) bool) (*models.Province, error) {
+	return h.findProvinceAt(ctx, time.Time{}, false, match)
+}
+
+// findProvinceAt is findProvinceWhere over the provinces as they were at asOf (zero: now), or
+// over the current and retired provinces when includeRetired is set
+func (h *Handler) findProvinceAt(ctx context.Context, asOf time.Time, includeRetired bool, match func(models.Province) bool) (*models.Province, error) {
+	provinces, err := h.provincesAt(ctx, asOf, includeRetired)
+	if err != nil {
+		return nil, err
+	}
+	for i := range provinces {
+		if match(provinces[i]) {
+			return &provinces[i], nil
+		}
+	}
+	return nil, nil
+}
+
+// provincesAt returns the current provinces (cached) plus, with includeRetired, the retired ones;
+// or those of the history at a non-zero asOf
+func (h *Handler) provincesAt(ctx context.Context, asOf time.Time, includeRetired bool) ([]models.Province, error) {
+	if !asOf.IsZero() {
+		return h.repo.GetProvincesAsOf(ctx, asOf)
+	}
+	provinces, ok := h.cache.GetProvinces(ctx)
+	if !ok {
+		var err error
+		if provinces, err = h.repo.GetProvinces(ctx); err != nil {
+			return nil, err
+		}
+		// Store in cache (ignore error for cache)
+		_ = h.cache.SetProvinces(ctx, provinces)
+	}
+	if includeRetired {
+		return h.withRetiredProvinces(ctx, provinces)
+	}
+	return provinces, nil
+}
+
+// maxConvertBody limits the JSON body of POST /api/v1/convert
+const maxConvertBody = 64 << 10
+
+// Convert handles POST /api/v1/convert
+// Body: {"ward": "...", "district": "...", "province": "..."} or {"address": "..."}
+func (h *Handler) Convert(w http.ResponseWriter, r *http.Request) {
+	var in convert.Input
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxConvertBody)).Decode(&in); err != nil {
+		h.respondError(w, http.StatusBadRequest, "Invalid JSON body")
+		return
+	}
+
+	res, err := h.converter.Convert(r.Context(), in)
+	if err != nil {
+		if errors.Is(err, convert.ErrMissingWard) {
+			h.respondError(w, http.StatusBadRequest, "ward or address is required")
+			return
+		}
+		h.log.Error("Failed to convert address", "error", err)
+		h.respondError(w, http.StatusInternalServerError, "Internal Server Error")
+		return
+	}
+	h.respondSuccess(w, res)
+}
+
+// ParseAddress handles POST /api/v1/address/parse
+// Body: {"address": "123 Lê Lợi, P. Bến Thành, Q.1, TP.HCM"}
+func (h *Handler) ParseAddress(w http.ResponseWriter, r *http.Request) {
+	var in struct {
+		Address string `json:"address"`
+	}
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxConvertBody)).Decode(&in); err != nil {
+		h.respondError(w, http.StatusBadRequest, "Invalid JSON body")
+		return
+	}
+	if strings.TrimSpace(in.Address) == "" {
+		h.respondError(w, http.StatusBadRequest, "address is required")
+		return
+	}
+
+	addr := address.Parse(in.Address)
+	if err := h.resolver.Resolve(r.Context(), &addr); err != nil {
+		h.log.Error("Failed to resolve address", "error", err)
+		h.respondError(w, http.StatusInternalServerError, "Internal Server Error")
+		return
+	}
+	h.respondSuccess(w, addr)
 }
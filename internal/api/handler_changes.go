@@ -0,0 +1,76 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"vn-admin-api/internal/models"
+)
+
+// Page size of GET /api/v1/changes
+const (
+	defaultChangesLimit = 500
+	maxChangesLimit     = 5000
+)
+
+// GetChanges handles GET /api/v1/changes?since=...&run_id=...&limit=...&cursor=...
+// Lists what crawl runs changed, oldest first. since is an RFC 3339 timestamp or a date (the
+// start of that day in Vietnam time); meta.next_cursor, passed back as cursor, fetches the next page.
+func (h *Handler) GetChanges(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	query := models.ChangeQuery{RunID: q.Get("run_id"), Limit: defaultChangesLimit}
+	if s := q.Get("since"); s != "" {
+		t, err := parseSince(s)
+		if err != nil {
+			h.respondError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		query.Since = t
+	}
+	if s := q.Get("limit"); s != "" {
+		n, err := strconv.Atoi(s)
+		if err != nil || n <= 0 || n > maxChangesLimit {
+			h.respondError(w, http.StatusBadRequest, fmt.Sprintf("Invalid limit (must be 1-%d)", maxChangesLimit))
+			return
+		}
+		query.Limit = n
+	}
+	if s := q.Get("cursor"); s != "" {
+		id, err := strconv.ParseInt(s, 10, 64)
+		if err != nil || id <= 0 {
+			h.respondError(w, http.StatusBadRequest, "Invalid cursor")
+			return
+		}
+		query.AfterID = id
+	}
+
+	changes, err := h.repo.GetChanges(r.Context(), query)
+	if err != nil {
+		h.log.Error("Failed to get changes", "error", err)
+		h.respondError(w, http.StatusInternalServerError, "Internal Server Error")
+		return
+	}
+
+	var next interface{}
+	if len(changes) == query.Limit {
+		next = strconv.FormatInt(changes[len(changes)-1].ID, 10)
+	}
+	h.respondSuccessWithMeta(w, changes, map[string]interface{}{
+		"limit":       query.Limit,
+		"next_cursor": next,
+	})
+}
+
+// parseSince reads an RFC 3339 timestamp, or a date meaning the start of that day in Vietnam time
+func parseSince(s string) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, s); err == nil {
+		return t, nil
+	}
+	t, err := time.ParseInLocation("2006-01-02", s, vnTime)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("Invalid since (expected YYYY-MM-DD or RFC 3339)")
+	}
+	return t, nil
+}
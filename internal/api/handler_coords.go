@@ -0,0 +1,99 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"vn-admin-api/internal/geo"
+	"vn-admin-api/internal/vn2000"
+)
+
+const (
+	maxCoordsBody   = 1 << 20
+	maxCoordsPoints = 10000
+)
+
+// coordPoint carries both coordinate pairs; requests fill in the pair of the source system
+type coordPoint struct {
+	X   float64 `json:"x"`   // VN-2000 northing (m)
+	Y   float64 `json:"y"`   // VN-2000 easting (m)
+	Lat float64 `json:"lat"` // WGS84
+	Lng float64 `json:"lng"` // WGS84
+}
+
+type coordsResult struct {
+	Zone   vn2000.Zone  `json:"zone"`
+	Points []coordPoint `json:"points"`
+}
+
+// ConvertCoords handles POST /api/v1/coords/convert
+// Body: {"from": "vn2000"|"wgs84", "province_id": 1, "central_meridian": 105.5, "points": [...]}
+// The TM3 zone comes from the province unless central_meridian is given.
+func (h *Handler) ConvertCoords(w http.ResponseWriter, r *http.Request) {
+	var in struct {
+		From            string       `json:"from"`
+		ProvinceID      int          `json:"province_id"`
+		CentralMeridian float64      `json:"central_meridian"`
+		Points          []coordPoint `json:"points"`
+	}
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxCoordsBody)).Decode(&in); err != nil {
+		h.respondError(w, http.StatusBadRequest, "Invalid JSON body")
+		return
+	}
+	if in.From != geo.CRSWGS84 && in.From != geo.CRSVN2000 {
+		h.respondError(w, http.StatusBadRequest, "from must be wgs84 or vn2000")
+		return
+	}
+	if len(in.Points) == 0 || len(in.Points) > maxCoordsPoints {
+		h.respondError(w, http.StatusBadRequest, fmt.Sprintf("points must hold 1-%d coordinates", maxCoordsPoints))
+		return
+	}
+
+	var zone vn2000.Zone
+	switch {
+	case in.CentralMeridian != 0:
+		zone = vn2000.TM3(in.CentralMeridian)
+		if err := zone.Validate(); err != nil {
+			h.respondError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+	case in.ProvinceID != 0:
+		p, err := h.findProvince(r.Context(), in.ProvinceID)
+		if err != nil {
+			h.log.Error("Failed to get provinces", "error", err)
+			h.respondError(w, http.StatusInternalServerError, "Internal Server Error")
+			return
+		}
+		if p == nil {
+			h.respondError(w, http.StatusNotFound, "Province not found")
+			return
+		}
+		var ok bool
+		if zone, ok = vn2000.ZoneOf(p.GSOCode, p.Code); !ok {
+			h.respondError(w, http.StatusUnprocessableEntity, "No VN-2000 zone known for this province; pass central_meridian")
+			return
+		}
+	default:
+		h.respondError(w, http.StatusBadRequest, "province_id or central_meridian is required")
+		return
+	}
+
+	for i := range in.Points {
+		p := &in.Points[i]
+		if in.From == geo.CRSWGS84 {
+			if !geo.ValidCoordinate(p.Lat, p.Lng) {
+				h.respondError(w, http.StatusBadRequest, fmt.Sprintf("points[%d]: invalid lat/lng", i))
+				return
+			}
+			p.X, p.Y = zone.FromWGS84(p.Lat, p.Lng)
+			continue
+		}
+		p.Lat, p.Lng = zone.ToWGS84(p.X, p.Y)
+		if !geo.ValidCoordinate(p.Lat, p.Lng) {
+			h.respondError(w, http.StatusBadRequest, fmt.Sprintf("points[%d]: invalid x/y", i))
+			return
+		}
+	}
+	h.respondSuccess(w, coordsResult{Zone: zone, Points: in.Points})
+}
@@ -0,0 +1,121 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"vn-admin-api/internal/crosswalk"
+	"vn-admin-api/internal/models"
+	"vn-admin-api/internal/storage"
+)
+
+// externalMatch is an external ID resolved to its province or unit
+type externalMatch struct {
+	models.ExternalID
+	Province *models.Province  `json:"province,omitempty"`
+	Unit     *models.AdminUnit `json:"unit,omitempty"`
+}
+
+// GetUnitExternalIDs handles GET /api/v1/units/{id}/external-ids?system=...
+func (h *Handler) GetUnitExternalIDs(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		h.respondError(w, http.StatusBadRequest, "Invalid Unit ID")
+		return
+	}
+	if _, err := h.repo.GetAdminUnit(r.Context(), id); err != nil {
+		if errors.Is(err, storage.ErrNotFound) {
+			h.respondError(w, http.StatusNotFound, "Unit not found")
+			return
+		}
+		h.log.Error("Failed to get unit", "unit_id", id, "error", err)
+		h.respondError(w, http.StatusInternalServerError, "Internal Server Error")
+		return
+	}
+	h.respondExternalIDs(w, r, models.RefKindUnit, id)
+}
+
+// GetProvinceExternalIDs handles GET /api/v1/provinces/{id}/external-ids?system=...
+func (h *Handler) GetProvinceExternalIDs(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		h.respondError(w, http.StatusBadRequest, "Invalid Province ID")
+		return
+	}
+	province, err := h.findProvince(r.Context(), id)
+	if err != nil {
+		h.log.Error("Failed to get provinces", "error", err)
+		h.respondError(w, http.StatusInternalServerError, "Internal Server Error")
+		return
+	}
+	if province == nil {
+		h.respondError(w, http.StatusNotFound, "Province not found")
+		return
+	}
+	h.respondExternalIDs(w, r, models.RefKindProvince, id)
+}
+
+// respondExternalIDs lists the external IDs of one record, optionally of one system
+func (h *Handler) respondExternalIDs(w http.ResponseWriter, r *http.Request, kind string, id int) {
+	system := r.URL.Query().Get("system")
+	if system != "" {
+		var err error
+		if system, err = crosswalk.NormalizeSystem(system); err != nil {
+			h.respondError(w, http.StatusBadRequest, "Invalid system")
+			return
+		}
+	}
+
+	ids, err := h.repo.GetExternalIDs(r.Context(), kind, id)
+	if err != nil {
+		h.log.Error("Failed to get external IDs", "kind", kind, "id", id, "error", err)
+		h.respondError(w, http.StatusInternalServerError, "Internal Server Error")
+		return
+	}
+	if system != "" {
+		filtered := make([]models.ExternalID, 0, len(ids))
+		for _, e := range ids {
+			if e.System == system {
+				filtered = append(filtered, e)
+			}
+		}
+		ids = filtered
+	}
+	h.respondSuccess(w, ids)
+}
+
+// GetExternal handles GET /api/v1/external/{system}/{external_id}: the province or unit an
+// outside system's ID maps to
+func (h *Handler) GetExternal(w http.ResponseWriter, r *http.Request) {
+	system, err := crosswalk.NormalizeSystem(r.PathValue("system"))
+	if err != nil {
+		h.respondError(w, http.StatusBadRequest, "Invalid system")
+		return
+	}
+
+	ctx := r.Context()
+	ext, err := h.repo.GetExternalID(ctx, system, r.PathValue("external_id"))
+	if errors.Is(err, storage.ErrNotFound) {
+		h.respondError(w, http.StatusNotFound, "External ID not found")
+		return
+	}
+	if err != nil {
+		h.log.Error("Failed to get external ID", "system", system, "error", err)
+		h.respondError(w, http.StatusInternalServerError, "Internal Server Error")
+		return
+	}
+
+	match := externalMatch{ExternalID: *ext}
+	if ext.Kind == models.RefKindProvince {
+		match.Province, err = h.findProvince(ctx, ext.RefID)
+	} else {
+		match.Unit, err = h.repo.GetAdminUnit(ctx, ext.RefID)
+	}
+	if err != nil {
+		h.log.Error("Failed to resolve external ID", "system", system, "kind", ext.Kind, "id", ext.RefID, "error", err)
+		h.respondError(w, http.StatusInternalServerError, "Internal Server Error")
+		return
+	}
+	h.respondSuccess(w, match)
+}
@@ -0,0 +1,114 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"vn-admin-api/internal/geo"
+	"vn-admin-api/internal/models"
+)
+
+// geoJSONFormat reads ?format=json|geojson; ok is false after an error response
+func (h *Handler) geoJSONFormat(w http.ResponseWriter, r *http.Request) (geojson, ok bool) {
+	switch r.URL.Query().Get("format") {
+	case "", "json":
+		return false, true
+	case "geojson":
+		return true, true
+	default:
+		h.respondError(w, http.StatusBadRequest, "Unknown format (use format=json or format=geojson)")
+		return false, false
+	}
+}
+
+// GetUnitsGeoJSON handles GET /api/v1/provinces/{id}/units.geojson
+func (h *Handler) GetUnitsGeoJSON(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	q.Set("format", "geojson")
+	r.URL.RawQuery = q.Encode()
+	h.GetUnitsByProvince(w, r)
+}
+
+// respondUnitsGeoJSON writes units as a FeatureCollection. ?geometry=all|centroid|boundary
+// selects Points, boundary polygons or both (default).
+func (h *Handler) respondUnitsGeoJSON(w http.ResponseWriter, r *http.Request, units []models.AdminUnit) {
+	geometry := r.URL.Query().Get("geometry")
+	switch geometry {
+	case "":
+		geometry = geo.GeometryAll
+	case geo.GeometryAll, geo.GeometryCentroid, geo.GeometryBoundary:
+	default:
+		h.respondError(w, http.StatusBadRequest, "Unknown geometry (use all, centroid or boundary)")
+		return
+	}
+
+	var boundaries map[int]json.RawMessage
+	if geometry != geo.GeometryCentroid {
+		ids := make([]int, len(units))
+		for i, u := range units {
+			ids[i] = u.ID
+		}
+		var err error
+		if boundaries, err = h.boundaryGeometries(r.Context(), models.BoundaryUnit, ids); err != nil {
+			h.log.Error("Failed to get unit boundaries", "error", err)
+			h.respondError(w, http.StatusInternalServerError, "Internal Server Error")
+			return
+		}
+	}
+
+	fc, err := geo.UnitFeatures(units, boundaries, geometry)
+	if err != nil {
+		h.log.Error("Failed to build GeoJSON", "error", err)
+		h.respondError(w, http.StatusInternalServerError, "Internal Server Error")
+		return
+	}
+	h.respondGeoJSON(w, fc)
+}
+
+// respondProvincesGeoJSON writes provinces as a FeatureCollection of their boundary polygons
+func (h *Handler) respondProvincesGeoJSON(w http.ResponseWriter, r *http.Request, provinces []models.Province) {
+	ids := make([]int, len(provinces))
+	for i, p := range provinces {
+		ids[i] = p.ID
+	}
+	boundaries, err := h.boundaryGeometries(r.Context(), models.BoundaryProvince, ids)
+	if err != nil {
+		h.log.Error("Failed to get province boundaries", "error", err)
+		h.respondError(w, http.StatusInternalServerError, "Internal Server Error")
+		return
+	}
+
+	fc, err := geo.ProvinceFeatures(provinces, boundaries)
+	if err != nil {
+		h.log.Error("Failed to build GeoJSON", "error", err)
+		h.respondError(w, http.StatusInternalServerError, "Internal Server Error")
+		return
+	}
+	h.respondGeoJSON(w, fc)
+}
+
+func (h *Handler) boundaryGeometries(ctx context.Context, kind string, ids []int) (map[int]json.RawMessage, error) {
+	if len(ids) == 0 {
+		return nil, nil
+	}
+	boundaries, err := h.repo.GetBoundariesByIDs(ctx, kind, ids)
+	if err != nil {
+		return nil, err
+	}
+	geometries := make(map[int]json.RawMessage, len(boundaries))
+	for _, b := range boundaries {
+		geometries[b.RefID] = b.Geometry
+	}
+	return geometries, nil
+}
+
+// respondGeoJSON writes a bare FeatureCollection (no {"data": ...} envelope) so GIS tools can load it directly
+func (h *Handler) respondGeoJSON(w http.ResponseWriter, fc *geo.FeatureCollection) {
+	w.Header().Set("Content-Type", "application/geo+json")
+	w.WriteHeader(http.StatusOK)
+	if err := json.NewEncoder(w).Encode(fc); err != nil && !errors.Is(err, http.ErrHandlerTimeout) {
+		h.log.Error("Failed to encode GeoJSON", "error", err)
+	}
+}
@@ -0,0 +1,89 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"vn-admin-api/internal/models"
+	"vn-admin-api/internal/search"
+)
+
+// vnTime is Vietnam time (UTC+7, no daylight saving); as_of dates are days in it
+var vnTime = time.FixedZone("ICT", 7*60*60)
+
+// parseAsOf reads ?as_of: an RFC 3339 timestamp, or a date meaning the end of that day in
+// Vietnam time. The zero time means the parameter is absent (the current data).
+func parseAsOf(q url.Values) (time.Time, error) {
+	s := q.Get("as_of")
+	if s == "" {
+		return time.Time{}, nil
+	}
+	if t, err := time.Parse(time.RFC3339, s); err == nil {
+		return t, nil
+	}
+	day, err := time.ParseInLocation("2006-01-02", s, vnTime)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("Invalid as_of (expected YYYY-MM-DD or RFC 3339)")
+	}
+	return day.AddDate(0, 0, 1).Add(-time.Microsecond), nil
+}
+
+// GetUnitHistory handles GET /api/v1/units/{id}/history: every version of a unit, oldest first
+func (h *Handler) GetUnitHistory(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		h.respondError(w, http.StatusBadRequest, "Invalid Unit ID")
+		return
+	}
+	versions, err := h.repo.GetUnitHistory(r.Context(), id)
+	if err != nil {
+		h.log.Error("Failed to get unit history", "unit_id", id, "error", err)
+		h.respondError(w, http.StatusInternalServerError, "Internal Server Error")
+		return
+	}
+	if len(versions) == 0 {
+		h.respondError(w, http.StatusNotFound, "Unit not found")
+		return
+	}
+	h.respondSuccess(w, versions)
+}
+
+// respondUnitAsOf serves GET /api/v1/units/{id}?as_of=... from the unit's history
+func (h *Handler) respondUnitAsOf(w http.ResponseWriter, r *http.Request, id int, asOf time.Time) {
+	versions, err := h.repo.GetUnitHistory(r.Context(), id)
+	if err != nil {
+		h.log.Error("Failed to get unit history", "unit_id", id, "error", err)
+		h.respondError(w, http.StatusInternalServerError, "Internal Server Error")
+		return
+	}
+	for _, v := range versions {
+		if v.ValidAt(asOf) {
+			h.respondWithETag(w, r, v.AdminUnit)
+			return
+		}
+	}
+	h.respondError(w, http.StatusNotFound, "Unit not found")
+}
+
+// searchAsOf ranks the units as they were at asOf, with the "did you mean" suggestion when no
+// result matches exactly
+func (h *Handler) searchAsOf(ctx context.Context, params models.SearchParams, asOf time.Time) ([]models.SearchResult, string, error) {
+	matcher := search.NewMatcher(params.Query)
+	if matcher.Empty() {
+		return []models.SearchResult{}, "", nil
+	}
+	units, err := h.repo.GetUnitsAsOf(ctx, asOf, 0)
+	if err != nil {
+		return nil, "", err
+	}
+	results := search.Rank(matcher, units, params)
+	if hasExactMatch(results) {
+		return results, "", nil
+	}
+	suggestion, _ := search.Suggest(matcher, units)
+	return results, suggestion, nil
+}
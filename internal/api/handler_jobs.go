@@ -0,0 +1,163 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"path"
+	"strings"
+
+	"vn-admin-api/internal/jobs"
+	"vn-admin-api/internal/models"
+	"vn-admin-api/internal/storage"
+)
+
+// maxJobUpload limits the size of a bulk conversion upload
+const maxJobUpload = 64 << 20
+
+// CreateJob handles POST /api/v1/jobs
+// The body is the raw file (Content-Type text/csv or application/x-ndjson) or a
+// multipart form with a "file" field. ?format=csv|ndjson overrides detection.
+func (h *Handler) CreateJob(w http.ResponseWriter, r *http.Request) {
+	if h.jobs == nil {
+		h.respondError(w, http.StatusServiceUnavailable, "Bulk jobs are disabled")
+		return
+	}
+
+	r.Body = http.MaxBytesReader(w, r.Body, maxJobUpload)
+	data, format, err := readUpload(r)
+	if err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			h.respondError(w, http.StatusRequestEntityTooLarge, "File too large (max 64MB)")
+			return
+		}
+		h.respondError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if f := r.URL.Query().Get("format"); f != "" {
+		format = f
+	}
+	if format != jobs.FormatCSV && format != jobs.FormatNDJSON {
+		h.respondError(w, http.StatusBadRequest, "Unknown format (use format=csv or format=ndjson)")
+		return
+	}
+
+	job, err := h.jobs.Submit(r.Context(), format, data)
+	if err != nil {
+		if errors.Is(err, jobs.ErrInvalidInput) {
+			h.respondError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		h.log.Error("Failed to create job", "error", err)
+		h.respondError(w, http.StatusInternalServerError, "Internal Server Error")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Location", "/api/v1/jobs/"+job.ID)
+	w.WriteHeader(http.StatusAccepted)
+	if err := json.NewEncoder(w).Encode(map[string]interface{}{"data": job}); err != nil {
+		h.log.Error("Failed to encode response", "error", err)
+	}
+}
+
+// GetJob handles GET /api/v1/jobs/{id}
+func (h *Handler) GetJob(w http.ResponseWriter, r *http.Request) {
+	job, ok := h.lookupJob(w, r)
+	if !ok {
+		return
+	}
+	h.respondSuccess(w, job)
+}
+
+// GetJobResult handles GET /api/v1/jobs/{id}/result and returns the annotated file
+func (h *Handler) GetJobResult(w http.ResponseWriter, r *http.Request) {
+	job, ok := h.lookupJob(w, r)
+	if !ok {
+		return
+	}
+	if job.Status != models.JobCompleted {
+		h.respondError(w, http.StatusConflict, fmt.Sprintf("Job is %s", job.Status))
+		return
+	}
+
+	result, err := h.repo.GetJobResult(r.Context(), job.ID)
+	if err != nil {
+		h.log.Error("Failed to get job result", "job_id", job.ID, "error", err)
+		h.respondError(w, http.StatusInternalServerError, "Internal Server Error")
+		return
+	}
+
+	contentType := "text/csv; charset=utf-8"
+	if job.Format == jobs.FormatNDJSON {
+		contentType = "application/x-ndjson"
+	}
+	w.Header().Set("Content-Type", contentType)
+	w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="%s.%s"`, job.ID, job.Format))
+	w.WriteHeader(http.StatusOK)
+	if _, err := w.Write(result); err != nil {
+		h.log.Error("Failed to write job result", "job_id", job.ID, "error", err)
+	}
+}
+
+// lookupJob loads the job named in the path, writing an error response when it cannot
+func (h *Handler) lookupJob(w http.ResponseWriter, r *http.Request) (*models.Job, bool) {
+	if h.jobs == nil {
+		h.respondError(w, http.StatusServiceUnavailable, "Bulk jobs are disabled")
+		return nil, false
+	}
+	id := r.PathValue("id")
+	job, err := h.repo.GetJob(r.Context(), id)
+	if err != nil {
+		if errors.Is(err, storage.ErrNotFound) {
+			h.respondError(w, http.StatusNotFound, "Job not found")
+			return nil, false
+		}
+		h.log.Error("Failed to get job", "job_id", id, "error", err)
+		h.respondError(w, http.StatusInternalServerError, "Internal Server Error")
+		return nil, false
+	}
+	return job, true
+}
+
+// readUpload returns the uploaded file and the format implied by its content type or file name
+func readUpload(r *http.Request) ([]byte, string, error) {
+	mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if mediaType != "multipart/form-data" {
+		data, err := io.ReadAll(r.Body)
+		return data, formatFor(mediaType, ""), err
+	}
+
+	file, header, err := r.FormFile("file")
+	if err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			return nil, "", err
+		}
+		return nil, "", errors.New("multipart upload needs a \"file\" field")
+	}
+	defer file.Close()
+
+	data, err := io.ReadAll(file)
+	return data, formatFor(header.Header.Get("Content-Type"), header.Filename), err
+}
+
+func formatFor(mediaType, filename string) string {
+	switch strings.ToLower(path.Ext(filename)) {
+	case ".csv":
+		return jobs.FormatCSV
+	case ".ndjson", ".jsonl":
+		return jobs.FormatNDJSON
+	}
+	switch mediaType {
+	case "text/csv":
+		return jobs.FormatCSV
+	case "application/x-ndjson", "application/ndjson", "application/jsonl":
+		return jobs.FormatNDJSON
+	}
+	return ""
+}
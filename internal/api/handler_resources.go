@@ -0,0 +1,124 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"vn-admin-api/internal/models"
+	"vn-admin-api/internal/storage"
+)
+
+// GetProvince handles GET /api/v1/provinces/{id} (?as_of=YYYY-MM-DD for the province as it was then)
+func (h *Handler) GetProvince(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		h.respondError(w, http.StatusBadRequest, "Invalid Province ID")
+		return
+	}
+	h.respondProvince(w, r, func(p models.Province) bool { return p.ID == id })
+}
+
+// GetProvinceByCode handles GET /api/v1/codes/provinces/{code} (mahc; "01" and "1" are the same)
+func (h *Handler) GetProvinceByCode(w http.ResponseWriter, r *http.Request) {
+	code, err := strconv.Atoi(r.PathValue("code"))
+	if err != nil {
+		h.respondError(w, http.StatusBadRequest, "Invalid Province code")
+		return
+	}
+	h.respondProvince(w, r, func(p models.Province) bool { return p.Code == code })
+}
+
+func (h *Handler) respondProvince(w http.ResponseWriter, r *http.Request, match func(models.Province) bool) {
+	asOf, err := parseAsOf(r.URL.Query())
+	if err != nil {
+		h.respondError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	includeRetired, err := parseIncludeRetired(r.URL.Query(), asOf)
+	if err != nil {
+		h.respondError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	p, err := h.findProvinceAt(r.Context(), asOf, includeRetired, match)
+	if err != nil {
+		h.log.Error("Failed to get provinces", "error", err)
+		h.respondError(w, http.StatusInternalServerError, "Internal Server Error")
+		return
+	}
+	if p == nil {
+		h.respondError(w, http.StatusNotFound, "Province not found")
+		return
+	}
+	h.respondWithETag(w, r, p)
+}
+
+// GetUnit handles GET /api/v1/units/{id} (?as_of=YYYY-MM-DD for the unit as it was then)
+func (h *Handler) GetUnit(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		h.respondError(w, http.StatusBadRequest, "Invalid Unit ID")
+		return
+	}
+	asOf, err := parseAsOf(r.URL.Query())
+	if err != nil {
+		h.respondError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	includeRetired, err := parseIncludeRetired(r.URL.Query(), asOf)
+	if err != nil {
+		h.respondError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if !asOf.IsZero() {
+		h.respondUnitAsOf(w, r, id, asOf)
+		return
+	}
+	h.respondUnit(w, r, includeRetired, "id:"+strconv.Itoa(id), func(ctx context.Context) (*models.AdminUnit, error) {
+		return h.repo.GetAdminUnit(ctx, id)
+	})
+}
+
+// GetUnitByCode handles GET /api/v1/codes/units/{code} (ma; "00004" and "4" are the same)
+func (h *Handler) GetUnitByCode(w http.ResponseWriter, r *http.Request) {
+	code := strings.TrimLeft(r.PathValue("code"), "0")
+	if _, err := strconv.Atoi(code); err != nil {
+		h.respondError(w, http.StatusBadRequest, "Invalid Unit code")
+		return
+	}
+	includeRetired, err := parseIncludeRetired(r.URL.Query(), time.Time{})
+	if err != nil {
+		h.respondError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	h.respondUnit(w, r, includeRetired, "code:"+code, func(ctx context.Context) (*models.AdminUnit, error) {
+		return h.repo.GetAdminUnitByCode(ctx, code)
+	})
+}
+
+// respondUnit serves a unit looked up by ID or code; a retired unit is only shown with include_retired
+func (h *Handler) respondUnit(w http.ResponseWriter, r *http.Request, includeRetired bool, cacheKey string, load func(context.Context) (*models.AdminUnit, error)) {
+	ctx := r.Context()
+	unit, ok := h.cache.GetUnit(ctx, cacheKey)
+	if !ok {
+		var err error
+		if unit, err = load(ctx); err != nil {
+			if errors.Is(err, storage.ErrNotFound) {
+				h.respondError(w, http.StatusNotFound, "Unit not found")
+				return
+			}
+			h.log.Error("Failed to get unit", "key", cacheKey, "error", err)
+			h.respondError(w, http.StatusInternalServerError, "Internal Server Error")
+			return
+		}
+		_ = h.cache.SetUnit(ctx, cacheKey, unit)
+	}
+	if unit.RetiredAt != nil && !includeRetired {
+		h.respondError(w, http.StatusNotFound, "Unit not found")
+		return
+	}
+	h.respondWithETag(w, r, unit)
+}
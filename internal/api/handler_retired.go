@@ -0,0 +1,57 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"sort"
+	"strconv"
+	"time"
+
+	"vn-admin-api/internal/models"
+)
+
+// parseIncludeRetired reads ?include_retired: provinces and units retired (gone upstream) are hidden
+// unless it is true. It cannot be combined with as_of, which shows what was live at that time.
+func parseIncludeRetired(q url.Values, asOf time.Time) (bool, error) {
+	s := q.Get("include_retired")
+	if s == "" {
+		return false, nil
+	}
+	include, err := strconv.ParseBool(s)
+	if err != nil {
+		return false, fmt.Errorf("Invalid include_retired (expected true or false)")
+	}
+	if include && !asOf.IsZero() {
+		return false, fmt.Errorf("include_retired cannot be combined with as_of")
+	}
+	return include, nil
+}
+
+// withRetiredProvinces adds the retired provinces to the current ones, ordered by ID
+func (h *Handler) withRetiredProvinces(ctx context.Context, provinces []models.Province) ([]models.Province, error) {
+	retired, _, err := h.repo.GetRetired(ctx)
+	if err != nil {
+		return nil, err
+	}
+	// Copy: the cached slice may be shared
+	all := append(append(make([]models.Province, 0, len(provinces)+len(retired)), provinces...), retired...)
+	sort.Slice(all, func(i, j int) bool { return all[i].ID < all[j].ID })
+	return all, nil
+}
+
+// withRetiredUnits adds the retired units of a province to its current units, ordered by ID
+func (h *Handler) withRetiredUnits(ctx context.Context, provinceID int, units []models.AdminUnit) ([]models.AdminUnit, error) {
+	_, retired, err := h.repo.GetRetired(ctx)
+	if err != nil {
+		return nil, err
+	}
+	all := append(make([]models.AdminUnit, 0, len(units)), units...)
+	for _, u := range retired {
+		if u.ProvinceID == provinceID {
+			all = append(all, u)
+		}
+	}
+	sort.Slice(all, func(i, j int) bool { return all[i].ID < all[j].ID })
+	return all, nil
+}
@@ -0,0 +1,64 @@
+package api
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"vn-admin-api/internal/models"
+)
+
+// syncToken is the opaque since_token of GET /api/v1/sync: the sync version the next delta starts
+// after. Versions are positive, so a token never asks for a full sync.
+type syncToken struct {
+	Version int64 `json:"v"`
+}
+
+func encodeSyncToken(version int64) string {
+	data, _ := json.Marshal(syncToken{Version: version})
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+func decodeSyncToken(s string) (int64, error) {
+	var token syncToken
+	data, err := base64.RawURLEncoding.DecodeString(s)
+	if err == nil {
+		err = json.Unmarshal(data, &token)
+	}
+	if err != nil || token.Version <= 0 {
+		return 0, errors.New("Invalid since_token")
+	}
+	return token.Version, nil
+}
+
+// syncResponse is the data of GET /api/v1/sync
+type syncResponse struct {
+	Token string `json:"token"` // since_token of the next sync
+	Full  bool   `json:"full"`  // No since_token: every live record, to replace the client's copy
+	*models.SyncDelta
+}
+
+// Sync handles GET /api/v1/sync?since_token=...
+// Without since_token it returns every live province and unit; with one, the records upserted and
+// the tombstones of those retired since the sync that returned it. Both come with a new token.
+// Reads bypass the cache so a delta never misses a write.
+func (h *Handler) Sync(w http.ResponseWriter, r *http.Request) {
+	var since int64
+	if s := r.URL.Query().Get("since_token"); s != "" {
+		v, err := decodeSyncToken(s)
+		if err != nil {
+			h.respondError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		since = v
+	}
+
+	delta, err := h.repo.GetSyncDelta(r.Context(), since)
+	if err != nil {
+		h.log.Error("Failed to get sync delta", "error", err)
+		h.respondError(w, http.StatusInternalServerError, "Internal Server Error")
+		return
+	}
+	h.respondSuccess(w, syncResponse{Token: encodeSyncToken(delta.Version), Full: since == 0, SyncDelta: delta})
+}
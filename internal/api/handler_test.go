@@ -1,11 +1,30 @@
 package api
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
+	"math"
 	"net/http"
 	"net/http/httptest"
+	"net/url"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
 	"testing"
+	"time"
+
+	"vn-admin-api/internal/address"
+	"vn-admin-api/internal/autocomplete"
+	"vn-admin-api/internal/cache"
+	"vn-admin-api/internal/convert"
+	"vn-admin-api/internal/geo"
+	"vn-admin-api/internal/jobs"
+	"vn-admin-api/internal/levels"
 	"vn-admin-api/internal/logger"
+	"vn-admin-api/internal/models"
+	"vn-admin-api/internal/storage"
 )
 
 func TestHandler_Root(t *testing.T) {
@@ -36,3 +55,1258 @@ func TestHandler_Root(t *testing.T) {
 		t.Errorf("Expected message %q, got %q", expectedMessage, response["message"])
 	}
 }
+
+func newTestStore(t *testing.T) *storage.MemoryStore {
+	t.Helper()
+	ctx := context.Background()
+	store := storage.NewMemoryStore()
+
+	provinces := []models.Province{
+		{ID: 1, Name: "Thành phố Hà Nội", Code: 1},
+		{ID: 2, Name: "Thành phố Hồ Chí Minh", Code: 79},
+	}
+	for _, p := range provinces {
+		if err := store.UpsertProvince(ctx, p); err != nil {
+			t.Fatalf("Failed to seed province: %v", err)
+		}
+	}
+
+	units := []models.AdminUnit{
+		{ID: 101, ProvinceID: 1, Name: "Phường Ba Đình", Level: "Phường", LevelCode: levels.Ward, Code: "00004", PreMergerDesc: "Phường Quán Thánh, Phường Trúc Bạch", Lat: 21.034, Long: 105.84},
+		{ID: 102, ProvinceID: 1, Name: "Xã Sơn Tây", Level: "Xã", LevelCode: levels.Commune, Code: "09574", PreMergerDesc: "Xã Đường Lâm (tỉnh Hà Tây cũ)", Lat: 21.14, Long: 105.5},
+		{ID: 201, ProvinceID: 2, Name: "Phường Bến Thành", Level: "Phường", LevelCode: levels.Ward, Code: "26740", PreMergerDesc: "Phường Bến Thành, Phường Phạm Ngũ Lão", Lat: 10.77, Long: 106.69},
+	}
+	for _, u := range units {
+		if err := store.UpsertAdminUnit(ctx, u); err != nil {
+			t.Fatalf("Failed to seed unit: %v", err)
+		}
+	}
+	return store
+}
+
+func serve(t *testing.T, h http.Handler, target string) *httptest.ResponseRecorder {
+	t.Helper()
+	req := httptest.NewRequest("GET", target, nil)
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, req)
+	return w
+}
+
+func TestRouter_GetUnitsByProvince(t *testing.T) {
+	router := NewRouter(newTestStore(t), logger.New("", false))
+
+	w := serve(t, router, "/api/v1/provinces/1/units")
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status code %d, got %d", http.StatusOK, w.Code)
+	}
+
+	var response struct {
+		Data []models.AdminUnit `json:"data"`
+	}
+	if err := json.NewDecoder(w.Body).Decode(&response); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	if len(response.Data) != 2 || response.Data[0].ID != 101 || response.Data[1].ID != 102 {
+		t.Errorf("Expected units [101 102], got %+v", response.Data)
+	}
+
+	// The level filter must not shrink the cached list
+	w = serve(t, router, "/api/v1/provinces/1/units?level=Xã")
+	response.Data = nil
+	if err := json.NewDecoder(w.Body).Decode(&response); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	if len(response.Data) != 1 || response.Data[0].ID != 102 || response.Data[0].LevelCode != levels.Commune {
+		t.Errorf("Expected commune 102, got %+v", response.Data)
+	}
+	if w := serve(t, router, "/api/v1/provinces/1/units"); !strings.Contains(w.Body.String(), `"id":101`) {
+		t.Errorf("Expected unfiltered list to still hold 101, got %s", w.Body)
+	}
+}
+
+func TestRouter_GetLevels(t *testing.T) {
+	router := NewRouter(newTestStore(t), logger.New("", false))
+
+	w := serve(t, router, "/api/v1/levels")
+	var response struct {
+		Data []levels.Level `json:"data"`
+	}
+	if err := json.NewDecoder(w.Body).Decode(&response); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	if !reflect.DeepEqual(response.Data, levels.All) {
+		t.Errorf("Expected the level taxonomy, got %+v", response.Data)
+	}
+}
+
+func TestRouter_SingleResources(t *testing.T) {
+	router := NewRouter(newTestStore(t), logger.New("", false))
+
+	tests := []struct {
+		target string
+		want   int
+		wantID int
+	}{
+		{"/api/v1/provinces/1", http.StatusOK, 1},
+		{"/api/v1/codes/provinces/79", http.StatusOK, 2},
+		{"/api/v1/codes/provinces/01", http.StatusOK, 1},
+		{"/api/v1/units/102", http.StatusOK, 102},
+		{"/api/v1/codes/units/00004", http.StatusOK, 101},
+		{"/api/v1/codes/units/4", http.StatusOK, 101},
+		{"/api/v1/provinces/99", http.StatusNotFound, 0},
+		{"/api/v1/provinces/99/units", http.StatusNotFound, 0},
+		{"/api/v1/codes/provinces/3", http.StatusNotFound, 0},
+		{"/api/v1/units/999", http.StatusNotFound, 0},
+		{"/api/v1/codes/units/99999", http.StatusNotFound, 0},
+		{"/api/v1/units/abc", http.StatusBadRequest, 0},
+		{"/api/v1/codes/units/x1", http.StatusBadRequest, 0},
+	}
+	for _, tt := range tests {
+		w := serve(t, router, tt.target)
+		if w.Code != tt.want {
+			t.Errorf("%s: expected status code %d, got %d", tt.target, tt.want, w.Code)
+			continue
+		}
+		if tt.want != http.StatusOK {
+			continue
+		}
+		var response struct {
+			Data struct {
+				ID int `json:"id"`
+			} `json:"data"`
+		}
+		if err := json.NewDecoder(w.Body).Decode(&response); err != nil {
+			t.Fatalf("Failed to decode response: %v", err)
+		}
+		if response.Data.ID != tt.wantID {
+			t.Errorf("%s: got id %d, want %d", tt.target, response.Data.ID, tt.wantID)
+		}
+	}
+
+	// Conditional requests
+	w := serve(t, router, "/api/v1/units/101")
+	etag := w.Header().Get("ETag")
+	if etag == "" {
+		t.Fatal("Expected an ETag header")
+	}
+	req := httptest.NewRequest("GET", "/api/v1/units/101", nil)
+	req.Header.Set("If-None-Match", `"stale", `+etag)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	if w.Code != http.StatusNotModified || w.Body.Len() != 0 {
+		t.Errorf("Expected %d with an empty body, got %d with %d bytes", http.StatusNotModified, w.Code, w.Body.Len())
+	}
+}
+
+func TestRouter_Search(t *testing.T) {
+	router := NewRouter(newTestStore(t), logger.New("", false))
+
+	if w := serve(t, router, "/api/v1/search?q=a"); w.Code != http.StatusBadRequest {
+		t.Errorf("Expected status code %d for short query, got %d", http.StatusBadRequest, w.Code)
+	}
+	if w := serve(t, router, "/api/v1/search?q=thanh&level=thon"); w.Code != http.StatusBadRequest {
+		t.Errorf("Expected status code %d for unknown level, got %d", http.StatusBadRequest, w.Code)
+	}
+
+	tests := []struct {
+		query string
+		want  []int
+	}{
+		{"Hà%20Tây", []int{102}},   // matched by pre-merger description
+		{"ha%20tay", []int{102}},   // typed without diacritics
+		{"ba%20dinh", []int{101}},  // đ folds to d
+		{"thanh", []int{201, 101}}, // name match ranks above pre-merger match
+		{"thanh&level=commune", []int{}},
+		{"ha%20tay&level=xa", []int{102}},
+	}
+	for _, tt := range tests {
+		w := serve(t, router, "/api/v1/search?q="+tt.query)
+		if w.Code != http.StatusOK {
+			t.Fatalf("Expected status code %d, got %d", http.StatusOK, w.Code)
+		}
+
+		var response struct {
+			Data []models.SearchResult `json:"data"`
+		}
+		if err := json.NewDecoder(w.Body).Decode(&response); err != nil {
+			t.Fatalf("Failed to decode response: %v", err)
+		}
+		got := make([]int, len(response.Data))
+		for i, r := range response.Data {
+			got[i] = r.ID
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("Search %q: expected units %v, got %v", tt.query, tt.want, got)
+		}
+	}
+}
+
+func TestRouter_SearchFuzzy(t *testing.T) {
+	router := NewRouter(newTestStore(t), logger.New("", false))
+
+	var response struct {
+		Data []models.SearchResult `json:"data"`
+		Meta struct {
+			DidYouMean string `json:"did_you_mean"`
+		} `json:"meta"`
+	}
+
+	// Without fuzzy: no results, but a suggestion
+	w := serve(t, router, "/api/v1/search?q=ba%20dinhh")
+	if err := json.NewDecoder(w.Body).Decode(&response); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	if len(response.Data) != 0 || response.Meta.DidYouMean != "Phường Ba Đình" {
+		t.Errorf("Expected no results and suggestion %q, got %+v / %q", "Phường Ba Đình", response.Data, response.Meta.DidYouMean)
+	}
+
+	// With fuzzy: the typo still matches
+	w = serve(t, router, "/api/v1/search?q=ba%20dinhh&fuzzy=true")
+	if err := json.NewDecoder(w.Body).Decode(&response); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	if len(response.Data) != 1 || response.Data[0].ID != 101 || response.Data[0].Match != models.MatchFuzzy {
+		t.Errorf("Expected fuzzy match on unit 101, got %+v", response.Data)
+	}
+
+	// A strict threshold filters it out again
+	w = serve(t, router, "/api/v1/search?q=ba%20dinhh&fuzzy=true&min_score=0.9")
+	response.Data = nil
+	if err := json.NewDecoder(w.Body).Decode(&response); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	if len(response.Data) != 0 {
+		t.Errorf("Expected no results above min_score 0.9, got %+v", response.Data)
+	}
+
+	if w := serve(t, router, "/api/v1/search?q=ba&min_score=2"); w.Code != http.StatusBadRequest {
+		t.Errorf("Expected status code %d for invalid min_score, got %d", http.StatusBadRequest, w.Code)
+	}
+}
+
+func TestRouter_Autocomplete(t *testing.T) {
+	router := NewRouter(newTestStore(t), logger.New("", false))
+
+	tests := []struct {
+		query string
+		want  []int
+	}{
+		{"/api/v1/autocomplete?q=ben", []int{201}},
+		{"/api/v1/autocomplete?q=phuong%20ba", []int{101}},
+		{"/api/v1/autocomplete?q=ha&limit=1", []int{1}},
+		{"/api/v1/autocomplete?q=s&province_id=1", []int{102}},
+	}
+	for _, tt := range tests {
+		w := serve(t, router, tt.query)
+		if w.Code != http.StatusOK {
+			t.Fatalf("Expected status code %d, got %d", http.StatusOK, w.Code)
+		}
+		var response struct {
+			Data []autocomplete.Suggestion `json:"data"`
+		}
+		if err := json.NewDecoder(w.Body).Decode(&response); err != nil {
+			t.Fatalf("Failed to decode response: %v", err)
+		}
+		got := make([]int, len(response.Data))
+		for i, s := range response.Data {
+			got[i] = s.ID
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: expected %v, got %v", tt.query, tt.want, got)
+		}
+	}
+
+	for _, target := range []string{"/api/v1/autocomplete?q=", "/api/v1/autocomplete?q=ha&limit=500", "/api/v1/autocomplete?q=ha&province_id=x"} {
+		if w := serve(t, router, target); w.Code != http.StatusBadRequest {
+			t.Errorf("%s: expected status code %d, got %d", target, http.StatusBadRequest, w.Code)
+		}
+	}
+}
+
+func TestRouter_Reverse(t *testing.T) {
+	router := NewRouter(newTestStore(t), logger.New("", false))
+
+	w := serve(t, router, "/api/v1/reverse?lat=21.03&lng=105.85&limit=2")
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status code %d, got %d", http.StatusOK, w.Code)
+	}
+	var response struct {
+		Data []models.NearbyUnit `json:"data"`
+	}
+	if err := json.NewDecoder(w.Body).Decode(&response); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	if len(response.Data) != 2 || response.Data[0].ID != 101 || response.Data[1].ID != 102 {
+		t.Fatalf("Expected units [101 102], got %+v", response.Data)
+	}
+	if d := response.Data[0].DistanceKm; d <= 0 || d > 2 {
+		t.Errorf("Expected unit 101 within 2 km, got %.3f km", d)
+	}
+
+	for _, target := range []string{"/api/v1/reverse?lat=21", "/api/v1/reverse?lat=91&lng=105", "/api/v1/reverse?lat=21&lng=105&limit=0"} {
+		if w := serve(t, router, target); w.Code != http.StatusBadRequest {
+			t.Errorf("%s: expected status code %d, got %d", target, http.StatusBadRequest, w.Code)
+		}
+	}
+}
+
+func TestRouter_Locate(t *testing.T) {
+	ctx := context.Background()
+	store := newTestStore(t)
+	boundaries := []models.Boundary{
+		{Kind: models.BoundaryProvince, RefID: 1, Geometry: json.RawMessage(`{"type": "Polygon", "coordinates": [[[105, 20.5], [106.5, 20.5], [106.5, 21.5], [105, 21.5], [105, 20.5]]]}`)},
+		{Kind: models.BoundaryUnit, RefID: 101, Geometry: json.RawMessage(`{"type": "Polygon", "coordinates": [[[105.8, 21], [105.9, 21], [105.9, 21.1], [105.8, 21.1], [105.8, 21]]]}`)},
+		{Kind: models.BoundaryUnit, RefID: 201, Geometry: json.RawMessage(`{"type": "Polygon", "coordinates": [[[106.6, 10.7], [106.8, 10.7], [106.8, 10.9], [106.6, 10.7]]]}`)},
+	}
+	for _, b := range boundaries {
+		if err := store.UpsertBoundary(ctx, b); err != nil {
+			t.Fatalf("Failed to seed boundary: %v", err)
+		}
+	}
+	router := NewRouter(store, logger.New("", false))
+
+	tests := []struct {
+		target             string
+		wantProvince, unit int
+	}{
+		{"/api/v1/locate?lat=21.03&lng=105.85", 1, 101},
+		{"/api/v1/locate?lat=20.8&lng=106", 1, 0},       // inside the province, no ward outline
+		{"/api/v1/locate?lat=10.75&lng=106.75", 2, 201}, // province taken from the unit
+	}
+	for _, tt := range tests {
+		w := serve(t, router, tt.target)
+		if w.Code != http.StatusOK {
+			t.Fatalf("%s: expected status code %d, got %d", tt.target, http.StatusOK, w.Code)
+		}
+		var response struct {
+			Data models.Location `json:"data"`
+		}
+		if err := json.NewDecoder(w.Body).Decode(&response); err != nil {
+			t.Fatalf("Failed to decode response: %v", err)
+		}
+		gotUnit := 0
+		if response.Data.Unit != nil {
+			gotUnit = response.Data.Unit.ID
+		}
+		if response.Data.Province == nil || response.Data.Province.ID != tt.wantProvince || gotUnit != tt.unit {
+			t.Errorf("%s: got %+v, want province %d unit %d", tt.target, response.Data, tt.wantProvince, tt.unit)
+		}
+	}
+
+	if w := serve(t, router, "/api/v1/locate?lat=16&lng=108"); w.Code != http.StatusNotFound {
+		t.Errorf("Expected status code %d outside every boundary, got %d", http.StatusNotFound, w.Code)
+	}
+	if w := serve(t, router, "/api/v1/locate?lat=abc&lng=108"); w.Code != http.StatusBadRequest {
+		t.Errorf("Expected status code %d for invalid lat, got %d", http.StatusBadRequest, w.Code)
+	}
+}
+
+func TestRouter_GeoJSON(t *testing.T) {
+	store := newTestStore(t)
+	boundary := models.Boundary{Kind: models.BoundaryUnit, RefID: 101, Geometry: json.RawMessage(`{"type": "Polygon", "coordinates": [[[105.8, 21], [105.9, 21], [105.9, 21.1], [105.8, 21.1], [105.8, 21]]]}`)}
+	if err := store.UpsertBoundary(context.Background(), boundary); err != nil {
+		t.Fatalf("Failed to seed boundary: %v", err)
+	}
+	router := NewRouter(store, logger.New("", false))
+
+	tests := []struct {
+		target string
+		want   []string // feature ids in order
+	}{
+		{"/api/v1/provinces/1/units?format=geojson", []string{"101", "101-boundary", "102"}},
+		{"/api/v1/provinces/1/units.geojson?geometry=centroid", []string{"101", "102"}},
+		{"/api/v1/provinces/1/units.geojson?geometry=boundary", []string{"101-boundary"}},
+		{"/api/v1/provinces?format=geojson", []string{"1", "2"}},
+	}
+	for _, tt := range tests {
+		w := serve(t, router, tt.target)
+		if w.Code != http.StatusOK {
+			t.Fatalf("%s: expected status code %d, got %d", tt.target, http.StatusOK, w.Code)
+		}
+		if ct := w.Header().Get("Content-Type"); ct != "application/geo+json" {
+			t.Errorf("%s: expected Content-Type application/geo+json, got %q", tt.target, ct)
+		}
+		var fc geo.FeatureCollection
+		if err := json.NewDecoder(w.Body).Decode(&fc); err != nil {
+			t.Fatalf("Failed to decode response: %v", err)
+		}
+		var got []string
+		for _, f := range fc.Features {
+			got = append(got, f.ID)
+		}
+		if fc.Type != "FeatureCollection" || strings.Join(got, ",") != strings.Join(tt.want, ",") {
+			t.Errorf("%s: got %s features %v, want %v", tt.target, fc.Type, got, tt.want)
+		}
+	}
+
+	for _, target := range []string{"/api/v1/provinces?format=kml", "/api/v1/provinces/1/units.geojson?geometry=hull"} {
+		if w := serve(t, router, target); w.Code != http.StatusBadRequest {
+			t.Errorf("%s: expected status code %d, got %d", target, http.StatusBadRequest, w.Code)
+		}
+	}
+}
+
+func TestRouter_Tiles(t *testing.T) {
+	router := NewRouter(newTestStore(t), logger.New("", false))
+
+	w := serve(t, router, "/tiles/10/813/450.mvt") // contains Phường Ba Đình
+	if w.Code != http.StatusOK || w.Body.Len() == 0 {
+		t.Fatalf("Expected a non-empty tile, got status %d with %d bytes", w.Code, w.Body.Len())
+	}
+	if ct := w.Header().Get("Content-Type"); ct != "application/vnd.mapbox-vector-tile" {
+		t.Errorf("Expected Content-Type application/vnd.mapbox-vector-tile, got %q", ct)
+	}
+
+	tests := []struct {
+		target string
+		want   int
+	}{
+		{"/tiles/10/0/0.mvt", http.StatusNoContent},
+		{"/tiles/25/0/0.mvt", http.StatusBadRequest},
+		{"/tiles/10/813/450.png", http.StatusNotFound},
+	}
+	for _, tt := range tests {
+		if w := serve(t, router, tt.target); w.Code != tt.want {
+			t.Errorf("%s: expected status code %d, got %d", tt.target, tt.want, w.Code)
+		}
+	}
+}
+
+func TestRouter_ListUnitsGeo(t *testing.T) {
+	router := NewRouter(newTestStore(t), logger.New("", false))
+
+	decode := func(w *httptest.ResponseRecorder) ([]models.NearbyUnit, map[string]interface{}) {
+		t.Helper()
+		if w.Code != http.StatusOK {
+			t.Fatalf("Expected status code %d, got %d: %s", http.StatusOK, w.Code, w.Body)
+		}
+		var response struct {
+			Data []models.NearbyUnit    `json:"data"`
+			Meta map[string]interface{} `json:"meta"`
+		}
+		if err := json.NewDecoder(w.Body).Decode(&response); err != nil {
+			t.Fatalf("Failed to decode response: %v", err)
+		}
+		return response.Data, response.Meta
+	}
+
+	// Ba Đình (~1 km) and Sơn Tây (~38 km) are within 50 km of Hoàn Kiếm; Bến Thành is not
+	units, meta := decode(serve(t, router, "/api/v1/units?near=21.03,105.85&radius_km=50"))
+	if len(units) != 2 || units[0].ID != 101 || units[1].ID != 102 || units[0].DistanceKm >= units[1].DistanceKm {
+		t.Errorf("Unexpected near results %+v", units)
+	}
+	if meta["total"] != float64(2) {
+		t.Errorf("Expected total 2, got %v", meta["total"])
+	}
+
+	units, meta = decode(serve(t, router, "/api/v1/units?near=21.03,105.85&radius_km=50&limit=1&offset=1"))
+	if len(units) != 1 || units[0].ID != 102 || meta["total"] != float64(2) {
+		t.Errorf("Unexpected second page %+v / %v", units, meta)
+	}
+
+	units, _ = decode(serve(t, router, "/api/v1/units?bbox=105,20.5,106,21.5"))
+	if len(units) != 2 || units[0].ID != 101 || units[1].ID != 102 {
+		t.Errorf("Unexpected bbox results %+v", units)
+	}
+
+	units, meta = decode(serve(t, router, "/api/v1/units?bbox=105,20.5,106,21.5&offset=5"))
+	if len(units) != 0 || meta["next_cursor"] != nil {
+		t.Errorf("Expected an empty last page past the results, got %+v / %v", units, meta)
+	}
+
+	// Huge offsets must not overflow offset+limit into a panic
+	huge := "9223372036854775807"
+	for _, target := range []string{
+		"/api/v1/units?near=21.03,105.85&radius_km=50&offset=" + huge,
+		"/api/v1/units?bbox=105,20.5,106,21.5&offset=" + huge,
+		"/api/v1/units?near=21.03,105.85&radius_km=50&cursor=" + encodeUnitsCursor(unitsCursor{Sort: "near", Offset: math.MaxInt}),
+		"/api/v1/units?bbox=105,20.5,106,21.5&cursor=" + encodeUnitsCursor(unitsCursor{Sort: "bbox", Offset: math.MaxInt}),
+	} {
+		if w := serve(t, router, target); w.Code != http.StatusBadRequest {
+			t.Errorf("%s: expected status code %d, got %d", target, http.StatusBadRequest, w.Code)
+		}
+	}
+
+	for _, target := range []string{
+		"/api/v1/units?near=21.03,105.85",
+		"/api/v1/units?near=21.03&radius_km=5",
+		"/api/v1/units?bbox=106,20,105,21",
+		"/api/v1/units?bbox=105,20,106,21&near=21,105&radius_km=5",
+		"/api/v1/units?bbox=105,20,106,21&limit=0",
+	} {
+		if w := serve(t, router, target); w.Code != http.StatusBadRequest {
+			t.Errorf("%s: expected status code %d, got %d", target, http.StatusBadRequest, w.Code)
+		}
+	}
+}
+
+func TestRouter_ListUnitsFiltered(t *testing.T) {
+	router := NewRouter(newTestStore(t), logger.New("", false))
+
+	list := func(target string) ([]int, map[string]interface{}) {
+		t.Helper()
+		w := serve(t, router, target)
+		if w.Code != http.StatusOK {
+			t.Fatalf("%s: expected status code %d, got %d: %s", target, http.StatusOK, w.Code, w.Body)
+		}
+		var response struct {
+			Data []models.AdminUnit     `json:"data"`
+			Meta map[string]interface{} `json:"meta"`
+		}
+		if err := json.NewDecoder(w.Body).Decode(&response); err != nil {
+			t.Fatalf("Failed to decode response: %v", err)
+		}
+		ids := make([]int, len(response.Data))
+		for i, u := range response.Data {
+			ids[i] = u.ID
+		}
+		return ids, response.Meta
+	}
+
+	tests := []struct {
+		target string
+		want   []int
+	}{
+		{"/api/v1/units", []int{101, 102, 201}},
+		{"/api/v1/units?province_id=1", []int{101, 102}},
+		{"/api/v1/units?level=phuong", []int{101, 201}},
+		{"/api/v1/units?level=commune", []int{102}},
+		{"/api/v1/units?level=special_zone", []int{}},
+		{"/api/v1/units?name=ben%20thanh", []int{201}},
+		{"/api/v1/units?has_coordinates=false", []int{}},
+		{"/api/v1/units?updated_since=2000-01-01T00:00:00Z&order=desc", []int{201, 102, 101}},
+		{"/api/v1/units?sort=name", []int{101, 201, 102}},
+		{"/api/v1/units?sort=name&order=desc&province_id=1", []int{102, 101}},
+	}
+	for _, tt := range tests {
+		if got, meta := list(tt.target); !slices.Equal(got, tt.want) || meta["total"] != float64(len(tt.want)) {
+			t.Errorf("%s: expected units %v, got %v (meta %v)", tt.target, tt.want, got, meta)
+		}
+	}
+
+	// Walk the name-sorted listing one unit per page
+	var walked []int
+	target := "/api/v1/units?sort=name&limit=1"
+	for range 5 {
+		ids, meta := list(target)
+		walked = append(walked, ids...)
+		if meta["total"] != float64(3) {
+			t.Errorf("Expected total 3 on every page, got %v", meta["total"])
+		}
+		next, ok := meta["next_cursor"].(string)
+		if !ok {
+			break
+		}
+		target = "/api/v1/units?sort=name&limit=1&cursor=" + next
+	}
+	if !slices.Equal(walked, []int{101, 201, 102}) {
+		t.Errorf("Expected cursor walk [101 201 102], got %v", walked)
+	}
+
+	_, meta := list("/api/v1/units?limit=2")
+	cursor, _ := meta["next_cursor"].(string)
+	for _, target := range []string{
+		"/api/v1/units?sort=name&cursor=" + cursor,
+		"/api/v1/units?cursor=not-a-cursor",
+		"/api/v1/units?offset=1",
+		"/api/v1/units?sort=population",
+		"/api/v1/units?order=up",
+		"/api/v1/units?has_coordinates=maybe",
+		"/api/v1/units?updated_since=yesterday",
+		"/api/v1/units?bbox=105,20,106,21&sort=name",
+		"/api/v1/units?level=thon",
+	} {
+		if w := serve(t, router, target); w.Code != http.StatusBadRequest {
+			t.Errorf("%s: expected status code %d, got %d", target, http.StatusBadRequest, w.Code)
+		}
+	}
+}
+
+func TestRouter_ExternalIDs(t *testing.T) {
+	store := newTestStore(t)
+	ids := []models.ExternalID{
+		{System: "ghn", ExternalID: "1A0101", Kind: models.RefKindUnit, RefID: 101},
+		{System: "ghn", ExternalID: "1A0102", Kind: models.RefKindUnit, RefID: 101},
+		{System: "ghn", ExternalID: "201", Kind: models.RefKindProvince, RefID: 1},
+	}
+	if err := store.SaveExternalIDs(context.Background(), "ghn", ids, false); err != nil {
+		t.Fatalf("Failed to seed external IDs: %v", err)
+	}
+	ghtk := []models.ExternalID{{System: "ghtk", ExternalID: "x-101", Kind: models.RefKindUnit, RefID: 101}}
+	if err := store.SaveExternalIDs(context.Background(), "ghtk", ghtk, false); err != nil {
+		t.Fatalf("Failed to seed external IDs: %v", err)
+	}
+	router := NewRouter(store, logger.New("", false))
+
+	list := func(target string) []string {
+		t.Helper()
+		w := serve(t, router, target)
+		var response struct {
+			Data []models.ExternalID `json:"data"`
+		}
+		if err := json.NewDecoder(w.Body).Decode(&response); err != nil {
+			t.Fatalf("Failed to decode response: %v", err)
+		}
+		got := make([]string, len(response.Data))
+		for i, e := range response.Data {
+			got[i] = e.System + ":" + e.ExternalID
+		}
+		return got
+	}
+	if got := list("/api/v1/units/101/external-ids"); !slices.Equal(got, []string{"ghn:1A0101", "ghn:1A0102", "ghtk:x-101"}) {
+		t.Errorf("Unexpected unit external IDs %v", got)
+	}
+	if got := list("/api/v1/units/101/external-ids?system=GHTK"); !slices.Equal(got, []string{"ghtk:x-101"}) {
+		t.Errorf("Unexpected filtered external IDs %v", got)
+	}
+	if got := list("/api/v1/provinces/1/external-ids"); !slices.Equal(got, []string{"ghn:201"}) {
+		t.Errorf("Unexpected province external IDs %v", got)
+	}
+
+	w := serve(t, router, "/api/v1/external/ghn/1A0102")
+	var response struct {
+		Data struct {
+			Kind  string            `json:"kind"`
+			RefID int               `json:"ref_id"`
+			Unit  *models.AdminUnit `json:"unit"`
+		} `json:"data"`
+	}
+	if err := json.NewDecoder(w.Body).Decode(&response); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	if response.Data.Kind != models.BoundaryUnit || response.Data.Unit == nil || response.Data.Unit.ID != 101 {
+		t.Errorf("Expected ghn 1A0102 to resolve to unit 101, got %+v", response.Data)
+	}
+	if w := serve(t, router, "/api/v1/external/ghn/201"); !strings.Contains(w.Body.String(), `"province":{"id":1`) {
+		t.Errorf("Expected ghn 201 to resolve to province 1, got %s", w.Body)
+	}
+
+	for _, tt := range []struct {
+		target string
+		want   int
+	}{
+		{"/api/v1/external/ghn/nope", http.StatusNotFound},
+		{"/api/v1/external/g%20hn/1A0101", http.StatusBadRequest},
+		{"/api/v1/units/999/external-ids", http.StatusNotFound},
+		{"/api/v1/provinces/99/external-ids", http.StatusNotFound},
+		{"/api/v1/units/abc/external-ids", http.StatusBadRequest},
+	} {
+		if w := serve(t, router, tt.target); w.Code != tt.want {
+			t.Errorf("%s: expected status code %d, got %d", tt.target, tt.want, w.Code)
+		}
+	}
+}
+
+func TestRouter_History(t *testing.T) {
+	ctx := context.Background()
+	store := newTestStore(t)
+	time.Sleep(2 * time.Millisecond)
+	before := time.Now().Format(time.RFC3339Nano)
+	time.Sleep(2 * time.Millisecond)
+
+	unit, _ := store.GetAdminUnit(ctx, 101)
+	if err := store.UpsertAdminUnit(ctx, *unit); err != nil { // Unchanged: no new version
+		t.Fatalf("Failed to upsert unit: %v", err)
+	}
+	renamed := *unit
+	renamed.Name = "Phường Ba Đình Mới"
+	if err := store.UpsertAdminUnit(ctx, renamed); err != nil {
+		t.Fatalf("Failed to upsert unit: %v", err)
+	}
+	router := NewRouter(store, logger.New("", false))
+
+	w := serve(t, router, "/api/v1/units/101/history")
+	var history struct {
+		Data []models.UnitVersion `json:"data"`
+	}
+	if err := json.NewDecoder(w.Body).Decode(&history); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	if v := history.Data; len(v) != 2 || v[0].Name != "Phường Ba Đình" || v[0].ValidTo == nil ||
+		!v[0].ValidTo.Equal(v[1].ValidFrom) || v[1].Name != renamed.Name || v[1].ValidTo != nil {
+		t.Fatalf("Unexpected history %+v", v)
+	}
+
+	asOf := "as_of=" + url.QueryEscape(before)
+	for _, tt := range []struct {
+		target string
+		want   string
+	}{
+		{"/api/v1/units/101?" + asOf, `"tenhc":"Phường Ba Đình"`},
+		{"/api/v1/units/101", `"tenhc":"Phường Ba Đình Mới"`},
+		{"/api/v1/provinces/1/units?" + asOf, `"tenhc":"Phường Ba Đình",`},
+		{"/api/v1/units?name=moi&" + asOf, `"total":0`},
+		{"/api/v1/units?name=moi", `"total":1`},
+		{"/api/v1/search?q=Ba+Dinh&" + asOf, `"tenhc":"Phường Ba Đình",`},
+		{"/api/v1/provinces?" + asOf, `"tentinh":"Thành phố Hà Nội"`},
+	} {
+		if w := serve(t, router, tt.target); w.Code != http.StatusOK || !strings.Contains(w.Body.String(), tt.want) {
+			t.Errorf("%s: expected %s, got %d %s", tt.target, tt.want, w.Code, w.Body)
+		}
+	}
+
+	for _, tt := range []struct {
+		target string
+		want   int
+	}{
+		{"/api/v1/units/101?as_of=2000-01-01", http.StatusNotFound},
+		{"/api/v1/provinces/1?as_of=2000-01-01", http.StatusNotFound},
+		{"/api/v1/provinces/1/units?as_of=2000-01-01", http.StatusNotFound},
+		{"/api/v1/units/999/history", http.StatusNotFound},
+		{"/api/v1/units?as_of=30/06/2025", http.StatusBadRequest},
+		{"/api/v1/units?near=21,105&radius_km=5&as_of=2025-06-30", http.StatusBadRequest},
+	} {
+		if w := serve(t, router, tt.target); w.Code != tt.want {
+			t.Errorf("%s: expected status code %d, got %d", tt.target, tt.want, w.Code)
+		}
+	}
+}
+
+func TestRouter_Changes(t *testing.T) {
+	ctx := context.Background()
+	store := newTestStore(t)
+	first := []models.Change{
+		{Kind: models.ChangeUnitAdded, RefID: 101, ProvinceID: 1, Name: "Phường Ba Đình"},
+		{Kind: models.ChangeUnitAdded, RefID: 102, ProvinceID: 1, Name: "Xã Sơn Tây"},
+	}
+	if err := store.SaveChanges(ctx, "run1", first); err != nil {
+		t.Fatalf("Failed to save changes: %v", err)
+	}
+	time.Sleep(2 * time.Millisecond)
+	since := time.Now().Format(time.RFC3339Nano)
+	time.Sleep(2 * time.Millisecond)
+	second := []models.Change{{Kind: models.ChangeUnitRenamed, RefID: 101, ProvinceID: 1, Name: "Phường Mới", OldValue: "Phường Ba Đình", NewValue: "Phường Mới"}}
+	if err := store.SaveChanges(ctx, "run2", second); err != nil {
+		t.Fatalf("Failed to save changes: %v", err)
+	}
+	router := NewRouter(store, logger.New("", false))
+
+	list := func(target string) ([]string, interface{}) {
+		t.Helper()
+		w := serve(t, router, target)
+		if w.Code != http.StatusOK {
+			t.Fatalf("%s: expected 200, got %d %s", target, w.Code, w.Body)
+		}
+		var response struct {
+			Data []models.Change        `json:"data"`
+			Meta map[string]interface{} `json:"meta"`
+		}
+		if err := json.NewDecoder(w.Body).Decode(&response); err != nil {
+			t.Fatalf("Failed to decode response: %v", err)
+		}
+		got := make([]string, len(response.Data))
+		for i, c := range response.Data {
+			got[i] = c.RunID + ":" + c.Kind + ":" + strconv.Itoa(c.RefID)
+		}
+		return got, response.Meta["next_cursor"]
+	}
+
+	if got, next := list("/api/v1/changes"); len(got) != 3 || next != nil {
+		t.Errorf("Unexpected changes %v (next %v)", got, next)
+	}
+	if got, _ := list("/api/v1/changes?since=" + url.QueryEscape(since)); !slices.Equal(got, []string{"run2:unit_renamed:101"}) {
+		t.Errorf("Unexpected changes since %v", got)
+	}
+	if got, _ := list("/api/v1/changes?run_id=run1"); len(got) != 2 {
+		t.Errorf("Unexpected changes of run1 %v", got)
+	}
+	got, next := list("/api/v1/changes?limit=2")
+	if !slices.Equal(got, []string{"run1:unit_added:101", "run1:unit_added:102"}) || next != "2" {
+		t.Fatalf("Unexpected first page %v (next %v)", got, next)
+	}
+	if got, _ := list("/api/v1/changes?limit=2&cursor=2"); !slices.Equal(got, []string{"run2:unit_renamed:101"}) {
+		t.Errorf("Unexpected second page %v", got)
+	}
+
+	for _, target := range []string{"/api/v1/changes?since=yesterday", "/api/v1/changes?limit=0", "/api/v1/changes?cursor=x"} {
+		if w := serve(t, router, target); w.Code != http.StatusBadRequest {
+			t.Errorf("%s: expected status code 400, got %d", target, w.Code)
+		}
+	}
+}
+
+func TestRouter_Sync(t *testing.T) {
+	ctx := context.Background()
+	store := newTestStore(t)
+	router := NewRouter(store, logger.New("", false))
+
+	type syncData struct {
+		Token            string             `json:"token"`
+		Full             bool               `json:"full"`
+		Provinces        []models.Province  `json:"provinces"`
+		Units            []models.AdminUnit `json:"units"`
+		DeletedProvinces []models.Tombstone `json:"deleted_provinces"`
+		DeletedUnits     []models.Tombstone `json:"deleted_units"`
+	}
+	sync := func(token string) syncData {
+		t.Helper()
+		w := serve(t, router, "/api/v1/sync?since_token="+url.QueryEscape(token))
+		if w.Code != http.StatusOK {
+			t.Fatalf("Expected 200, got %d %s", w.Code, w.Body)
+		}
+		var response struct {
+			Data syncData `json:"data"`
+		}
+		if err := json.NewDecoder(w.Body).Decode(&response); err != nil {
+			t.Fatalf("Failed to decode response: %v", err)
+		}
+		return response.Data
+	}
+	unitIDs := func(units []models.AdminUnit) []int {
+		ids := make([]int, len(units))
+		for i, u := range units {
+			ids[i] = u.ID
+		}
+		return ids
+	}
+
+	full := sync("")
+	if !full.Full || len(full.Provinces) != 2 || !slices.Equal(unitIDs(full.Units), []int{101, 102, 201}) || len(full.DeletedUnits) != 0 || full.Token == "" {
+		t.Fatalf("Unexpected full sync %+v", full)
+	}
+
+	time.Sleep(2 * time.Millisecond)
+	unit := full.Units[0]
+	unit.Name = "Phường Ba Đình Mới"
+	if err := store.UpsertAdminUnit(ctx, unit); err != nil {
+		t.Fatalf("Failed to update unit: %v", err)
+	}
+	if err := store.Retire(ctx, nil, []int{102}); err != nil {
+		t.Fatalf("Failed to retire unit: %v", err)
+	}
+
+	delta := sync(full.Token)
+	if delta.Full || len(delta.Provinces) != 0 || !slices.Equal(unitIDs(delta.Units), []int{101}) ||
+		len(delta.DeletedUnits) != 1 || delta.DeletedUnits[0].ID != 102 || len(delta.DeletedProvinces) != 0 {
+		t.Fatalf("Unexpected delta %+v", delta)
+	}
+	if empty := sync(delta.Token); len(empty.Units) != 0 || len(empty.DeletedUnits) != 0 {
+		t.Errorf("Expected an empty delta, got %+v", empty)
+	}
+
+	// Upserting a retired unit restores it
+	time.Sleep(2 * time.Millisecond)
+	if err := store.UpsertAdminUnit(ctx, full.Units[1]); err != nil {
+		t.Fatalf("Failed to restore unit: %v", err)
+	}
+	if restored := sync(delta.Token); !slices.Equal(unitIDs(restored.Units), []int{102}) || len(restored.DeletedUnits) != 0 {
+		t.Errorf("Unexpected delta after restore %+v", restored)
+	}
+
+	for _, token := range []string{"x", "bm90IGpzb24", "e30"} {
+		if w := serve(t, router, "/api/v1/sync?since_token="+token); w.Code != http.StatusBadRequest {
+			t.Errorf("since_token=%s: expected status code 400, got %d", token, w.Code)
+		}
+	}
+}
+
+func TestRouter_Retired(t *testing.T) {
+	store := newTestStore(t)
+	if err := store.Retire(context.Background(), nil, []int{102}); err != nil {
+		t.Fatalf("Failed to retire unit: %v", err)
+	}
+	router := NewRouter(store, logger.New("", false))
+
+	ids := func(target string) []int {
+		t.Helper()
+		w := serve(t, router, target)
+		if w.Code != http.StatusOK {
+			t.Fatalf("%s: expected 200, got %d %s", target, w.Code, w.Body)
+		}
+		var response struct {
+			Data []struct {
+				ID int `json:"id"`
+			} `json:"data"`
+		}
+		if err := json.NewDecoder(w.Body).Decode(&response); err != nil {
+			t.Fatalf("Failed to decode response: %v", err)
+		}
+		got := make([]int, len(response.Data))
+		for i, d := range response.Data {
+			got[i] = d.ID
+		}
+		return got
+	}
+
+	for target, want := range map[string][]int{
+		"/api/v1/provinces/1/units":                      {101},
+		"/api/v1/provinces/1/units?include_retired=true": {101, 102},
+		"/api/v1/units":                                  {101, 201},
+		"/api/v1/units?include_retired=true":             {101, 102, 201},
+		"/api/v1/search?q=son%20tay":                     {},
+		"/api/v1/search?q=son%20tay&include_retired=1":   {102},
+	} {
+		if got := ids(target); !slices.Equal(got, want) {
+			t.Errorf("%s: got %v, want %v", target, got, want)
+		}
+	}
+
+	if w := serve(t, router, "/api/v1/units/102"); w.Code != http.StatusNotFound {
+		t.Errorf("Expected a retired unit to be hidden, got %d", w.Code)
+	}
+	w := serve(t, router, "/api/v1/units/102?include_retired=true")
+	var response struct {
+		Data models.AdminUnit `json:"data"`
+	}
+	if err := json.NewDecoder(w.Body).Decode(&response); err != nil || w.Code != http.StatusOK || response.Data.RetiredAt == nil {
+		t.Errorf("Expected the retired unit with retired_at, got %d %+v", w.Code, response.Data)
+	}
+	if w := serve(t, router, "/api/v1/codes/units/09574"); w.Code != http.StatusNotFound {
+		t.Errorf("Expected a retired unit code to be hidden, got %d", w.Code)
+	}
+
+	for _, target := range []string{
+		"/api/v1/units/102?include_retired=maybe",
+		"/api/v1/provinces?include_retired=true&as_of=2025-07-01",
+		"/api/v1/units?include_retired=true&near=21.03,105.84&radius_km=5",
+	} {
+		if w := serve(t, router, target); w.Code != http.StatusBadRequest {
+			t.Errorf("%s: expected status code 400, got %d", target, w.Code)
+		}
+	}
+}
+
+func TestRouter_StagedPublish(t *testing.T) {
+	ctx := context.Background()
+	store := newTestStore(t)
+	appCache := cache.NewMemoryCache(time.Minute)
+	router := NewRouterWithCache(store, logger.New("", false), appCache)
+
+	unitName := func(target string) string {
+		t.Helper()
+		w := serve(t, router, target)
+		if w.Code == http.StatusNotFound {
+			return ""
+		}
+		var response struct {
+			Data models.AdminUnit `json:"data"`
+		}
+		if err := json.NewDecoder(w.Body).Decode(&response); err != nil {
+			t.Fatalf("%s: failed to decode %d response: %v", target, w.Code, err)
+		}
+		return response.Data.Name
+	}
+	if got := unitName("/api/v1/units/101"); got != "Phường Ba Đình" {
+		t.Fatalf("Unexpected unit before staging: %q", got)
+	}
+
+	release, err := store.BeginStaging(ctx)
+	if err != nil {
+		t.Fatalf("Failed to begin staging: %v", err)
+	}
+	defer release()
+	if _, err := store.BeginStaging(ctx); !errors.Is(err, storage.ErrStagingBusy) {
+		t.Errorf("Expected an overlapping crawl to be refused, got %v", err)
+	}
+
+	renamed := models.AdminUnit{ID: 101, ProvinceID: 1, Name: "Phường Ba Đình Mới", Level: "Phường", LevelCode: levels.Ward, Code: "00004", Lat: 21.034, Long: 105.84}
+	preds := []models.Predecessor{{UnitID: 101, Name: "Phường Quán Thánh", Level: "Phường"}}
+	if err := store.StageProvince(ctx, "run1", models.Province{ID: 1, Name: "Thành phố Hà Nội", Code: 1}, []models.AdminUnit{renamed}, preds); err != nil {
+		t.Fatalf("Failed to stage: %v", err)
+	}
+	if got, _ := store.GetAdminUnit(ctx, 101); got.Name != "Phường Ba Đình" {
+		t.Errorf("Staging changed the live unit: %q", got.Name)
+	}
+
+	changes := []models.Change{
+		{Kind: models.ChangeUnitRenamed, RefID: 101, ProvinceID: 1, Name: renamed.Name, OldValue: "Phường Ba Đình", NewValue: renamed.Name},
+		{Kind: models.ChangeUnitRemoved, RefID: 102, ProvinceID: 1, Name: "Xã Sơn Tây"},
+	}
+	if err := store.Publish(ctx, "run1", changes); err != nil {
+		t.Fatalf("Failed to publish: %v", err)
+	}
+	if err := store.Publish(ctx, "run1", changes); err == nil {
+		t.Error("Expected a published run to be gone from staging")
+	}
+
+	if got := unitName("/api/v1/units/101"); got != "Phường Ba Đình" {
+		t.Errorf("Expected the cached unit until the cache is flushed, got %q", got)
+	}
+	if err := appCache.Flush(ctx); err != nil {
+		t.Fatalf("Failed to flush cache: %v", err)
+	}
+	if got := unitName("/api/v1/units/101"); got != renamed.Name {
+		t.Errorf("Expected the published unit, got %q", got)
+	}
+	if got := unitName("/api/v1/units/102"); got != "" {
+		t.Errorf("Expected the removed unit to be retired, got %q", got)
+	}
+	if got, _ := store.GetPredecessors(ctx, 101); len(got) != 1 || got[0].Name != "Phường Quán Thánh" {
+		t.Errorf("Unexpected predecessors %+v", got)
+	}
+	if got, _ := store.GetChanges(ctx, models.ChangeQuery{RunID: "run1", Limit: 10}); len(got) != 2 {
+		t.Errorf("Expected 2 changes of run1, got %+v", got)
+	}
+}
+
+func TestRouter_GetPredecessors(t *testing.T) {
+	store := newTestStore(t)
+	preds := []models.Predecessor{
+		{Name: "Quán Thánh", Level: "Phường", District: "Quận Ba Đình"},
+		{Name: "Trúc Bạch", Level: "Phường", District: "Quận Ba Đình", Partial: true},
+	}
+	if err := store.ReplacePredecessors(context.Background(), 101, preds); err != nil {
+		t.Fatalf("Failed to seed predecessors: %v", err)
+	}
+	router := NewRouter(store, logger.New("", false))
+
+	w := serve(t, router, "/api/v1/units/101/predecessors")
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status code %d, got %d", http.StatusOK, w.Code)
+	}
+	var response struct {
+		Data []models.Predecessor `json:"data"`
+	}
+	if err := json.NewDecoder(w.Body).Decode(&response); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	if len(response.Data) != 2 || response.Data[0].UnitID != 101 || !response.Data[1].Partial {
+		t.Errorf("Unexpected predecessors %+v", response.Data)
+	}
+
+	if w := serve(t, router, "/api/v1/units/999/predecessors"); w.Code != http.StatusNotFound {
+		t.Errorf("Expected status code %d for unknown unit, got %d", http.StatusNotFound, w.Code)
+	}
+}
+
+func TestRouter_Convert(t *testing.T) {
+	ctx := context.Background()
+	store := newTestStore(t)
+	seed := map[int][]models.Predecessor{
+		101: {
+			{Name: "Quán Thánh", Level: "Phường", District: "Quận Ba Đình"},
+			{Name: "Trúc Bạch", Level: "Phường", District: "Quận Ba Đình", Partial: true},
+		},
+		102: {
+			{Name: "Trúc Bạch", Level: "Phường", District: "Quận Ba Đình", Partial: true},
+		},
+	}
+	for id, preds := range seed {
+		if err := store.ReplacePredecessors(ctx, id, preds); err != nil {
+			t.Fatalf("Failed to seed predecessors: %v", err)
+		}
+	}
+	router := NewRouter(store, logger.New("", false))
+
+	tests := []struct {
+		name       string
+		body       string
+		wantUnits  []int
+		confidence string
+		split      bool
+	}{
+		{"whole merge", `{"ward": "phuong quan thanh", "district": "Quận Ba Đình"}`, []int{101}, "high", false},
+		{"split across units", `{"address": "12 Hàng Bè, Phường Trúc Bạch, Quận Ba Đình, Hà Nội"}`, []int{101, 102}, "medium", true},
+		{"already a current unit", `{"ward": "Phường Bến Thành"}`, []int{201}, "medium", false},
+		{"unknown", `{"ward": "Xã Không Tồn Tại"}`, []int{}, "none", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("POST", "/api/v1/convert", strings.NewReader(tt.body))
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+			if w.Code != http.StatusOK {
+				t.Fatalf("Expected status code %d, got %d: %s", http.StatusOK, w.Code, w.Body)
+			}
+
+			var response struct {
+				Data convert.Result `json:"data"`
+			}
+			if err := json.NewDecoder(w.Body).Decode(&response); err != nil {
+				t.Fatalf("Failed to decode response: %v", err)
+			}
+			got := make([]int, len(response.Data.Matches))
+			for i, m := range response.Data.Matches {
+				got[i] = m.Unit.ID
+			}
+			if !reflect.DeepEqual(got, tt.wantUnits) || response.Data.Confidence != tt.confidence || response.Data.Split != tt.split {
+				t.Errorf("Got units %v confidence %q split %v, want %v %q %v",
+					got, response.Data.Confidence, response.Data.Split, tt.wantUnits, tt.confidence, tt.split)
+			}
+		})
+	}
+
+	req := httptest.NewRequest("POST", "/api/v1/convert", strings.NewReader(`{}`))
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("Expected status code %d for empty input, got %d", http.StatusBadRequest, w.Code)
+	}
+}
+
+func TestRouter_ParseAddress(t *testing.T) {
+	router := NewRouter(newTestStore(t), logger.New("", false))
+
+	tests := []struct {
+		name                   string
+		body                   string
+		wantWard, wantProvince int
+		wantStreet             string
+		wantInferredProvince   bool
+	}{
+		{"abbreviations", `{"address": "123 Lê Lợi, P. Bến Thành, Q.1, TP.HCM"}`, 201, 2, "123 Lê Lợi", false},
+		{"no diacritics, province inferred", `{"address": "so 5 pho Quan Thanh, phuong ba dinh"}`, 101, 1, "so 5 pho Quan Thanh", true},
+		{"unknown ward", `{"address": "Phường Không Tồn Tại, Hà Nội"}`, 0, 1, "", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("POST", "/api/v1/address/parse", strings.NewReader(tt.body))
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+			if w.Code != http.StatusOK {
+				t.Fatalf("Expected status code %d, got %d: %s", http.StatusOK, w.Code, w.Body)
+			}
+
+			var response struct {
+				Data address.Address `json:"data"`
+			}
+			if err := json.NewDecoder(w.Body).Decode(&response); err != nil {
+				t.Fatalf("Failed to decode response: %v", err)
+			}
+			got := response.Data
+			if got.Ward == nil || got.Province == nil {
+				t.Fatalf("Expected ward and province, got %+v", got)
+			}
+			if got.Ward.ID != tt.wantWard || got.Province.ID != tt.wantProvince || got.Street != tt.wantStreet || got.Province.Inferred != tt.wantInferredProvince {
+				t.Errorf("Got ward %d province %d (inferred %v) street %q, want %d %d (%v) %q",
+					got.Ward.ID, got.Province.ID, got.Province.Inferred, got.Street,
+					tt.wantWard, tt.wantProvince, tt.wantInferredProvince, tt.wantStreet)
+			}
+		})
+	}
+
+	req := httptest.NewRequest("POST", "/api/v1/address/parse", strings.NewReader(`{"address": "  "}`))
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("Expected status code %d for empty address, got %d", http.StatusBadRequest, w.Code)
+	}
+}
+
+func TestRouter_ConvertCoords(t *testing.T) {
+	router := NewRouter(newTestStore(t), logger.New("", false))
+	post := func(body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest("POST", "/api/v1/coords/convert", strings.NewReader(body))
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	w := post(`{"from": "wgs84", "province_id": 1, "points": [{"lat": 21.0285, "lng": 105.8542}]}`)
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status code %d, got %d: %s", http.StatusOK, w.Code, w.Body)
+	}
+	var response struct {
+		Data coordsResult `json:"data"`
+	}
+	if err := json.NewDecoder(w.Body).Decode(&response); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	p := response.Data.Points[0]
+	if response.Data.Zone.CentralMeridian != 105 || p.X < 2.32e6 || p.X > 2.33e6 || p.Y < 5.8e5 || p.Y > 5.9e5 {
+		t.Fatalf("Unexpected conversion %+v", response.Data)
+	}
+
+	// And back, with an explicit zone
+	body, _ := json.Marshal(map[string]interface{}{"from": "vn2000", "central_meridian": 105, "points": []coordPoint{{X: p.X, Y: p.Y}}})
+	w = post(string(body))
+	if err := json.NewDecoder(w.Body).Decode(&response); err != nil || w.Code != http.StatusOK {
+		t.Fatalf("Expected status code %d, got %d (%v)", http.StatusOK, w.Code, err)
+	}
+	if back := response.Data.Points[0]; math.Abs(back.Lat-21.0285) > 1e-8 || math.Abs(back.Lng-105.8542) > 1e-8 {
+		t.Errorf("Round trip = %+v", back)
+	}
+
+	tests := []struct {
+		body string
+		want int
+	}{
+		{`{"from": "utm", "province_id": 1, "points": [{"lat": 21, "lng": 105}]}`, http.StatusBadRequest},
+		{`{"from": "wgs84", "points": [{"lat": 21, "lng": 105}]}`, http.StatusBadRequest},
+		{`{"from": "wgs84", "province_id": 1, "points": []}`, http.StatusBadRequest},
+		{`{"from": "wgs84", "province_id": 1, "points": [{"lat": 100, "lng": 105}]}`, http.StatusBadRequest},
+		{`{"from": "wgs84", "central_meridian": 5, "points": [{"lat": 21, "lng": 105}]}`, http.StatusBadRequest},
+		{`{"from": "wgs84", "province_id": 99, "points": [{"lat": 21, "lng": 105}]}`, http.StatusNotFound},
+	}
+	for _, tt := range tests {
+		if w := post(tt.body); w.Code != tt.want {
+			t.Errorf("%s: expected status code %d, got %d", tt.body, tt.want, w.Code)
+		}
+	}
+}
+
+func TestRouter_Jobs(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	store := newTestStore(t)
+	if err := store.ReplacePredecessors(ctx, 101, []models.Predecessor{
+		{Name: "Quán Thánh", Level: "Phường", District: "Quận Ba Đình"},
+	}); err != nil {
+		t.Fatalf("Failed to seed predecessors: %v", err)
+	}
+	log := logger.New("", false)
+	manager := jobs.NewManager(store, log, 1)
+	manager.Start(ctx)
+	router := NewRouter(store, log, WithJobs(manager))
+
+	body := "id,ward,district\n1,Phường Quán Thánh,Quận Ba Đình\n2,Xã Không Tồn Tại,\n"
+	req := httptest.NewRequest("POST", "/api/v1/jobs", strings.NewReader(body))
+	req.Header.Set("Content-Type", "text/csv")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	if w.Code != http.StatusAccepted {
+		t.Fatalf("Expected status code %d, got %d: %s", http.StatusAccepted, w.Code, w.Body)
+	}
+	var created struct {
+		Data models.Job `json:"data"`
+	}
+	if err := json.NewDecoder(w.Body).Decode(&created); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	if created.Data.Total != 2 || created.Data.Status != models.JobQueued {
+		t.Fatalf("Unexpected job %+v", created.Data)
+	}
+
+	// Poll until the worker finishes
+	var job models.Job
+	for deadline := time.Now().Add(5 * time.Second); ; {
+		w := serve(t, router, "/api/v1/jobs/"+created.Data.ID)
+		var response struct {
+			Data models.Job `json:"data"`
+		}
+		if err := json.NewDecoder(w.Body).Decode(&response); err != nil {
+			t.Fatalf("Failed to decode response: %v", err)
+		}
+		job = response.Data
+		if job.Status == models.JobCompleted || job.Status == models.JobFailed || time.Now().After(deadline) {
+			break
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	if job.Status != models.JobCompleted || job.Processed != 2 || job.Matched != 1 {
+		t.Fatalf("Unexpected job state %+v", job)
+	}
+
+	w = serve(t, router, "/api/v1/jobs/"+job.ID+"/result")
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status code %d, got %d", http.StatusOK, w.Code)
+	}
+	want := "id,ward,district,new_province_id,new_province_name,new_unit_id,new_unit_name,match_quality,split\n" +
+		"1,Phường Quán Thánh,Quận Ba Đình,1,Thành phố Hà Nội,101,Phường Ba Đình,high,false\n" +
+		"2,Xã Không Tồn Tại,,,,,,none,false\n"
+	if got := w.Body.String(); got != want {
+		t.Errorf("Unexpected result file:\n%s\nwant:\n%s", got, want)
+	}
+
+	if w := serve(t, router, "/api/v1/jobs/unknown"); w.Code != http.StatusNotFound {
+		t.Errorf("Expected status code %d for unknown job, got %d", http.StatusNotFound, w.Code)
+	}
+
+	req = httptest.NewRequest("POST", "/api/v1/jobs?format=csv", strings.NewReader("name\nfoo\n"))
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("Expected status code %d without an address column, got %d", http.StatusBadRequest, w.Code)
+	}
+}
@@ -0,0 +1,57 @@
+package api
+
+import (
+	"net/http"
+	"strings"
+
+	"vn-admin-api/internal/tiles"
+)
+
+// GetTile handles GET /tiles/{z}/{x}/{y}.mvt
+// Tiles are rendered from the in-memory indexes and cached under the indexes' data version.
+func (h *Handler) GetTile(w http.ResponseWriter, r *http.Request) {
+	y, ok := strings.CutSuffix(r.PathValue("y"), ".mvt")
+	if !ok {
+		h.respondError(w, http.StatusNotFound, "Not Found")
+		return
+	}
+	t, err := tiles.ParseTile(r.PathValue("z"), r.PathValue("x"), y)
+	if err != nil {
+		h.respondError(w, http.StatusBadRequest, "Invalid tile: "+err.Error())
+		return
+	}
+
+	ctx := r.Context()
+	if !h.spatial.Ready() {
+		if _, err := h.spatial.Rebuild(ctx); err != nil {
+			h.log.Error("Failed to build spatial index", "error", err)
+			h.respondError(w, http.StatusInternalServerError, "Internal Server Error")
+			return
+		}
+	}
+	if !h.outlines.Ready() {
+		if _, err := h.outlines.Rebuild(ctx); err != nil {
+			h.log.Error("Failed to build boundary index", "error", err)
+			h.respondError(w, http.StatusInternalServerError, "Internal Server Error")
+			return
+		}
+	}
+
+	key := tiles.Key(t, h.spatial.Version()+"|"+h.outlines.Version())
+	data, cached := h.cache.GetTile(ctx, key)
+	if !cached {
+		data = tiles.Render(t, h.spatial, h.outlines)
+		// Store in cache (ignore error for cache)
+		_ = h.cache.SetTile(ctx, key, data)
+	}
+
+	if len(data) == 0 {
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+	w.Header().Set("Content-Type", "application/vnd.mapbox-vector-tile")
+	w.WriteHeader(http.StatusOK)
+	if _, err := w.Write(data); err != nil {
+		h.log.Error("Failed to write tile", "tile", t.String(), "error", err)
+	}
+}
@@ -0,0 +1,313 @@
+package api
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"vn-admin-api/internal/geo"
+	"vn-admin-api/internal/levels"
+	"vn-admin-api/internal/models"
+	"vn-admin-api/internal/storage"
+)
+
+// Pagination and radius limits for GET /api/v1/units
+const (
+	defaultUnitsLimit = 100
+	maxUnitsLimit     = 1000
+	maxUnitsOffset    = 100000 // Far beyond any result; keeps offset+limit from overflowing
+	maxRadiusKm       = 500
+)
+
+// unitsCursor is the decoded form of the opaque cursor query value. Filter listings resume after
+// the keyset position; near/bbox listings resume at Offset. Sort records which listing issued it.
+type unitsCursor struct {
+	Sort   string `json:"s"`
+	Desc   bool   `json:"d,omitempty"`
+	Offset int    `json:"o,omitempty"`
+	models.UnitCursor
+}
+
+func encodeUnitsCursor(c unitsCursor) string {
+	data, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+func decodeUnitsCursor(s string) (*unitsCursor, error) {
+	data, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, err
+	}
+	var c unitsCursor
+	if err := json.Unmarshal(data, &c); err != nil {
+		return nil, err
+	}
+	return &c, nil
+}
+
+// ListUnits handles GET /api/v1/units. Filters: province_id, level (loai), name, has_coordinates,
+// updated_since (RFC 3339). Without near/bbox the filtered units are sorted by sort=id|name|updated_at
+// and order=asc|desc, as_of=YYYY-MM-DD lists the units as they were then, and include_retired=true
+// adds the retired units. With near=lat,lng&radius_km=... results are ordered by distance and carry
+// distance_km; with bbox=minLng,minLat,maxLng,maxLat they are ordered by ID.
+// Pages are limit long; meta.next_cursor, passed back as cursor, fetches the next one.
+func (h *Handler) ListUnits(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	limit, offset, err := parsePage(q.Get("limit"), q.Get("offset"))
+	if err != nil {
+		h.respondError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	query, err := parseUnitQuery(q)
+	if err != nil {
+		h.respondError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	query.Limit = limit
+	asOf, err := parseAsOf(q)
+	if err != nil {
+		h.respondError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if query.IncludeRetired, err = parseIncludeRetired(q, asOf); err != nil {
+		h.respondError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	mode := query.Sort
+	near, bbox := q.Get("near"), q.Get("bbox")
+	switch {
+	case near != "" && bbox != "":
+		h.respondError(w, http.StatusBadRequest, "Only one of near or bbox may be given")
+		return
+	case near != "" || bbox != "":
+		if q.Has("sort") || q.Has("order") {
+			h.respondError(w, http.StatusBadRequest, "sort and order cannot be combined with near or bbox")
+			return
+		}
+		// The spatial index only holds the current units
+		if !asOf.IsZero() {
+			h.respondError(w, http.StatusBadRequest, "as_of cannot be combined with near or bbox")
+			return
+		}
+		if query.IncludeRetired {
+			h.respondError(w, http.StatusBadRequest, "include_retired cannot be combined with near or bbox")
+			return
+		}
+		mode = "bbox"
+		if near != "" {
+			mode = "near"
+		}
+	case q.Has("offset"):
+		h.respondError(w, http.StatusBadRequest, "offset is only supported with near or bbox; use cursor")
+		return
+	}
+
+	if s := q.Get("cursor"); s != "" {
+		c, err := decodeUnitsCursor(s)
+		if err != nil || c.Sort != mode || c.Desc != query.Desc || c.Offset < 0 || c.Offset > maxUnitsOffset {
+			h.respondError(w, http.StatusBadRequest, "Invalid cursor for this query")
+			return
+		}
+		query.After, offset = &c.UnitCursor, c.Offset
+	}
+
+	if near == "" && bbox == "" {
+		h.listUnitsFiltered(w, r, query, asOf)
+		return
+	}
+
+	if !h.spatial.Ready() {
+		if _, err := h.spatial.Rebuild(r.Context()); err != nil {
+			h.log.Error("Failed to build spatial index", "error", err)
+			h.respondError(w, http.StatusInternalServerError, "Internal Server Error")
+			return
+		}
+	}
+
+	match := storage.UnitMatcher(query)
+	var data interface{}
+	var total int
+	if near != "" {
+		coords, err := parseFloats(near, 2)
+		if err != nil || !geo.ValidCoordinate(coords[0], coords[1]) {
+			h.respondError(w, http.StatusBadRequest, "Invalid near (expected lat,lng)")
+			return
+		}
+		radius, err := strconv.ParseFloat(q.Get("radius_km"), 64)
+		if err != nil || radius <= 0 || radius > maxRadiusKm {
+			h.respondError(w, http.StatusBadRequest, fmt.Sprintf("Invalid radius_km (must be in (0, %d])", maxRadiusKm))
+			return
+		}
+		units := slices.DeleteFunc(h.spatial.Within(coords[0], coords[1], radius),
+			func(u models.NearbyUnit) bool { return !match(u.AdminUnit) })
+		total = len(units)
+		start := min(offset, total)
+		data = units[start : start+min(limit, total-start)]
+	} else {
+		c, err := parseFloats(bbox, 4)
+		if err != nil || !geo.ValidCoordinate(c[1], c[0]) || !geo.ValidCoordinate(c[3], c[2]) || c[0] > c[2] || c[1] > c[3] {
+			h.respondError(w, http.StatusBadRequest, "Invalid bbox (expected minLng,minLat,maxLng,maxLat)")
+			return
+		}
+		units := slices.DeleteFunc(h.spatial.InBBox(geo.BBox{MinLng: c[0], MinLat: c[1], MaxLng: c[2], MaxLat: c[3]}),
+			func(u models.AdminUnit) bool { return !match(u) })
+		total = len(units)
+		start := min(offset, total)
+		data = units[start : start+min(limit, total-start)]
+	}
+
+	var next interface{}
+	if limit < total-offset {
+		next = encodeUnitsCursor(unitsCursor{Sort: mode, Offset: offset + limit})
+	}
+	h.respondSuccessWithMeta(w, data, map[string]interface{}{
+		"total":       total,
+		"limit":       limit,
+		"offset":      offset,
+		"next_cursor": next,
+	})
+}
+
+// listUnitsFiltered serves the keyset-paginated listing without a spatial constraint, of the
+// current units or, for a non-zero asOf, of the units as they were then
+func (h *Handler) listUnitsFiltered(w http.ResponseWriter, r *http.Request, query models.UnitQuery, asOf time.Time) {
+	// Fetch one extra unit to learn whether another page follows
+	limit := query.Limit
+	query.Limit++
+	var units []models.AdminUnit
+	var total int
+	var err error
+	if asOf.IsZero() {
+		units, total, err = h.repo.ListAdminUnits(r.Context(), query)
+	} else if units, err = h.repo.GetUnitsAsOf(r.Context(), asOf, query.ProvinceID); err == nil {
+		units, total = storage.PageUnits(units, query)
+	}
+	if err != nil {
+		h.log.Error("Failed to list units", "error", err)
+		h.respondError(w, http.StatusInternalServerError, "Internal Server Error")
+		return
+	}
+
+	var next interface{}
+	if len(units) > limit {
+		units = units[:limit]
+		last := units[len(units)-1]
+		c := unitsCursor{Sort: query.Sort, Desc: query.Desc, UnitCursor: models.UnitCursor{ID: last.ID}}
+		switch query.Sort {
+		case models.UnitSortName:
+			c.Name = last.Name
+		case models.UnitSortUpdatedAt:
+			c.UpdatedAt = last.UpdatedAt
+		}
+		next = encodeUnitsCursor(c)
+	}
+	h.respondSuccessWithMeta(w, units, map[string]interface{}{
+		"total":       total,
+		"limit":       limit,
+		"next_cursor": next,
+	})
+}
+
+// parseUnitQuery reads the filter and sort parameters shared by every ListUnits mode
+func parseUnitQuery(q url.Values) (models.UnitQuery, error) {
+	query := models.UnitQuery{
+		Name: strings.TrimSpace(q.Get("name")),
+		Sort: models.UnitSortID,
+	}
+	level, err := parseLevel(q.Get("level"))
+	if err != nil {
+		return query, err
+	}
+	query.Level = level
+	if s := q.Get("province_id"); s != "" {
+		id, err := strconv.Atoi(s)
+		if err != nil || id <= 0 {
+			return query, fmt.Errorf("Invalid province_id")
+		}
+		query.ProvinceID = id
+	}
+	if s := q.Get("has_coordinates"); s != "" {
+		b, err := strconv.ParseBool(s)
+		if err != nil {
+			return query, fmt.Errorf("Invalid has_coordinates (expected true or false)")
+		}
+		query.HasCoordinates = &b
+	}
+	if s := q.Get("updated_since"); s != "" {
+		t, err := time.Parse(time.RFC3339, s)
+		if err != nil {
+			return query, fmt.Errorf("Invalid updated_since (expected RFC 3339, e.g. 2025-07-01T00:00:00Z)")
+		}
+		query.UpdatedSince = t
+	}
+	switch s := q.Get("sort"); s {
+	case "", models.UnitSortID, models.UnitSortName, models.UnitSortUpdatedAt:
+		if s != "" {
+			query.Sort = s
+		}
+	default:
+		return query, fmt.Errorf("Invalid sort (must be id, name or updated_at)")
+	}
+	switch q.Get("order") {
+	case "", "asc":
+	case "desc":
+		query.Desc = true
+	default:
+		return query, fmt.Errorf("Invalid order (must be asc or desc)")
+	}
+	return query, nil
+}
+
+// parseLevel resolves a level query value (code or Vietnamese name) to its code; "" means no filter
+func parseLevel(s string) (string, error) {
+	if strings.TrimSpace(s) == "" {
+		return "", nil
+	}
+	l, ok := levels.Lookup(s)
+	if !ok {
+		return "", fmt.Errorf("Unknown level %q (see /api/v1/levels)", s)
+	}
+	return l.Code, nil
+}
+
+// parsePage reads limit/offset query values, applying the defaults for empty ones
+func parsePage(limitStr, offsetStr string) (limit, offset int, err error) {
+	limit = defaultUnitsLimit
+	if limitStr != "" {
+		limit, err = strconv.Atoi(limitStr)
+		if err != nil || limit <= 0 || limit > maxUnitsLimit {
+			return 0, 0, fmt.Errorf("Invalid limit (must be 1-%d)", maxUnitsLimit)
+		}
+	}
+	if offsetStr != "" {
+		offset, err = strconv.Atoi(offsetStr)
+		if err != nil || offset < 0 || offset > maxUnitsOffset {
+			return 0, 0, fmt.Errorf("Invalid offset (must be 0-%d)", maxUnitsOffset)
+		}
+	}
+	return limit, offset, nil
+}
+
+// parseFloats splits a comma-separated list of exactly n numbers
+func parseFloats(s string, n int) ([]float64, error) {
+	parts := strings.Split(s, ",")
+	if len(parts) != n {
+		return nil, fmt.Errorf("expected %d numbers", n)
+	}
+	values := make([]float64, n)
+	for i, p := range parts {
+		v, err := strconv.ParseFloat(strings.TrimSpace(p), 64)
+		if err != nil {
+			return nil, err
+		}
+		values[i] = v
+	}
+	return values, nil
+}
@@ -3,18 +3,58 @@ package api
 import (
 	"net/http"
 
+	"vn-admin-api/internal/autocomplete"
 	"vn-admin-api/internal/cache"
-	"vn-admin-api/internal/database"
+	"vn-admin-api/internal/geo"
+	"vn-admin-api/internal/jobs"
 	"vn-admin-api/internal/logger"
+	"vn-admin-api/internal/storage"
 )
 
-func NewRouter(repo *database.Repository, log *logger.Logger) http.Handler {
+// Option configures optional handler dependencies
+type Option func(*Handler)
+
+// WithJobs enables the bulk conversion job endpoints
+func WithJobs(m *jobs.Manager) Option {
+	return func(h *Handler) {
+		h.jobs = m
+	}
+}
+
+// WithAutocomplete shares an index that is built and refreshed by the caller
+func WithAutocomplete(idx *autocomplete.Index) Option {
+	return func(h *Handler) {
+		h.suggest = idx
+	}
+}
+
+// WithSpatialIndex shares a coordinate index that is built and refreshed by the caller
+func WithSpatialIndex(idx *geo.Index) Option {
+	return func(h *Handler) {
+		h.spatial = idx
+	}
+}
+
+// WithBoundaryIndex shares a boundary index that is built and refreshed by the caller
+func WithBoundaryIndex(idx *geo.BoundaryIndex) Option {
+	return func(h *Handler) {
+		h.outlines = idx
+	}
+}
+
+func NewRouter(repo storage.Store, log *logger.Logger, opts ...Option) http.Handler {
 	handler := NewHandler(repo, log)
+	for _, opt := range opts {
+		opt(handler)
+	}
 	return buildRouter(handler, log)
 }
 
-func NewRouterWithCache(repo *database.Repository, log *logger.Logger, c cache.Cache) http.Handler {
+func NewRouterWithCache(repo storage.Store, log *logger.Logger, c cache.Cache, opts ...Option) http.Handler {
 	handler := NewHandlerWithCache(repo, log, c)
+	for _, opt := range opts {
+		opt(handler)
+	}
 	return buildRouter(handler, log)
 }
 
@@ -27,9 +67,36 @@ func buildRouter(handler *Handler, log *logger.Logger) http.Handler {
 	mux.HandleFunc("GET /", handler.Root)
 
 	// API Routes
+	mux.HandleFunc("GET /api/v1/levels", handler.GetLevels)
 	mux.HandleFunc("GET /api/v1/provinces", handler.GetProvinces)
+	mux.HandleFunc("GET /api/v1/provinces/{id}", handler.GetProvince)
 	mux.HandleFunc("GET /api/v1/provinces/{id}/units", handler.GetUnitsByProvince)
+	mux.HandleFunc("GET /api/v1/provinces/{id}/units.geojson", handler.GetUnitsGeoJSON)
+	mux.HandleFunc("GET /api/v1/provinces/{id}/external-ids", handler.GetProvinceExternalIDs)
+	mux.HandleFunc("GET /api/v1/units", handler.ListUnits)
+	mux.HandleFunc("GET /api/v1/units/{id}", handler.GetUnit)
+	mux.HandleFunc("GET /api/v1/units/{id}/predecessors", handler.GetPredecessors)
+	mux.HandleFunc("GET /api/v1/units/{id}/history", handler.GetUnitHistory)
+	mux.HandleFunc("GET /api/v1/units/{id}/external-ids", handler.GetUnitExternalIDs)
+	mux.HandleFunc("GET /api/v1/external/{system}/{external_id}", handler.GetExternal)
+	mux.HandleFunc("GET /api/v1/changes", handler.GetChanges)
+	mux.HandleFunc("GET /api/v1/sync", handler.Sync)
+	// Code lookups live under their own prefix: /provinces/code/{code} would overlap /provinces/{id}/units
+	mux.HandleFunc("GET /api/v1/codes/provinces/{code}", handler.GetProvinceByCode)
+	mux.HandleFunc("GET /api/v1/codes/units/{code}", handler.GetUnitByCode)
 	mux.HandleFunc("GET /api/v1/search", handler.Search)
+	mux.HandleFunc("GET /api/v1/autocomplete", handler.Autocomplete)
+	mux.HandleFunc("GET /api/v1/reverse", handler.Reverse)
+	mux.HandleFunc("GET /api/v1/locate", handler.Locate)
+	mux.HandleFunc("POST /api/v1/convert", handler.Convert)
+	mux.HandleFunc("POST /api/v1/address/parse", handler.ParseAddress)
+	mux.HandleFunc("POST /api/v1/coords/convert", handler.ConvertCoords)
+	mux.HandleFunc("POST /api/v1/jobs", handler.CreateJob)
+	mux.HandleFunc("GET /api/v1/jobs/{id}", handler.GetJob)
+	mux.HandleFunc("GET /api/v1/jobs/{id}/result", handler.GetJobResult)
+
+	// Vector Tiles ({y} carries the .mvt extension; a wildcard must fill a whole segment)
+	mux.HandleFunc("GET /tiles/{z}/{x}/{y}", handler.GetTile)
 
 	// Middleware Chain
 	return ChainMiddleware(mux,
@@ -0,0 +1,250 @@
+// Package autocomplete serves as-you-type suggestions from an in-memory prefix index.
+// Names are accent-folded into sorted keys, so a lookup is a binary search plus a short
+// scan and never touches the Store. The index is rebuilt periodically when the data changes.
+package autocomplete
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"vn-admin-api/internal/lineage"
+	"vn-admin-api/internal/logger"
+	"vn-admin-api/internal/models"
+	"vn-admin-api/internal/vntext"
+)
+
+const (
+	// DefaultLimit is the number of suggestions returned when none is requested
+	DefaultLimit = 10
+	// MaxLimit caps the limit parameter
+	MaxLimit = 50
+)
+
+// Suggestion kinds
+const (
+	KindProvince = "province"
+	KindUnit     = "unit"
+)
+
+// Suggestion is one autocomplete result
+type Suggestion struct {
+	Kind         string `json:"type"` // "province" or "unit"
+	ID           int    `json:"id"`
+	Name         string `json:"name"`
+	Level        string `json:"level,omitempty"`
+	ProvinceID   int    `json:"province_id"`
+	ProvinceName string `json:"province_name,omitempty"`
+}
+
+// Store is the subset of storage.Store the index is built from
+type Store interface {
+	GetProvinces(ctx context.Context) ([]models.Province, error)
+	GetUnitsByProvince(ctx context.Context, provinceID int) ([]models.AdminUnit, error)
+}
+
+// key points one folded name (or name without its level word) at a suggestion
+type key struct {
+	text string
+	ref  int // index into snapshot.items
+}
+
+// snapshot is an immutable built index; lookups never lock
+type snapshot struct {
+	items   []Suggestion
+	keys    []key // sorted by text
+	version string
+}
+
+// Index is a rebuildable prefix index over provinces and units
+type Index struct {
+	store   Store
+	current atomic.Pointer[snapshot]
+	buildMu sync.Mutex // serializes rebuilds
+}
+
+func NewIndex(store Store) *Index {
+	return &Index{store: store}
+}
+
+// Ready reports whether the index has been built
+func (idx *Index) Ready() bool {
+	return idx.current.Load() != nil
+}
+
+// Rebuild reloads provinces and units and swaps in a new index when the data changed.
+// It reports whether a new index was installed.
+func (idx *Index) Rebuild(ctx context.Context) (bool, error) {
+	idx.buildMu.Lock()
+	defer idx.buildMu.Unlock()
+
+	provinces, err := idx.store.GetProvinces(ctx)
+	if err != nil {
+		return false, err
+	}
+	var units []models.AdminUnit
+	for _, p := range provinces {
+		pu, err := idx.store.GetUnitsByProvince(ctx, p.ID)
+		if err != nil {
+			return false, err
+		}
+		units = append(units, pu...)
+	}
+
+	version := dataVersion(provinces, units)
+	if old := idx.current.Load(); old != nil && old.version == version {
+		return false, nil
+	}
+	idx.current.Store(build(provinces, units, version))
+	return true, nil
+}
+
+// Run rebuilds the index every interval until ctx is cancelled
+func (idx *Index) Run(ctx context.Context, interval time.Duration, log *logger.Logger) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		changed, err := idx.Rebuild(ctx)
+		switch {
+		case err != nil && ctx.Err() == nil:
+			log.Error("Failed to rebuild autocomplete index", "error", err)
+		case changed:
+			log.Info("Autocomplete index rebuilt", "entries", idx.Len())
+		}
+	}
+}
+
+// Len returns the number of indexed provinces and units
+func (idx *Index) Len() int {
+	snap := idx.current.Load()
+	if snap == nil {
+		return 0
+	}
+	return len(snap.items)
+}
+
+// Lookup returns up to limit suggestions whose name starts with query, ignoring case,
+// diacritics and the level word ("ben th" finds "Phường Bến Thành").
+// provinceID > 0 restricts results to units of that province.
+// Exact matches come first, then provinces, then shorter names.
+func (idx *Index) Lookup(query string, provinceID, limit int) []Suggestion {
+	results := make([]Suggestion, 0)
+	snap := idx.current.Load()
+	q := normalize(query)
+	if snap == nil || q == "" {
+		return results
+	}
+	if limit <= 0 {
+		limit = DefaultLimit
+	}
+
+	type hit struct {
+		ref   int
+		exact bool
+	}
+	var hits []hit
+	seen := make(map[int]int) // ref -> position in hits
+	start := sort.Search(len(snap.keys), func(i int) bool { return snap.keys[i].text >= q })
+	for _, k := range snap.keys[start:] {
+		if !strings.HasPrefix(k.text, q) {
+			break
+		}
+		item := snap.items[k.ref]
+		if provinceID > 0 && (item.Kind != KindUnit || item.ProvinceID != provinceID) {
+			continue
+		}
+		exact := k.text == q
+		if pos, ok := seen[k.ref]; ok {
+			hits[pos].exact = hits[pos].exact || exact
+			continue
+		}
+		seen[k.ref] = len(hits)
+		hits = append(hits, hit{ref: k.ref, exact: exact})
+	}
+
+	sort.Slice(hits, func(i, j int) bool {
+		a, b := snap.items[hits[i].ref], snap.items[hits[j].ref]
+		if hits[i].exact != hits[j].exact {
+			return hits[i].exact
+		}
+		if a.Kind != b.Kind {
+			return a.Kind == KindProvince
+		}
+		if len(a.Name) != len(b.Name) {
+			return len(a.Name) < len(b.Name)
+		}
+		return hits[i].ref < hits[j].ref
+	})
+	if len(hits) > limit {
+		hits = hits[:limit]
+	}
+	for _, h := range hits {
+		results = append(results, snap.items[h.ref])
+	}
+	return results
+}
+
+func build(provinces []models.Province, units []models.AdminUnit, version string) *snapshot {
+	snap := &snapshot{version: version}
+	names := make(map[int]string, len(provinces))
+	for _, p := range provinces {
+		names[p.ID] = p.Name
+		level, _ := lineage.SplitLevel(p.Name)
+		snap.add(Suggestion{Kind: KindProvince, ID: p.ID, Name: p.Name, Level: level, ProvinceID: p.ID})
+	}
+	for _, u := range units {
+		snap.add(Suggestion{Kind: KindUnit, ID: u.ID, Name: u.Name, Level: u.Level, ProvinceID: u.ProvinceID, ProvinceName: names[u.ProvinceID]})
+	}
+	sort.Slice(snap.keys, func(i, j int) bool {
+		if snap.keys[i].text != snap.keys[j].text {
+			return snap.keys[i].text < snap.keys[j].text
+		}
+		return snap.keys[i].ref < snap.keys[j].ref
+	})
+	return snap
+}
+
+// add indexes s under its full name and its name without the level word
+func (snap *snapshot) add(s Suggestion) {
+	ref := len(snap.items)
+	snap.items = append(snap.items, s)
+
+	full := normalize(s.Name)
+	snap.keys = append(snap.keys, key{text: full, ref: ref})
+	if _, rest := lineage.SplitLevel(s.Name); rest != s.Name {
+		if short := normalize(rest); short != "" && short != full {
+			snap.keys = append(snap.keys, key{text: short, ref: ref})
+		}
+	}
+}
+
+// normalize folds s and joins its words with single spaces
+func normalize(s string) string {
+	return strings.Join(vntext.Tokens(s), " ")
+}
+
+// dataVersion summarizes the row counts and latest update so unchanged data is not re-indexed
+func dataVersion(provinces []models.Province, units []models.AdminUnit) string {
+	var latest time.Time
+	for _, p := range provinces {
+		if p.UpdatedAt.After(latest) {
+			latest = p.UpdatedAt
+		}
+	}
+	for _, u := range units {
+		if u.UpdatedAt.After(latest) {
+			latest = u.UpdatedAt
+		}
+	}
+	return fmt.Sprintf("%s/%d/%d", latest.UTC().Format(time.RFC3339Nano), len(provinces), len(units))
+}
@@ -0,0 +1,102 @@
+package autocomplete
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"vn-admin-api/internal/models"
+)
+
+type fakeStore struct {
+	provinces []models.Province
+	units     []models.AdminUnit
+}
+
+func (s *fakeStore) GetProvinces(ctx context.Context) ([]models.Province, error) {
+	return s.provinces, nil
+}
+
+func (s *fakeStore) GetUnitsByProvince(ctx context.Context, provinceID int) ([]models.AdminUnit, error) {
+	var units []models.AdminUnit
+	for _, u := range s.units {
+		if u.ProvinceID == provinceID {
+			units = append(units, u)
+		}
+	}
+	return units, nil
+}
+
+func newTestIndex(t *testing.T) (*Index, *fakeStore) {
+	t.Helper()
+	store := &fakeStore{
+		provinces: []models.Province{
+			{ID: 1, Name: "Thành phố Hà Nội"},
+			{ID: 2, Name: "Thành phố Hồ Chí Minh"},
+			{ID: 3, Name: "Tỉnh Hà Tĩnh"},
+		},
+		units: []models.AdminUnit{
+			{ID: 101, ProvinceID: 1, Name: "Phường Ba Đình", Level: "Phường"},
+			{ID: 102, ProvinceID: 1, Name: "Phường Hà Đông", Level: "Phường"},
+			{ID: 201, ProvinceID: 2, Name: "Phường Bến Thành", Level: "Phường"},
+			{ID: 202, ProvinceID: 2, Name: "Xã Bình Hưng", Level: "Xã"},
+		},
+	}
+	idx := NewIndex(store)
+	if _, err := idx.Rebuild(context.Background()); err != nil {
+		t.Fatalf("Rebuild failed: %v", err)
+	}
+	return idx, store
+}
+
+func TestLookup(t *testing.T) {
+	idx, _ := newTestIndex(t)
+
+	tests := []struct {
+		name       string
+		query      string
+		provinceID int
+		want       []int
+	}{
+		{"level word is optional", "ben th", 0, []int{201}},
+		{"full name", "phuong ba dinh", 0, []int{101}},
+		{"diacritics and case ignored", "BÌNH h", 0, []int{202}},
+		{"provinces before units", "ha", 0, []int{3, 1, 102}},
+		{"restricted to a province", "ha", 1, []int{102}},
+		{"exact match first", "ha noi", 0, []int{1}},
+		{"no match", "xyz", 0, []int{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := make([]int, 0)
+			for _, s := range idx.Lookup(tt.query, tt.provinceID, DefaultLimit) {
+				got = append(got, s.ID)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Lookup(%q, %d) = %v, want %v", tt.query, tt.provinceID, got, tt.want)
+			}
+		})
+	}
+
+	if got := idx.Lookup("p", 0, 2); len(got) != 2 {
+		t.Errorf("Expected limit 2 to be respected, got %d results", len(got))
+	}
+}
+
+func TestRebuild(t *testing.T) {
+	idx, store := newTestIndex(t)
+
+	changed, err := idx.Rebuild(context.Background())
+	if err != nil || changed {
+		t.Fatalf("Expected no rebuild for unchanged data, got changed=%v err=%v", changed, err)
+	}
+
+	store.units = append(store.units, models.AdminUnit{ID: 203, ProvinceID: 2, Name: "Phường Sài Gòn", Level: "Phường"})
+	changed, err = idx.Rebuild(context.Background())
+	if err != nil || !changed {
+		t.Fatalf("Expected rebuild after a new unit, got changed=%v err=%v", changed, err)
+	}
+	if got := idx.Lookup("sai gon", 0, DefaultLimit); len(got) != 1 || got[0].ID != 203 || got[0].ProvinceName != "Thành phố Hồ Chí Minh" {
+		t.Errorf("Expected the new unit to be found, got %+v", got)
+	}
+}
@@ -18,6 +18,11 @@ type Cache interface {
 	SetProvinces(ctx context.Context, provinces []models.Province) error
 	GetUnits(ctx context.Context, provinceID int) ([]models.AdminUnit, bool)
 	SetUnits(ctx context.Context, provinceID int, units []models.AdminUnit) error
+	GetUnit(ctx context.Context, key string) (*models.AdminUnit, bool)
+	SetUnit(ctx context.Context, key string, unit *models.AdminUnit) error
+	GetTile(ctx context.Context, key string) ([]byte, bool)
+	SetTile(ctx context.Context, key string, tile []byte) error
+	Flush(ctx context.Context) error // Drops every entry, e.g. after a crawl was published
 	Ping(ctx context.Context) error
 }
 
@@ -96,6 +101,62 @@ func (c *RedisCache) SetUnits(ctx context.Context, provinceID int, units []model
 	return c.client.Set(ctx, key, data, c.ttl).Err()
 }
 
+func (c *RedisCache) GetUnit(ctx context.Context, key string) (*models.AdminUnit, bool) {
+	data, err := c.client.Get(ctx, "unit:"+key).Bytes()
+	if err != nil {
+		return nil, false
+	}
+
+	var unit models.AdminUnit
+	if err := json.Unmarshal(data, &unit); err != nil {
+		return nil, false
+	}
+	return &unit, true
+}
+
+func (c *RedisCache) SetUnit(ctx context.Context, key string, unit *models.AdminUnit) error {
+	data, err := json.Marshal(unit)
+	if err != nil {
+		return err
+	}
+	return c.client.Set(ctx, "unit:"+key, data, c.ttl).Err()
+}
+
+func (c *RedisCache) GetTile(ctx context.Context, key string) ([]byte, bool) {
+	data, err := c.client.Get(ctx, "tile:"+key).Bytes()
+	if err != nil {
+		return nil, false
+	}
+	return data, true
+}
+
+func (c *RedisCache) SetTile(ctx context.Context, key string, tile []byte) error {
+	return c.client.Set(ctx, "tile:"+key, tile, c.ttl).Err()
+}
+
+// Flush deletes the keys this cache writes, leaving the rest of the Redis database alone
+func (c *RedisCache) Flush(ctx context.Context) error {
+	if err := c.client.Del(ctx, "provinces").Err(); err != nil {
+		return err
+	}
+	for _, pattern := range []string{"units:*", "unit:*", "tile:*"} {
+		iter := c.client.Scan(ctx, 0, pattern, 1000).Iterator()
+		var keys []string
+		for iter.Next(ctx) {
+			keys = append(keys, iter.Val())
+		}
+		if err := iter.Err(); err != nil {
+			return err
+		}
+		if len(keys) > 0 {
+			if err := c.client.Del(ctx, keys...).Err(); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
 func (c *RedisCache) Close() error {
 	return c.client.Close()
 }
@@ -114,9 +175,29 @@ type MemoryCache struct {
 	unitsMu sync.RWMutex
 	units   map[int]cachedUnits
 
+	unitMu sync.RWMutex
+	unit   map[string]cachedUnit
+
+	tilesMu sync.RWMutex
+	tiles   map[string]cachedTile
+
 	ttl time.Duration
 }
 
+type cachedUnit struct {
+	data   *models.AdminUnit
+	expiry time.Time
+}
+
+// maxMemoryEntries bounds the keyed unit and tile caches; tiles are keyed by data version,
+// so old entries pile up
+const maxMemoryEntries = 10000
+
+type cachedTile struct {
+	data   []byte
+	expiry time.Time
+}
+
 type cachedUnits struct {
 	data   []models.AdminUnit
 	expiry time.Time
@@ -126,6 +207,8 @@ func NewMemoryCache(ttl time.Duration) *MemoryCache {
 	return &MemoryCache{
 		ttl:   ttl,
 		units: make(map[int]cachedUnits),
+		unit:  make(map[string]cachedUnit),
+		tiles: make(map[string]cachedTile),
 	}
 }
 
@@ -175,4 +258,81 @@ func (c *MemoryCache) SetUnits(ctx context.Context, provinceID int, units []mode
 	return nil
 }
 
+func (c *MemoryCache) GetUnit(ctx context.Context, key string) (*models.AdminUnit, bool) {
+	c.unitMu.RLock()
+	defer c.unitMu.RUnlock()
+
+	if cached, ok := c.unit[key]; ok && time.Now().Before(cached.expiry) {
+		return cached.data, true
+	}
+	return nil, false
+}
+
+func (c *MemoryCache) SetUnit(ctx context.Context, key string, unit *models.AdminUnit) error {
+	c.unitMu.Lock()
+	defer c.unitMu.Unlock()
+
+	now := time.Now()
+	makeRoom(c.unit, func(cached cachedUnit) bool { return now.After(cached.expiry) })
+	c.unit[key] = cachedUnit{data: unit, expiry: now.Add(c.ttl)}
+	return nil
+}
+
+func (c *MemoryCache) GetTile(ctx context.Context, key string) ([]byte, bool) {
+	c.tilesMu.RLock()
+	defer c.tilesMu.RUnlock()
+
+	if cached, ok := c.tiles[key]; ok && time.Now().Before(cached.expiry) {
+		return cached.data, true
+	}
+	return nil, false
+}
+
+func (c *MemoryCache) SetTile(ctx context.Context, key string, tile []byte) error {
+	c.tilesMu.Lock()
+	defer c.tilesMu.Unlock()
+
+	now := time.Now()
+	makeRoom(c.tiles, func(cached cachedTile) bool { return now.After(cached.expiry) })
+	c.tiles[key] = cachedTile{data: tile, expiry: now.Add(c.ttl)}
+	return nil
+}
+
+func (c *MemoryCache) Flush(ctx context.Context) error {
+	c.mu.Lock()
+	c.provinces, c.provinceExp = nil, time.Time{}
+	c.mu.Unlock()
+
+	c.unitsMu.Lock()
+	c.units = make(map[int]cachedUnits)
+	c.unitsMu.Unlock()
+
+	c.unitMu.Lock()
+	c.unit = make(map[string]cachedUnit)
+	c.unitMu.Unlock()
+
+	c.tilesMu.Lock()
+	c.tiles = make(map[string]cachedTile)
+	c.tilesMu.Unlock()
+	return nil
+}
+
+// makeRoom drops expired entries once m is full, then arbitrary ones if it still is
+func makeRoom[V any](m map[string]V, expired func(V) bool) {
+	if len(m) < maxMemoryEntries {
+		return
+	}
+	for k, v := range m {
+		if expired(v) {
+			delete(m, k)
+		}
+	}
+	for k := range m {
+		if len(m) < maxMemoryEntries {
+			break
+		}
+		delete(m, k)
+	}
+}
+
 var _ Cache = (*MemoryCache)(nil)
@@ -0,0 +1,48 @@
+package cache
+
+import (
+	"context"
+	"time"
+
+	"vn-admin-api/internal/logger"
+)
+
+// VersionSource reports the current sync version of the stored data, which changes with every
+// write; storage.Store is one
+type VersionSource interface {
+	GetSyncVersion(ctx context.Context) (int64, error)
+}
+
+// Watch flushes c whenever the sync version of src changes, such as after a crawl published by
+// another process, checking every interval until ctx is cancelled
+func Watch(ctx context.Context, c Cache, src VersionSource, interval time.Duration, log *logger.Logger) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	seen, err := src.GetSyncVersion(ctx)
+	known := err == nil
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		version, err := src.GetSyncVersion(ctx)
+		if err != nil {
+			if ctx.Err() == nil {
+				log.Error("Failed to check for data changes", "error", err)
+			}
+			continue
+		}
+		if known && version == seen {
+			continue
+		}
+		// Without a baseline, data may have changed since the cache was filled
+		if err := c.Flush(ctx); err != nil {
+			log.Error("Failed to flush cache", "error", err)
+			continue // Retry on the next tick
+		}
+		seen, known = version, true
+		log.Info("Cache flushed after data changes", "sync_version", version)
+	}
+}
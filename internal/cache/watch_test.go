@@ -0,0 +1,41 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"vn-admin-api/internal/logger"
+	"vn-admin-api/internal/models"
+)
+
+// flakySource fails its first check, then reports the same version forever
+type flakySource struct {
+	calls atomic.Int32
+}
+
+func (s *flakySource) GetSyncVersion(ctx context.Context) (int64, error) {
+	if s.calls.Add(1) == 1 {
+		return 0, errors.New("connection refused")
+	}
+	return 5, nil
+}
+
+func TestWatch_FlushesWithoutBaseline(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	c := NewMemoryCache(time.Hour)
+	c.SetProvinces(ctx, []models.Province{{ID: 1, Name: "Thành phố Hà Nội"}})
+	go Watch(ctx, c, &flakySource{}, time.Millisecond, logger.New("", false))
+
+	// The data may have changed while the version was unknown, so the first read must flush
+	for deadline := time.Now().Add(time.Second); time.Now().Before(deadline); time.Sleep(time.Millisecond) {
+		if _, ok := c.GetProvinces(ctx); !ok {
+			return
+		}
+	}
+	t.Fatal("Expected the cache to be flushed once the sync version could be read")
+}
@@ -0,0 +1,177 @@
+// Package changelog compares a crawl with the stored data and describes what changed: provinces
+// and units added, removed or renamed, units moved to another province, and changed coordinates.
+package changelog
+
+import (
+	"fmt"
+	"math"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"vn-admin-api/internal/models"
+)
+
+// coordEpsilon ignores float noise in upstream coordinates (about 1 cm)
+const coordEpsilon = 1e-7
+
+// Snapshot is every province and unit of one side of a diff
+type Snapshot struct {
+	Provinces []models.Province
+	Units     []models.AdminUnit
+}
+
+// NewRunID returns the ID of a crawl run started at t; IDs sort in start order
+func NewRunID(t time.Time) string {
+	return t.UTC().Format("20060102T150405Z")
+}
+
+// Diff lists the changes from before to after, provinces first, each ordered by ID. A unit
+// missing from after is only reported as removed when complete[its province] is true, i.e. the
+// crawl fetched that province's whole unit list; otherwise it may simply not have been fetched.
+func Diff(before, after Snapshot, complete map[int]bool) []models.Change {
+	var changes []models.Change
+
+	oldProvinces := make(map[int]models.Province, len(before.Provinces))
+	for _, p := range before.Provinces {
+		oldProvinces[p.ID] = p
+	}
+	seenProvinces := make(map[int]bool, len(after.Provinces))
+	for _, p := range sortProvinces(after.Provinces) {
+		seenProvinces[p.ID] = true
+		old, ok := oldProvinces[p.ID]
+		switch {
+		case !ok:
+			changes = append(changes, models.Change{Kind: models.ChangeProvinceAdded, RefID: p.ID, ProvinceID: p.ID, Name: p.Name})
+		case old.Name != p.Name:
+			changes = append(changes, models.Change{Kind: models.ChangeProvinceRenamed, RefID: p.ID, ProvinceID: p.ID,
+				Name: p.Name, OldValue: old.Name, NewValue: p.Name})
+		}
+	}
+	for _, p := range sortProvinces(before.Provinces) {
+		if !seenProvinces[p.ID] {
+			changes = append(changes, models.Change{Kind: models.ChangeProvinceRemoved, RefID: p.ID, ProvinceID: p.ID, Name: p.Name})
+		}
+	}
+
+	oldUnits := make(map[int]models.AdminUnit, len(before.Units))
+	for _, u := range before.Units {
+		oldUnits[u.ID] = u
+	}
+	seenUnits := make(map[int]bool, len(after.Units))
+	for _, u := range sortUnits(after.Units) {
+		seenUnits[u.ID] = true
+		old, ok := oldUnits[u.ID]
+		if !ok {
+			changes = append(changes, models.Change{Kind: models.ChangeUnitAdded, RefID: u.ID, ProvinceID: u.ProvinceID, Name: u.Name})
+			continue
+		}
+		change := models.Change{RefID: u.ID, ProvinceID: u.ProvinceID, Name: u.Name}
+		if old.Name != u.Name {
+			change.Kind, change.OldValue, change.NewValue = models.ChangeUnitRenamed, old.Name, u.Name
+			changes = append(changes, change)
+		}
+		if old.ProvinceID != u.ProvinceID {
+			change.Kind, change.OldValue, change.NewValue = models.ChangeUnitMoved, strconv.Itoa(old.ProvinceID), strconv.Itoa(u.ProvinceID)
+			changes = append(changes, change)
+		}
+		if math.Abs(old.Lat-u.Lat) > coordEpsilon || math.Abs(old.Long-u.Long) > coordEpsilon {
+			change.Kind, change.OldValue, change.NewValue = models.ChangeUnitCoordinates, coords(old), coords(u)
+			changes = append(changes, change)
+		}
+	}
+	for _, u := range sortUnits(before.Units) {
+		if !seenUnits[u.ID] && complete[u.ProvinceID] {
+			changes = append(changes, models.Change{Kind: models.ChangeUnitRemoved, RefID: u.ID, ProvinceID: u.ProvinceID, Name: u.Name})
+		}
+	}
+	return changes
+}
+
+// Removals returns the IDs of the provinces and units changes report as removed
+func Removals(changes []models.Change) (provinceIDs, unitIDs []int) {
+	for _, c := range changes {
+		switch c.Kind {
+		case models.ChangeProvinceRemoved:
+			provinceIDs = append(provinceIDs, c.RefID)
+		case models.ChangeUnitRemoved:
+			unitIDs = append(unitIDs, c.RefID)
+		}
+	}
+	return provinceIDs, unitIDs
+}
+
+// WithoutRemovals drops the removals from changes, in place
+func WithoutRemovals(changes []models.Change) []models.Change {
+	return slices.DeleteFunc(changes, func(c models.Change) bool {
+		return c.Kind == models.ChangeProvinceRemoved || c.Kind == models.ChangeUnitRemoved
+	})
+}
+
+// Describe renders one change as a line of text
+func Describe(c models.Change) string {
+	subject := fmt.Sprintf("%s %d %q", strings.SplitN(c.Kind, "_", 2)[0], c.RefID, c.Name)
+	switch c.Kind {
+	case models.ChangeProvinceAdded, models.ChangeUnitAdded:
+		return "+ " + subject
+	case models.ChangeProvinceRemoved, models.ChangeUnitRemoved:
+		return "- " + subject
+	case models.ChangeProvinceRenamed, models.ChangeUnitRenamed:
+		return fmt.Sprintf("~ %s renamed from %q", subject, c.OldValue)
+	case models.ChangeUnitMoved:
+		return fmt.Sprintf("~ %s moved from province %s to %s", subject, c.OldValue, c.NewValue)
+	case models.ChangeUnitCoordinates:
+		return fmt.Sprintf("~ %s coordinates %s -> %s", subject, c.OldValue, c.NewValue)
+	default:
+		return fmt.Sprintf("? %s %s: %s -> %s", subject, c.Kind, c.OldValue, c.NewValue)
+	}
+}
+
+// Summarize renders a human-readable report: the number of changes per kind, then one line per
+// change, at most maxLines of them
+func Summarize(runID string, changes []models.Change, maxLines int) string {
+	var b strings.Builder
+	if len(changes) == 0 {
+		fmt.Fprintf(&b, "Crawl run %s: no changes\n", runID)
+		return b.String()
+	}
+	fmt.Fprintf(&b, "Crawl run %s: %d changes\n", runID, len(changes))
+
+	counts := make(map[string]int)
+	var kinds []string
+	for _, c := range changes {
+		if counts[c.Kind] == 0 {
+			kinds = append(kinds, c.Kind)
+		}
+		counts[c.Kind]++
+	}
+	for _, k := range kinds {
+		fmt.Fprintf(&b, "  %-17s %d\n", k, counts[k])
+	}
+	for i, c := range changes {
+		if i == maxLines {
+			fmt.Fprintf(&b, "  ... and %d more\n", len(changes)-maxLines)
+			break
+		}
+		fmt.Fprintf(&b, "  %s\n", Describe(c))
+	}
+	return b.String()
+}
+
+func coords(u models.AdminUnit) string {
+	return strconv.FormatFloat(u.Lat, 'f', -1, 64) + "," + strconv.FormatFloat(u.Long, 'f', -1, 64)
+}
+
+func sortProvinces(provinces []models.Province) []models.Province {
+	sorted := append([]models.Province(nil), provinces...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].ID < sorted[j].ID })
+	return sorted
+}
+
+func sortUnits(units []models.AdminUnit) []models.AdminUnit {
+	sorted := append([]models.AdminUnit(nil), units...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].ID < sorted[j].ID })
+	return sorted
+}
@@ -0,0 +1,89 @@
+package changelog
+
+import (
+	"reflect"
+	"slices"
+	"strings"
+	"testing"
+
+	"vn-admin-api/internal/models"
+)
+
+func TestDiff(t *testing.T) {
+	before := Snapshot{
+		Provinces: []models.Province{{ID: 1, Name: "Thành phố Hà Nội"}, {ID: 2, Name: "Thành phố Hồ Chí Minh"}, {ID: 3, Name: "Tỉnh Hà Tây"}},
+		Units: []models.AdminUnit{
+			{ID: 101, ProvinceID: 1, Name: "Phường Ba Đình", Lat: 21.034, Long: 105.84},
+			{ID: 102, ProvinceID: 1, Name: "Xã Sơn Tây", Lat: 21.14, Long: 105.5},
+			{ID: 103, ProvinceID: 1, Name: "Xã Đường Lâm"},
+			{ID: 201, ProvinceID: 2, Name: "Phường Bến Thành"},
+			{ID: 202, ProvinceID: 2, Name: "Phường Cầu Kho"}, // province 2 not fetched: not removed
+		},
+	}
+	after := Snapshot{
+		Provinces: []models.Province{{ID: 2, Name: "Thành phố Hồ Chí Minh"}, {ID: 1, Name: "Thủ đô Hà Nội"}, {ID: 4, Name: "Tỉnh Mới"}},
+		Units: []models.AdminUnit{
+			{ID: 102, ProvinceID: 4, Name: "Phường Sơn Tây", Lat: 21.15, Long: 105.5},
+			{ID: 101, ProvinceID: 1, Name: "Phường Ba Đình", Lat: 21.03400000001, Long: 105.84},
+			{ID: 104, ProvinceID: 1, Name: "Phường Mới"},
+		},
+	}
+
+	got := Diff(before, after, map[int]bool{1: true, 4: true})
+	want := []models.Change{
+		{Kind: models.ChangeProvinceRenamed, RefID: 1, ProvinceID: 1, Name: "Thủ đô Hà Nội", OldValue: "Thành phố Hà Nội", NewValue: "Thủ đô Hà Nội"},
+		{Kind: models.ChangeProvinceAdded, RefID: 4, ProvinceID: 4, Name: "Tỉnh Mới"},
+		{Kind: models.ChangeProvinceRemoved, RefID: 3, ProvinceID: 3, Name: "Tỉnh Hà Tây"},
+		{Kind: models.ChangeUnitRenamed, RefID: 102, ProvinceID: 4, Name: "Phường Sơn Tây", OldValue: "Xã Sơn Tây", NewValue: "Phường Sơn Tây"},
+		{Kind: models.ChangeUnitMoved, RefID: 102, ProvinceID: 4, Name: "Phường Sơn Tây", OldValue: "1", NewValue: "4"},
+		{Kind: models.ChangeUnitCoordinates, RefID: 102, ProvinceID: 4, Name: "Phường Sơn Tây", OldValue: "21.14,105.5", NewValue: "21.15,105.5"},
+		{Kind: models.ChangeUnitAdded, RefID: 104, ProvinceID: 1, Name: "Phường Mới"},
+		{Kind: models.ChangeUnitRemoved, RefID: 103, ProvinceID: 1, Name: "Xã Đường Lâm"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Diff:\ngot  %+v\nwant %+v", got, want)
+	}
+
+	if changes := Diff(before, before, map[int]bool{1: true, 2: true}); len(changes) != 0 {
+		t.Errorf("Expected no changes against itself, got %+v", changes)
+	}
+}
+
+func TestRemovals(t *testing.T) {
+	changes := []models.Change{
+		{Kind: models.ChangeProvinceRemoved, RefID: 3},
+		{Kind: models.ChangeUnitRenamed, RefID: 101},
+		{Kind: models.ChangeUnitRemoved, RefID: 103},
+		{Kind: models.ChangeUnitRemoved, RefID: 104},
+	}
+	provinces, units := Removals(changes)
+	if !slices.Equal(provinces, []int{3}) || !slices.Equal(units, []int{103, 104}) {
+		t.Errorf("Removals = %v, %v", provinces, units)
+	}
+	if kept := WithoutRemovals(changes); len(kept) != 1 || kept[0].RefID != 101 {
+		t.Errorf("WithoutRemovals = %+v", kept)
+	}
+}
+
+func TestSummarize(t *testing.T) {
+	changes := []models.Change{
+		{Kind: models.ChangeUnitRenamed, RefID: 102, Name: "Phường Sơn Tây", OldValue: "Xã Sơn Tây", NewValue: "Phường Sơn Tây"},
+		{Kind: models.ChangeUnitAdded, RefID: 104, Name: "Phường Mới"},
+		{Kind: models.ChangeUnitAdded, RefID: 105, Name: "Phường Khác"},
+	}
+	got := Summarize("20250701T000000Z", changes, 2)
+	for _, want := range []string{
+		"Crawl run 20250701T000000Z: 3 changes\n",
+		"unit_added        2\n",
+		`~ unit 102 "Phường Sơn Tây" renamed from "Xã Sơn Tây"`,
+		`+ unit 104 "Phường Mới"`,
+		"... and 1 more",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("Summary lacks %q:\n%s", want, got)
+		}
+	}
+	if got := Summarize("x", nil, 10); got != "Crawl run x: no changes\n" {
+		t.Errorf("Unexpected empty summary %q", got)
+	}
+}
@@ -3,47 +3,81 @@ package config
 import (
 	"fmt"
 	"os"
+	"strconv"
 	"time"
 
 	"github.com/joho/godotenv"
 )
 
 type Config struct {
-	DBHost     string
-	DBPort     string
-	DBUser     string
-	DBPassword string
-	DBName     string
-	DBSSLMode  string
-	APICookie  string
-	ServerPort string
-	RedisURL   string
-	CacheTTL   time.Duration
+	DBHost        string
+	DBPort        string
+	DBUser        string
+	DBPassword    string
+	DBName        string
+	DBSSLMode     string
+	APICookie     string
+	ServerPort    string
+	RedisURL      string
+	CacheTTL      time.Duration
+	StorageDriver string        // "postgres" (default) or "memory"
+	DataFile      string        // JSON snapshot used by the memory driver
+	JobWorkers    int           // concurrent bulk conversion jobs per instance
+	IndexRefresh  time.Duration // how often the in-memory indexes check for data changes
+
+	RetireMaxFraction float64 // crawler: most of the stored units one crawl may retire (0..1)
+
+	Warnings []string // deprecated settings in use, for the caller to log
 }
 
 // Load reads .env file and environment variables
 func Load() (*Config, error) {
 	_ = godotenv.Load()
+	var warnings []string
 
 	ttl, err := time.ParseDuration(getEnvDefault("CACHE_TTL", "5m"))
 	if err != nil {
 		ttl = 5 * time.Minute
 	}
 
+	workers, err := strconv.Atoi(getEnvDefault("JOB_WORKERS", "2"))
+	if err != nil || workers < 1 {
+		workers = 2
+	}
+
+	refresh, err := time.ParseDuration(getEnvRenamed("INDEX_REFRESH", "AUTOCOMPLETE_REFRESH", "5m", &warnings))
+	if err != nil || refresh <= 0 {
+		refresh = 5 * time.Minute
+	}
+
+	retireMax, err := strconv.ParseFloat(getEnvDefault("RETIRE_MAX_FRACTION", "0.05"), 64)
+	if err != nil || retireMax < 0 || retireMax > 1 {
+		retireMax = 0.05
+	}
+
 	cfg := &Config{
-		DBHost:     os.Getenv("DB_HOST"),
-		DBPort:     getEnvDefault("DB_PORT", "5432"),
-		DBUser:     os.Getenv("DB_USER"),
-		DBPassword: os.Getenv("DB_PASSWORD"),
-		DBName:     os.Getenv("DB_NAME"),
-		DBSSLMode:  getEnvDefault("DB_SSLMODE", "disable"),
-		APICookie:  os.Getenv("API_COOKIE"),
-		ServerPort: getEnvDefault("SERVER_PORT", "8080"),
-		RedisURL:   os.Getenv("REDIS_URL"),
-		CacheTTL:   ttl,
-	}
-
-	if cfg.DBHost == "" || cfg.DBUser == "" || cfg.DBName == "" {
+		DBHost:        os.Getenv("DB_HOST"),
+		DBPort:        getEnvDefault("DB_PORT", "5432"),
+		DBUser:        os.Getenv("DB_USER"),
+		DBPassword:    os.Getenv("DB_PASSWORD"),
+		DBName:        os.Getenv("DB_NAME"),
+		DBSSLMode:     getEnvDefault("DB_SSLMODE", "disable"),
+		APICookie:     os.Getenv("API_COOKIE"),
+		ServerPort:    getEnvDefault("SERVER_PORT", "8080"),
+		RedisURL:      os.Getenv("REDIS_URL"),
+		CacheTTL:      ttl,
+		StorageDriver: getEnvDefault("STORAGE_DRIVER", "postgres"),
+		DataFile:      getEnvDefault("DATA_FILE", "data/admin_units.json"),
+		JobWorkers:    workers,
+		IndexRefresh:  refresh,
+
+		RetireMaxFraction: retireMax,
+
+		Warnings: warnings,
+	}
+
+	// The in-memory driver runs without Postgres
+	if cfg.StorageDriver == "postgres" && (cfg.DBHost == "" || cfg.DBUser == "" || cfg.DBName == "") {
 		return nil, fmt.Errorf("missing required DB environment variables")
 	}
 
@@ -56,3 +90,15 @@ func getEnvDefault(key, defaultValue string) string {
 	}
 	return defaultValue
 }
+
+// getEnvRenamed reads a renamed variable, falling back to its deprecated old name with a warning
+func getEnvRenamed(key, oldKey, defaultValue string, warnings *[]string) string {
+	if value := os.Getenv(key); value != "" {
+		return value
+	}
+	if value := os.Getenv(oldKey); value != "" {
+		*warnings = append(*warnings, fmt.Sprintf("%s is deprecated, use %s", oldKey, key))
+		return value
+	}
+	return defaultValue
+}
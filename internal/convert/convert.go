@@ -0,0 +1,278 @@
+// Package convert maps pre-2025 addresses (old ward/district/province) to the
+// administrative units that replaced them, using the parsed unit_predecessors lineage.
+package convert
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"vn-admin-api/internal/address"
+	"vn-admin-api/internal/lineage"
+	"vn-admin-api/internal/models"
+	"vn-admin-api/internal/search"
+	"vn-admin-api/internal/storage"
+	"vn-admin-api/internal/vntext"
+)
+
+// Confidence levels reported in Result.Confidence
+const (
+	ConfidenceHigh   = "high"   // One new unit, old unit merged whole, location confirmed
+	ConfidenceMedium = "medium" // One plausible new unit, or a confirmed split
+	ConfidenceLow    = "low"    // Several candidates that cannot be told apart
+	ConfidenceNone   = "none"   // Nothing matched
+)
+
+// provinceTTL bounds how long the province list is reused between conversions
+const provinceTTL = 5 * time.Minute
+
+// ErrMissingWard is returned when neither a ward nor a free-text address is given
+var ErrMissingWard = errors.New("ward or address is required")
+
+// Store is the subset of storage.Store the converter reads from
+type Store interface {
+	GetProvinces(ctx context.Context) ([]models.Province, error)
+	GetAdminUnit(ctx context.Context, id int) (*models.AdminUnit, error)
+	FindPredecessorsByName(ctx context.Context, name string) ([]models.Predecessor, error)
+	SearchAdminUnits(ctx context.Context, params models.SearchParams) ([]models.SearchResult, error)
+}
+
+// Input is an old address, either as components or as free text
+type Input struct {
+	Ward     string `json:"ward,omitempty"`
+	District string `json:"district,omitempty"`
+	Province string `json:"province,omitempty"`
+	Address  string `json:"address,omitempty"`
+}
+
+// Match is one new unit the old address now belongs to
+type Match struct {
+	Unit        models.AdminUnit    `json:"unit"`
+	Province    *models.Province    `json:"province,omitempty"`
+	Partial     bool                `json:"partial"`               // Only part of the old unit went here
+	Predecessor *models.Predecessor `json:"predecessor,omitempty"` // nil when the input already names a current unit
+}
+
+// Result is the outcome of one conversion
+type Result struct {
+	Input      Input   `json:"input"` // Components actually used (parsed from Address when given)
+	Matches    []Match `json:"matches"`
+	Confidence string  `json:"confidence"`
+	Split      bool    `json:"split"` // The old unit was divided among several new units
+}
+
+// Converter resolves old addresses against a Store
+type Converter struct {
+	store Store
+
+	mu        sync.Mutex
+	provinces map[int]models.Province
+	loadedAt  time.Time
+}
+
+func New(store Store) *Converter {
+	return &Converter{store: store}
+}
+
+type candidate struct {
+	pred      models.Predecessor
+	unit      models.AdminUnit
+	confirmed bool // district or province matched the input
+}
+
+// Convert finds the new unit(s) for an old address
+func (c *Converter) Convert(ctx context.Context, in Input) (*Result, error) {
+	if in.Ward == "" && in.Address != "" {
+		in = splitAddress(in.Address)
+	}
+	if strings.TrimSpace(in.Ward) == "" {
+		return nil, ErrMissingWard
+	}
+
+	provinces, err := c.provinceIndex(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	wardLevel, wardName := lineage.SplitLevel(in.Ward)
+	preds, err := c.store.FindPredecessorsByName(ctx, wardName)
+	if err != nil {
+		return nil, err
+	}
+	if wardLevel != "" {
+		// Prefer the stated level, but tolerate a wrong one ("xã" typed for a "phường")
+		var sameLevel []models.Predecessor
+		for _, p := range preds {
+			if p.Level == wardLevel {
+				sameLevel = append(sameLevel, p)
+			}
+		}
+		if len(sameLevel) > 0 {
+			preds = sameLevel
+		}
+	}
+
+	var candidates []candidate
+	for _, p := range preds {
+		unit, err := c.store.GetAdminUnit(ctx, p.UnitID)
+		if errors.Is(err, storage.ErrNotFound) {
+			continue // lineage of a unit that no longer exists
+		}
+		if err != nil {
+			return nil, err
+		}
+		cand := candidate{pred: p, unit: *unit}
+
+		if in.District != "" && p.District != "" {
+			if !sameName(p.District, in.District) {
+				continue
+			}
+			cand.confirmed = true
+		}
+		if in.Province != "" {
+			switch {
+			case p.Province != "":
+				if !sameName(p.Province, in.Province) {
+					continue
+				}
+				cand.confirmed = true
+			case sameName(provinces[unit.ProvinceID].Name, in.Province):
+				cand.confirmed = true
+			}
+		}
+		candidates = append(candidates, cand)
+	}
+
+	// Once some candidates are confirmed by location, the rest are noise
+	var confirmed []candidate
+	for _, cand := range candidates {
+		if cand.confirmed {
+			confirmed = append(confirmed, cand)
+		}
+	}
+	if len(confirmed) > 0 {
+		candidates = confirmed
+	}
+
+	if len(candidates) == 0 {
+		return c.matchCurrentUnits(ctx, in, wardName, provinces)
+	}
+
+	sort.Slice(candidates, func(i, j int) bool {
+		if candidates[i].pred.Partial != candidates[j].pred.Partial {
+			return !candidates[i].pred.Partial
+		}
+		return candidates[i].unit.ID < candidates[j].unit.ID
+	})
+
+	res := &Result{Input: in, Matches: make([]Match, 0, len(candidates))}
+	seen := make(map[int]bool)
+	allPartial := true
+	for _, cand := range candidates {
+		if seen[cand.unit.ID] {
+			continue
+		}
+		seen[cand.unit.ID] = true
+		pred := cand.pred
+		res.Matches = append(res.Matches, Match{
+			Unit:        cand.unit,
+			Province:    provinceRef(provinces, cand.unit.ProvinceID),
+			Partial:     pred.Partial,
+			Predecessor: &pred,
+		})
+		allPartial = allPartial && pred.Partial
+	}
+
+	switch {
+	case len(res.Matches) == 1 && !res.Matches[0].Partial && len(confirmed) > 0:
+		res.Confidence = ConfidenceHigh
+	case len(res.Matches) == 1:
+		res.Confidence = ConfidenceMedium
+	case allPartial && len(confirmed) > 0:
+		// One old unit divided among several new ones: the street decides which
+		res.Split = true
+		res.Confidence = ConfidenceMedium
+	case allPartial:
+		res.Split = true
+		res.Confidence = ConfidenceLow
+	default:
+		res.Confidence = ConfidenceLow
+	}
+	return res, nil
+}
+
+// matchCurrentUnits handles input that already names a post-merger unit
+func (c *Converter) matchCurrentUnits(ctx context.Context, in Input, wardName string, provinces map[int]models.Province) (*Result, error) {
+	res := &Result{Input: in, Matches: make([]Match, 0), Confidence: ConfidenceNone}
+
+	results, err := c.store.SearchAdminUnits(ctx, models.SearchParams{Query: wardName, Limit: search.DefaultLimit})
+	if err != nil {
+		return nil, err
+	}
+	for _, r := range results {
+		if !sameName(r.Name, wardName) {
+			continue
+		}
+		if in.Province != "" && !sameName(provinces[r.ProvinceID].Name, in.Province) {
+			continue
+		}
+		res.Matches = append(res.Matches, Match{Unit: r.AdminUnit, Province: provinceRef(provinces, r.ProvinceID)})
+	}
+
+	switch len(res.Matches) {
+	case 0:
+	case 1:
+		res.Confidence = ConfidenceMedium
+	default:
+		res.Confidence = ConfidenceLow
+	}
+	return res, nil
+}
+
+// provinceIndex returns provinces by ID, reloading them at most every provinceTTL
+func (c *Converter) provinceIndex(ctx context.Context) (map[int]models.Province, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.provinces != nil && time.Since(c.loadedAt) < provinceTTL {
+		return c.provinces, nil
+	}
+	list, err := c.store.GetProvinces(ctx)
+	if err != nil {
+		return nil, err
+	}
+	c.provinces = make(map[int]models.Province, len(list))
+	for _, p := range list {
+		c.provinces[p.ID] = p
+	}
+	c.loadedAt = time.Now()
+	return c.provinces, nil
+}
+
+func provinceRef(provinces map[int]models.Province, id int) *models.Province {
+	if p, ok := provinces[id]; ok {
+		return &p
+	}
+	return nil
+}
+
+// sameName compares two unit names ignoring level words, case and diacritics
+func sameName(a, b string) bool {
+	_, a = lineage.SplitLevel(a)
+	_, b = lineage.SplitLevel(b)
+	return a != "" && vntext.Fold(a) == vntext.Fold(b)
+}
+
+// splitAddress reads the components of a free-text address
+func splitAddress(raw string) Input {
+	addr := address.Parse(raw)
+	return Input{
+		Ward:     addr.Ward.String(),
+		District: addr.District.String(),
+		Province: addr.Province.String(),
+		Address:  raw,
+	}
+}
@@ -0,0 +1,57 @@
+package convert
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"vn-admin-api/internal/models"
+	"vn-admin-api/internal/storage"
+)
+
+type fakeStore struct {
+	units   map[int]models.AdminUnit
+	unitErr error // Returned by GetAdminUnit instead of a unit
+}
+
+func (s *fakeStore) GetProvinces(ctx context.Context) ([]models.Province, error) {
+	return []models.Province{{ID: 1, Name: "Thành phố Hà Nội"}}, nil
+}
+
+func (s *fakeStore) GetAdminUnit(ctx context.Context, id int) (*models.AdminUnit, error) {
+	if s.unitErr != nil {
+		return nil, s.unitErr
+	}
+	u, ok := s.units[id]
+	if !ok {
+		return nil, storage.ErrNotFound
+	}
+	return &u, nil
+}
+
+func (s *fakeStore) FindPredecessorsByName(ctx context.Context, name string) ([]models.Predecessor, error) {
+	return []models.Predecessor{
+		{UnitID: 101, Name: "Quán Thánh", Level: "Phường"},
+		{UnitID: 999, Name: "Quán Thánh", Level: "Phường"}, // Unit no longer exists
+	}, nil
+}
+
+func (s *fakeStore) SearchAdminUnits(ctx context.Context, params models.SearchParams) ([]models.SearchResult, error) {
+	return nil, nil
+}
+
+func TestConvert_StoreErrors(t *testing.T) {
+	ctx := context.Background()
+	store := &fakeStore{units: map[int]models.AdminUnit{101: {ID: 101, ProvinceID: 1, Name: "Phường Ba Đình"}}}
+
+	res, err := New(store).Convert(ctx, Input{Ward: "Phường Quán Thánh"})
+	if err != nil || len(res.Matches) != 1 || res.Matches[0].Unit.ID != 101 {
+		t.Fatalf("Expected the lineage of a missing unit to be skipped, got %+v, %v", res, err)
+	}
+
+	outage := errors.New("connection refused")
+	store.unitErr = outage
+	if res, err := New(store).Convert(ctx, Input{Ward: "Phường Quán Thánh"}); !errors.Is(err, outage) {
+		t.Errorf("Expected the store error, got %+v, %v", res, err)
+	}
+}
@@ -3,17 +3,23 @@ package crawler
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
 	"net/url"
+	"slices"
 	"strings"
 	"time"
 
+	"vn-admin-api/internal/cache"
+	"vn-admin-api/internal/changelog"
 	"vn-admin-api/internal/config"
-	"vn-admin-api/internal/database"
+	"vn-admin-api/internal/levels"
+	"vn-admin-api/internal/lineage"
 	"vn-admin-api/internal/logger"
 	"vn-admin-api/internal/models"
+	"vn-admin-api/internal/storage"
 )
 
 const (
@@ -24,66 +30,265 @@ const (
 )
 
 type Crawler struct {
-	repo   *database.Repository
-	log    *logger.Logger
-	cookie string
-	client *http.Client
+	repo      storage.Store
+	log       *logger.Logger
+	cookie    string
+	client    *http.Client
+	retireMax float64     // Most of the stored units one run may retire (0..1)
+	cache     cache.Cache // Flushed once a run is published; nil when there is none
 }
 
-func New(repo *database.Repository, log *logger.Logger, cfg *config.Config) *Crawler {
-	return &Crawler{
-		repo:   repo,
-		log:    log,
-		cookie: cfg.APICookie,
-		client: &http.Client{Timeout: 30 * time.Second},
+// Option configures a Crawler
+type Option func(*Crawler)
+
+// WithCache flushes c after each published run
+func WithCache(c cache.Cache) Option {
+	return func(cr *Crawler) { cr.cache = c }
+}
+
+func New(repo storage.Store, log *logger.Logger, cfg *config.Config, opts ...Option) *Crawler {
+	c := &Crawler{
+		repo:      repo,
+		log:       log,
+		cookie:    cfg.APICookie,
+		client:    &http.Client{Timeout: 30 * time.Second},
+		retireMax: cfg.RetireMaxFraction,
 	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
 }
 
-func (c *Crawler) Run(ctx context.Context) error {
-	c.log.Info("Starting crawler process")
+// Report summarizes one Run
+type Report struct {
+	RunID     string
+	Provinces int             // Provinces fetched
+	Units     int             // Units fetched
+	Failed    []int           // Provinces whose units could not be fetched or stored
+	Changes   []models.Change // Differences from the data stored before the run
+	Retired   int             // Provinces and units retired as removed upstream
+
+	// Why removals were neither retired nor recorded; "" when they were
+	RetireSkipped string
+}
+
+// Summary renders the report for people: counts, failures and the changes
+func (r *Report) Summary() string {
+	summary := changelog.Summarize(r.RunID, r.Changes, maxSummaryLines)
+	if len(r.Failed) > 0 {
+		summary += fmt.Sprintf("  %d provinces failed: %v\n", len(r.Failed), r.Failed)
+	}
+	if r.RetireSkipped != "" {
+		summary += "  Removals not retired: " + r.RetireSkipped + "\n"
+	} else if r.Retired > 0 {
+		summary += fmt.Sprintf("  Retired %d provinces and units\n", r.Retired)
+	}
+	return fmt.Sprintf("Fetched %d provinces, %d units\n", r.Provinces, r.Units) + summary
+}
+
+// maxSummaryLines caps the changes listed one per line in Report.Summary
+const maxSummaryLines = 200
+
+// Run crawls every province and its units into staging, validates the result, then publishes it
+// to the live data in one atomic step together with the change log under a new run ID, retiring
+// the provinces and units removed upstream. A crawl failing validation leaves the live data
+// untouched. Removals are only retired (and recorded) after a complete crawl in which at most
+// retireMax of the units vanished.
+func (c *Crawler) Run(ctx context.Context) (*Report, error) {
+	report := &Report{RunID: changelog.NewRunID(time.Now())}
+	c.log.Info("Starting crawler process", "run_id", report.RunID)
+
+	// Hold the staging area until the run is published, so an overlapping crawl can neither wipe
+	// it nor publish in between and stale this run's diff; runs left staged by a crash are dropped
+	release, err := c.repo.BeginStaging(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin staging: %w", err)
+	}
+	defer release()
+
+	before, err := c.loadStored(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load stored data: %w", err)
+	}
 
 	// 1. Fetch Provinces
 	c.log.Info("Fetching provinces...")
 	provinces, err := c.fetchProvincesWithRetry(ctx)
 	if err != nil {
-		return fmt.Errorf("failed to fetch provinces: %w", err)
+		return nil, fmt.Errorf("failed to fetch provinces: %w", err)
 	}
 	c.log.Info("Found provinces", "count", len(provinces))
 
+	after := changelog.Snapshot{Provinces: provinces}
 	for _, p := range provinces {
 		select {
 		case <-ctx.Done():
-			return ctx.Err()
+			return nil, ctx.Err()
 		default:
 		}
 
 		c.log.Info("Processing Province", "name", p.Name, "id", p.ID)
 
-		// Insert Province
-		if err := c.repo.UpsertProvince(ctx, p); err != nil {
-			c.log.Error("Failed to upsert province", "id", p.ID, "error", err)
-			continue
-		}
-
-		// 2. Fetch Units for Province
+		// 2. Fetch Units for Province. Without them the province is still staged, so it is not
+		// taken for removed, but its stored units are kept as they are.
 		units, err := c.fetchUnitsWithRetry(ctx, p.ID)
 		if err != nil {
 			c.log.Error("Failed to fetch units", "province_id", p.ID, "error", err)
-			continue
+			report.Failed = append(report.Failed, p.ID)
+			units = nil
+		} else {
+			c.log.Info("Found units", "province_id", p.ID, "count", len(units))
 		}
-		c.log.Info("Found units", "province_id", p.ID, "count", len(units))
 
-		for _, u := range units {
-			if err := c.repo.UpsertAdminUnit(ctx, u); err != nil {
-				c.log.Error("Failed to upsert unit", "id", u.ID, "error", err)
+		var preds []models.Predecessor
+		for i := range units {
+			u := &units[i]
+			if u.LevelCode = levels.Code(u.Level); u.LevelCode == "" {
+				c.log.Warn("Unknown unit level", "id", u.ID, "level", u.Level)
+			}
+			// Structured lineage from the free-text truocsapnhap
+			for _, pred := range lineage.Parse(u.PreMergerDesc) {
+				pred.UnitID = u.ID
+				preds = append(preds, pred)
 			}
 		}
 
+		// 3. Stage them; the live data is untouched until the run is published
+		if err := c.repo.StageProvince(ctx, report.RunID, p, units, preds); err != nil {
+			c.log.Error("Failed to stage province", "id", p.ID, "error", err)
+			if !slices.Contains(report.Failed, p.ID) {
+				report.Failed = append(report.Failed, p.ID)
+			}
+			continue
+		}
+		after.Units = append(after.Units, units...)
+		report.Units += len(units)
+
 		time.Sleep(500 * time.Millisecond) // Polite delay
 	}
+	report.Provinces = len(provinces)
+
+	// 4. Validate before anything goes live
+	if err := validate(after); err != nil {
+		return report, fmt.Errorf("crawl failed validation, live data left untouched: %w", err)
+	}
+
+	// 5. Work out what changed. Only a complete crawl shows which units are gone: every stored
+	// province, including those gone upstream, then had its whole unit list fetched.
+	complete := make(map[int]bool)
+	if len(report.Failed) == 0 {
+		for _, p := range before.Provinces {
+			complete[p.ID] = true
+		}
+		for _, p := range provinces {
+			complete[p.ID] = true
+		}
+	}
+	report.Changes = changelog.Diff(before, after, complete)
+	provinceIDs, unitIDs := changelog.Removals(report.Changes)
+	if report.RetireSkipped = checkRetirement(len(report.Failed), len(unitIDs), len(before.Units), c.retireMax); report.RetireSkipped != "" {
+		c.log.Error("Skipping retirement", "reason", report.RetireSkipped)
+		report.Changes = changelog.WithoutRemovals(report.Changes)
+		provinceIDs, unitIDs = nil, nil
+	}
+
+	// 6. Publish: upsert the staged data, record the changes and retire (soft-delete) what was
+	// removed upstream, all at once
+	if err := c.repo.Publish(ctx, report.RunID, report.Changes); err != nil {
+		return report, fmt.Errorf("failed to publish crawl: %w", err)
+	}
+	report.Retired = len(provinceIDs) + len(unitIDs)
+
+	// 7. Cached responses now describe the old data
+	if c.cache != nil {
+		if err := c.cache.Flush(ctx); err != nil {
+			c.log.Warn("Failed to flush cache, entries expire with their TTL", "error", err)
+		}
+	}
+
+	c.log.Info("Crawler finished successfully", "run_id", report.RunID,
+		"units", report.Units, "failed_provinces", len(report.Failed), "changes", len(report.Changes), "retired", report.Retired)
+	return report, nil
+}
+
+// loadStored reads every stored province and unit, the "before" side of the run's diff. Reads skip
+// retired records: they are not removed again, and reappear as added.
+func (c *Crawler) loadStored(ctx context.Context) (changelog.Snapshot, error) {
+	provinces, err := c.repo.GetProvinces(ctx)
+	if err != nil {
+		return changelog.Snapshot{}, err
+	}
+	snap := changelog.Snapshot{Provinces: provinces}
+	for _, p := range provinces {
+		units, err := c.repo.GetUnitsByProvince(ctx, p.ID)
+		if err != nil {
+			return changelog.Snapshot{}, err
+		}
+		snap.Units = append(snap.Units, units...)
+	}
+	return snap, nil
+}
+
+// checkRetirement returns why the removals of a run must not be retired, or "" when they may be:
+// the crawl must be complete, and at most maxFraction of the stored units may have vanished (a
+// larger share more likely means a broken upstream than real abolitions)
+func checkRetirement(failed, removedUnits, storedUnits int, maxFraction float64) string {
+	switch {
+	case failed > 0:
+		return fmt.Sprintf("incomplete crawl, %d provinces failed", failed)
+	case removedUnits > 0 && float64(removedUnits) > maxFraction*float64(storedUnits):
+		return fmt.Sprintf("%d of %d stored units vanished, more than RETIRE_MAX_FRACTION=%g", removedUnits, storedUnits, maxFraction)
+	}
+	return ""
+}
+
+// maxValidationProblems caps the problems listed in a validation error
+const maxValidationProblems = 5
 
-	c.log.Info("Crawler finished successfully")
-	return nil
+// validate checks a crawl before it is published: it must have provinces and units, unique IDs,
+// names, and no unit outside the crawled provinces
+func validate(snap changelog.Snapshot) error {
+	var problems []string
+	if len(snap.Provinces) == 0 {
+		problems = append(problems, "no provinces")
+	}
+	if len(snap.Units) == 0 {
+		problems = append(problems, "no units")
+	}
+
+	provinces := make(map[int]bool, len(snap.Provinces))
+	for _, p := range snap.Provinces {
+		if provinces[p.ID] {
+			problems = append(problems, fmt.Sprintf("duplicate province %d", p.ID))
+		}
+		provinces[p.ID] = true
+		if strings.TrimSpace(p.Name) == "" {
+			problems = append(problems, fmt.Sprintf("province %d has no name", p.ID))
+		}
+	}
+	units := make(map[int]bool, len(snap.Units))
+	for _, u := range snap.Units {
+		if units[u.ID] {
+			problems = append(problems, fmt.Sprintf("duplicate unit %d", u.ID))
+		}
+		units[u.ID] = true
+		if strings.TrimSpace(u.Name) == "" {
+			problems = append(problems, fmt.Sprintf("unit %d has no name", u.ID))
+		}
+		if !provinces[u.ProvinceID] {
+			problems = append(problems, fmt.Sprintf("unit %d belongs to unknown province %d", u.ID, u.ProvinceID))
+		}
+	}
+
+	if len(problems) == 0 {
+		return nil
+	}
+	if len(problems) > maxValidationProblems {
+		more := len(problems) - maxValidationProblems
+		problems = append(problems[:maxValidationProblems], fmt.Sprintf("and %d more", more))
+	}
+	return errors.New(strings.Join(problems, "; "))
 }
 
 func (c *Crawler) fetchProvincesWithRetry(ctx context.Context) ([]models.Province, error) {
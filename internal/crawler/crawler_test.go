@@ -0,0 +1,66 @@
+package crawler
+
+import (
+	"strings"
+	"testing"
+
+	"vn-admin-api/internal/changelog"
+	"vn-admin-api/internal/models"
+)
+
+func TestCheckRetirement(t *testing.T) {
+	tests := []struct {
+		name                  string
+		failed, removed, have int
+		want                  string // Substring of the reason; "" when retirement may go ahead
+	}{
+		{"complete crawl", 0, 5, 1000, ""},
+		{"nothing removed", 0, 0, 0, ""},
+		{"at the threshold", 0, 50, 1000, ""},
+		{"failed provinces", 2, 0, 1000, "2 provinces failed"},
+		{"too many vanished", 0, 51, 1000, "51 of 1000 stored units vanished"},
+		{"upstream emptied", 0, 3, 3, "3 of 3"},
+	}
+	for _, tt := range tests {
+		got := checkRetirement(tt.failed, tt.removed, tt.have, 0.05)
+		if (tt.want == "") != (got == "") || !strings.Contains(got, tt.want) {
+			t.Errorf("%s: checkRetirement = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestValidate(t *testing.T) {
+	valid := changelog.Snapshot{
+		Provinces: []models.Province{{ID: 1, Name: "Thành phố Hà Nội"}},
+		Units:     []models.AdminUnit{{ID: 101, ProvinceID: 1, Name: "Phường Ba Đình"}},
+	}
+	if err := validate(valid); err != nil {
+		t.Errorf("validate(valid) = %v", err)
+	}
+
+	tests := []struct {
+		name string
+		snap changelog.Snapshot
+		want string
+	}{
+		{"empty", changelog.Snapshot{}, "no provinces; no units"},
+		{"duplicate province", changelog.Snapshot{
+			Provinces: []models.Province{{ID: 1, Name: "A"}, {ID: 1, Name: "A"}},
+			Units:     valid.Units,
+		}, "duplicate province 1"},
+		{"orphan unit", changelog.Snapshot{
+			Provinces: valid.Provinces,
+			Units:     []models.AdminUnit{{ID: 101, ProvinceID: 2, Name: "Phường Ba Đình"}},
+		}, "unit 101 belongs to unknown province 2"},
+		{"unnamed units", changelog.Snapshot{
+			Provinces: valid.Provinces,
+			Units:     []models.AdminUnit{{ID: 1, ProvinceID: 1}, {ID: 2, ProvinceID: 1}, {ID: 3, ProvinceID: 1}, {ID: 4, ProvinceID: 1}, {ID: 5, ProvinceID: 1}, {ID: 6, ProvinceID: 1}, {ID: 7, ProvinceID: 1}},
+		}, "unit 5 has no name; and 2 more"},
+	}
+	for _, tt := range tests {
+		err := validate(tt.snap)
+		if err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("%s: validate = %v, want %q", tt.name, err, tt.want)
+		}
+	}
+}
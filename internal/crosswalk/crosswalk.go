@@ -0,0 +1,168 @@
+// Package crosswalk imports mappings from outside systems' identifiers (shipping carriers such as
+// GHN, GHTK or Viettel Post) to provinces and units. Input is CSV with a header row:
+//
+//	external_id,unit_id,province_id[,system]
+//
+// Each row fills exactly one of unit_id (admin_units.id) or province_id (provinces.id).
+package crosswalk
+
+import (
+	"context"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"vn-admin-api/internal/models"
+)
+
+// Store is the subset of storage.Store the importer needs
+type Store interface {
+	GetProvinces(ctx context.Context) ([]models.Province, error)
+	GetUnitsByProvince(ctx context.Context, provinceID int) ([]models.AdminUnit, error)
+	SaveExternalIDs(ctx context.Context, system string, ids []models.ExternalID, replace bool) error
+}
+
+var systemName = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]{0,63}$`)
+
+// NormalizeSystem lowercases a system name and checks it is a short slug ("Viettel_Post" -> "viettel_post")
+func NormalizeSystem(system string) (string, error) {
+	s := strings.ToLower(strings.TrimSpace(system))
+	if !systemName.MatchString(s) {
+		return "", fmt.Errorf("invalid system %q (expected letters, digits, _ or -)", system)
+	}
+	return s, nil
+}
+
+// Report summarizes an import
+type Report struct {
+	Rows     int      `json:"rows"`
+	Imported int      `json:"imported"`
+	Invalid  []string `json:"invalid,omitempty"` // Rows skipped, with the reason
+}
+
+// Import reads CSV rows for system and saves the valid ones. A system column, when present, must
+// agree with system. Rows naming an unknown record, or an external ID already mapped elsewhere
+// in the file, are reported and skipped. With replace the system's stored IDs are swapped for
+// the file's, so any invalid row aborts the import instead.
+func Import(ctx context.Context, store Store, r io.Reader, system string, replace bool) (*Report, error) {
+	system, err := NormalizeSystem(system)
+	if err != nil {
+		return nil, err
+	}
+
+	cr := csv.NewReader(r)
+	cr.FieldsPerRecord = -1
+	cr.TrimLeadingSpace = true
+	header, err := cr.Read()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read CSV header: %w", err)
+	}
+	cols := map[string]int{"external_id": -1, "unit_id": -1, "province_id": -1, "system": -1}
+	for i, name := range header {
+		name = strings.ToLower(strings.TrimSpace(strings.TrimPrefix(name, "\ufeff")))
+		if _, ok := cols[name]; ok {
+			cols[name] = i
+		}
+	}
+	if cols["external_id"] < 0 || (cols["unit_id"] < 0 && cols["province_id"] < 0) {
+		return nil, fmt.Errorf("CSV header needs external_id and unit_id or province_id")
+	}
+
+	provinces, err := store.GetProvinces(ctx)
+	if err != nil {
+		return nil, err
+	}
+	provinceIDs, unitIDs := make(map[int]bool, len(provinces)), make(map[int]bool)
+	for _, p := range provinces {
+		provinceIDs[p.ID] = true
+		units, err := store.GetUnitsByProvince(ctx, p.ID)
+		if err != nil {
+			return nil, err
+		}
+		for _, u := range units {
+			unitIDs[u.ID] = true
+		}
+	}
+
+	report := &Report{}
+	seen := make(map[string]models.ExternalID)
+	var ids []models.ExternalID
+	for line := 2; ; line++ {
+		row, err := cr.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read CSV: %w", err)
+		}
+		report.Rows++
+		get := func(col string) string {
+			if i := cols[col]; i >= 0 && i < len(row) {
+				return strings.TrimSpace(row[i])
+			}
+			return ""
+		}
+
+		id, reason := parseRow(get, system)
+		if reason == "" {
+			switch {
+			case id.Kind == models.RefKindProvince && !provinceIDs[id.RefID]:
+				reason = fmt.Sprintf("unknown province %d", id.RefID)
+			case id.Kind == models.RefKindUnit && !unitIDs[id.RefID]:
+				reason = fmt.Sprintf("unknown unit %d", id.RefID)
+			}
+		}
+		if prev, ok := seen[id.ExternalID]; reason == "" && ok && prev != id {
+			reason = fmt.Sprintf("%q is already mapped to %s %d", id.ExternalID, prev.Kind, prev.RefID)
+		}
+		if reason != "" {
+			report.Invalid = append(report.Invalid, fmt.Sprintf("line %d: %s", line, reason))
+			continue
+		}
+		if _, ok := seen[id.ExternalID]; !ok {
+			seen[id.ExternalID] = id
+			ids = append(ids, id)
+		}
+	}
+
+	if replace && len(report.Invalid) > 0 {
+		return report, fmt.Errorf("%d invalid rows; %s IDs not replaced", len(report.Invalid), system)
+	}
+	if err := store.SaveExternalIDs(ctx, system, ids, replace); err != nil {
+		return report, err
+	}
+	report.Imported = len(ids)
+	return report, nil
+}
+
+// parseRow builds the ExternalID of one row, or returns why it is invalid
+func parseRow(get func(col string) string, system string) (models.ExternalID, string) {
+	id := models.ExternalID{System: system, ExternalID: get("external_id")}
+	if id.ExternalID == "" {
+		return id, "missing external_id"
+	}
+	if s := get("system"); s != "" {
+		if norm, err := NormalizeSystem(s); err != nil || norm != system {
+			return id, fmt.Sprintf("system %q does not match %q", s, system)
+		}
+	}
+
+	unit, province := get("unit_id"), get("province_id")
+	if (unit == "") == (province == "") {
+		return id, "exactly one of unit_id or province_id is required"
+	}
+	id.Kind, id.RefID = models.RefKindUnit, 0
+	value := unit
+	if province != "" {
+		id.Kind, value = models.RefKindProvince, province
+	}
+	n, err := strconv.Atoi(value)
+	if err != nil || n <= 0 {
+		return id, fmt.Sprintf("invalid %s_id %q", id.Kind, value)
+	}
+	id.RefID = n
+	return id, ""
+}
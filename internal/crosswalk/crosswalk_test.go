@@ -0,0 +1,79 @@
+package crosswalk
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+
+	"vn-admin-api/internal/models"
+	"vn-admin-api/internal/storage"
+)
+
+func TestImport(t *testing.T) {
+	ctx := context.Background()
+	store := storage.NewMemoryStore()
+	if err := store.UpsertProvince(ctx, models.Province{ID: 1, Name: "Thành phố Hà Nội", Code: 1}); err != nil {
+		t.Fatal(err)
+	}
+	for _, id := range []int{101, 102} {
+		if err := store.UpsertAdminUnit(ctx, models.AdminUnit{ID: id, ProvinceID: 1, Name: fmt.Sprintf("Phường %d", id)}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	csv := "external_id,unit_id,province_id,system\n" +
+		"1A0101,101,,GHN\n" +
+		"1A0102,101,,\n" + // two carrier wards merged into one unit
+		"201,,1,ghn\n" +
+		"1A0199,999,,\n" +
+		"1A0101,102,,\n" +
+		"1A0103,101,1,\n" +
+		"1A0104,102,,ghtk\n"
+	report, err := Import(ctx, store, strings.NewReader(csv), "GHN", false)
+	if err != nil {
+		t.Fatalf("Import: %v", err)
+	}
+	wantInvalid := []string{
+		"line 5: unknown unit 999",
+		`line 6: "1A0101" is already mapped to unit 101`,
+		"line 7: exactly one of unit_id or province_id is required",
+		`line 8: system "ghtk" does not match "ghn"`,
+	}
+	if report.Rows != 7 || report.Imported != 3 || !reflect.DeepEqual(report.Invalid, wantInvalid) {
+		t.Errorf("Unexpected report %+v", report)
+	}
+
+	ids, _ := store.GetExternalIDs(ctx, models.RefKindUnit, 101)
+	if len(ids) != 2 || ids[0].ExternalID != "1A0101" || ids[1].ExternalID != "1A0102" || ids[0].System != "ghn" {
+		t.Errorf("Unexpected unit IDs %+v", ids)
+	}
+	id, err := store.GetExternalID(ctx, "ghn", "201")
+	if err != nil || id.Kind != models.RefKindProvince || id.RefID != 1 {
+		t.Errorf("Expected ghn 201 -> province 1, got %+v (%v)", id, err)
+	}
+
+	// replace swaps the system's mappings, and refuses to with invalid rows
+	if _, err := Import(ctx, store, strings.NewReader("external_id,unit_id\n1A0199,999\n"), "ghn", true); err == nil {
+		t.Errorf("Expected replace with invalid rows to fail")
+	}
+	if _, err := Import(ctx, store, strings.NewReader("external_id,unit_id\n9,102\n"), "ghn", true); err != nil {
+		t.Fatalf("Import: %v", err)
+	}
+	if _, err := store.GetExternalID(ctx, "ghn", "1A0101"); err != storage.ErrNotFound {
+		t.Errorf("Expected replaced ID to be gone, got %v", err)
+	}
+	if ids, _ := store.GetExternalIDs(ctx, models.RefKindUnit, 102); len(ids) != 1 || ids[0].ExternalID != "9" {
+		t.Errorf("Unexpected unit IDs after replace %+v", ids)
+	}
+}
+
+func TestNormalizeSystem(t *testing.T) {
+	for in, want := range map[string]string{"GHN": "ghn", " viettel_post ": "viettel_post", "a b": "", "": "", "x/y": ""} {
+		got, err := NormalizeSystem(in)
+		if got != want || (err != nil) != (want == "") {
+			t.Errorf("NormalizeSystem(%q) = %q, %v; want %q", in, got, err, want)
+		}
+	}
+}
@@ -0,0 +1,81 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"vn-admin-api/internal/models"
+
+	"github.com/lib/pq"
+)
+
+// boundaryTables maps a boundary kind to its table and key column
+var boundaryTables = map[string]struct{ table, key string }{
+	models.BoundaryProvince: {"province_boundaries", "province_id"},
+	models.BoundaryUnit:     {"unit_boundaries", "unit_id"},
+}
+
+// UpsertBoundary inserts or replaces the outline of a province or unit
+func (r *Repository) UpsertBoundary(ctx context.Context, b models.Boundary) error {
+	t, ok := boundaryTables[b.Kind]
+	if !ok {
+		return fmt.Errorf("unknown boundary kind %q", b.Kind)
+	}
+	query := `
+		INSERT INTO ` + t.table + ` (` + t.key + `, geometry, updated_at)
+		VALUES ($1, $2, NOW())
+		ON CONFLICT (` + t.key + `)
+		DO UPDATE SET geometry = $2, updated_at = NOW()`
+
+	if _, err := r.db.ExecContext(ctx, query, b.RefID, []byte(b.Geometry)); err != nil {
+		return fmt.Errorf("failed to upsert %s boundary %d: %w", b.Kind, b.RefID, err)
+	}
+	return nil
+}
+
+// GetBoundaries returns every stored outline of the given kind, ordered by ID
+func (r *Repository) GetBoundaries(ctx context.Context, kind string) ([]models.Boundary, error) {
+	t, ok := boundaryTables[kind]
+	if !ok {
+		return nil, fmt.Errorf("unknown boundary kind %q", kind)
+	}
+	rows, err := r.db.QueryContext(ctx,
+		"SELECT "+t.key+", geometry, updated_at FROM "+t.table+" ORDER BY "+t.key)
+	if err != nil {
+		return nil, err
+	}
+	return scanBoundaries(rows, kind)
+}
+
+// GetBoundariesByIDs returns the stored outlines of the given provinces or units, ordered by ID.
+// IDs without an outline are skipped.
+func (r *Repository) GetBoundariesByIDs(ctx context.Context, kind string, ids []int) ([]models.Boundary, error) {
+	t, ok := boundaryTables[kind]
+	if !ok {
+		return nil, fmt.Errorf("unknown boundary kind %q", kind)
+	}
+	rows, err := r.db.QueryContext(ctx,
+		"SELECT "+t.key+", geometry, updated_at FROM "+t.table+" WHERE "+t.key+" = ANY($1) ORDER BY "+t.key,
+		pq.Array(ids))
+	if err != nil {
+		return nil, err
+	}
+	return scanBoundaries(rows, kind)
+}
+
+func scanBoundaries(rows *sql.Rows, kind string) ([]models.Boundary, error) {
+	defer rows.Close()
+
+	boundaries := make([]models.Boundary, 0)
+	for rows.Next() {
+		b := models.Boundary{Kind: kind}
+		var geometry []byte
+		if err := rows.Scan(&b.RefID, &geometry, &b.UpdatedAt); err != nil {
+			return nil, err
+		}
+		b.Geometry = geometry
+		boundaries = append(boundaries, b)
+	}
+	return boundaries, rows.Err()
+}
@@ -0,0 +1,88 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"vn-admin-api/internal/models"
+)
+
+// SaveChanges appends the changes of one crawl run to change_log in a single transaction
+func (r *Repository) SaveChanges(ctx context.Context, runID string, changes []models.Change) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := saveChanges(ctx, tx, runID, changes); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// saveChanges appends changes to change_log within tx
+func saveChanges(ctx context.Context, tx *sql.Tx, runID string, changes []models.Change) error {
+	stmt, err := tx.PrepareContext(ctx, `
+		INSERT INTO change_log (run_id, kind, ref_id, province_id, name, old_value, new_value, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, NOW())`)
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+
+	for _, c := range changes {
+		if _, err := stmt.ExecContext(ctx, runID, c.Kind, c.RefID, c.ProvinceID, c.Name,
+			nullString(c.OldValue), nullString(c.NewValue)); err != nil {
+			return fmt.Errorf("failed to log %s of %d: %w", c.Kind, c.RefID, err)
+		}
+	}
+	return nil
+}
+
+// GetChanges returns change_log entries matching q, ordered by ID
+func (r *Repository) GetChanges(ctx context.Context, q models.ChangeQuery) ([]models.Change, error) {
+	var where []string
+	var args []interface{}
+	arg := func(v interface{}) string {
+		args = append(args, v)
+		return "$" + strconv.Itoa(len(args))
+	}
+	if !q.Since.IsZero() {
+		// created_at is NOW() in the session zone, UTC since connString pins it
+		where = append(where, "created_at > "+arg(timestamp(q.Since))+"::timestamp")
+	}
+	if q.RunID != "" {
+		where = append(where, "run_id = "+arg(q.RunID))
+	}
+	if q.AfterID != 0 {
+		where = append(where, "id > "+arg(q.AfterID))
+	}
+	filter := ""
+	if len(where) > 0 {
+		filter = " WHERE " + strings.Join(where, " AND ")
+	}
+
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT id, run_id, kind, ref_id, province_id, name, old_value, new_value, created_at
+		FROM change_log`+filter+" ORDER BY id LIMIT "+arg(q.Limit), args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	changes := make([]models.Change, 0)
+	for rows.Next() {
+		var c models.Change
+		var oldValue, newValue sql.NullString
+		if err := rows.Scan(&c.ID, &c.RunID, &c.Kind, &c.RefID, &c.ProvinceID, &c.Name, &oldValue, &newValue, &c.CreatedAt); err != nil {
+			return nil, err
+		}
+		c.OldValue, c.NewValue = oldValue.String, newValue.String
+		changes = append(changes, c)
+	}
+	return changes, rows.Err()
+}
@@ -0,0 +1,37 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"vn-admin-api/internal/models"
+)
+
+func TestGetChanges_SinceNonUTCRole(t *testing.T) {
+	ctx := context.Background()
+	repo := testRepository(t)
+
+	runID := fmt.Sprintf("test-%d", time.Now().UnixNano())
+	change := models.Change{Kind: models.ChangeProvinceAdded, RefID: 990001, ProvinceID: 990001, Name: "Tỉnh Kiểm Thử"}
+	if err := repo.SaveChanges(ctx, runID, []models.Change{change}); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, tt := range []struct {
+		since time.Time
+		want  int
+	}{
+		{time.Now().Add(-time.Minute), 1},
+		{time.Now().Add(time.Minute), 0},
+	} {
+		changes, err := repo.GetChanges(ctx, models.ChangeQuery{Since: tt.since, RunID: runID, Limit: 10})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(changes) != tt.want {
+			t.Errorf("Since %v: expected %d changes, got %d", tt.since, tt.want, len(changes))
+		}
+	}
+}
@@ -3,16 +3,26 @@ package database
 import (
 	"context"
 	"database/sql"
+	"errors"
 	"fmt"
 	"strconv"
+	"strings"
 	"time"
 
 	"vn-admin-api/internal/config"
 	"vn-admin-api/internal/models"
+	"vn-admin-api/internal/search"
+	"vn-admin-api/internal/vntext"
 
 	_ "github.com/lib/pq"
 )
 
+// ErrNotFound is returned when a requested row does not exist
+var ErrNotFound = errors.New("not found")
+
+// ErrJobLost is returned by job updates from a worker whose job was claimed again by another one
+var ErrJobLost = errors.New("job claimed by another worker")
+
 // Repository handles all database interactions
 type Repository struct {
 	db *sql.DB
@@ -23,15 +33,20 @@ func NewRepository(db *sql.DB) *Repository {
 	return &Repository{db: db}
 }
 
-// Connect establishes a connection to the database and returns a Repository
-func Connect(cfg *config.Config) (*Repository, error) {
-	connStr := fmt.Sprintf(
-		"host=%s port=%s user=%s password=%s dbname=%s sslmode=%s",
+// connString builds the connection string for cfg. Sessions are pinned to UTC whatever the server
+// or role default: NOW() lands in timestamp-without-time-zone columns in the session time zone,
+// and queries compare those columns with UTC instants (see timestamp).
+func connString(cfg *config.Config) string {
+	return fmt.Sprintf(
+		"host=%s port=%s user=%s password=%s dbname=%s sslmode=%s timezone=UTC",
 		cfg.DBHost, cfg.DBPort, cfg.DBUser, cfg.DBPassword, cfg.DBName, cfg.DBSSLMode,
 	)
+}
 
+// Connect establishes a connection to the database and returns a Repository
+func Connect(cfg *config.Config) (*Repository, error) {
 	// Open connection (lazy)
-	db, err := sql.Open("postgres", connStr)
+	db, err := sql.Open("postgres", connString(cfg))
 	if err != nil {
 		return nil, fmt.Errorf("failed to open database connection: %w", err)
 	}
@@ -58,26 +73,22 @@ func (r *Repository) Close() error {
 	return r.db.Close()
 }
 
+// Ping verifies the database connection is alive
+func (r *Repository) Ping(ctx context.Context) error {
+	return r.db.PingContext(ctx)
+}
+
 // DB returns the underlying sql.DB (useful for transactions or direct access if needed)
 func (r *Repository) DB() *sql.DB {
 	return r.db
 }
 
-// InitSchema executes the schema SQL to create tables
-func (r *Repository) InitSchema(schemaSQL string) error {
-	if _, err := r.db.Exec(schemaSQL); err != nil {
-		return fmt.Errorf("failed to execute schema: %w", err)
-	}
-	return nil
-}
-
-// UpsertProvince inserts or updates a province
+// UpsertProvince inserts or updates a province, restoring it if it was retired. Rows that would not
+// change are left alone, so updated_at and the version history only move on real changes.
 func (r *Repository) UpsertProvince(ctx context.Context, p models.Province) error {
 	query := `
 		INSERT INTO provinces (id, name, code, updated_at) 
-		VALUES ($1, $2, $3, NOW()) 
-		ON CONFLICT (id) 
-		DO UPDATE SET name = $2, code = $3, updated_at = NOW()`
+		VALUES ($1, $2, $3, NOW()) ` + provinceOnConflict
 
 	_, err := r.db.ExecContext(ctx, query, p.ID, p.Name, fmt.Sprintf("%d", p.Code))
 	if err != nil {
@@ -86,39 +97,96 @@ func (r *Repository) UpsertProvince(ctx context.Context, p models.Province) erro
 	return nil
 }
 
-// UpsertAdminUnit inserts or updates an admin unit
+// provinceOnConflict completes an INSERT INTO provinces into the upsert of UpsertProvince
+const provinceOnConflict = `
+		ON CONFLICT (id) 
+		DO UPDATE SET name = EXCLUDED.name, code = EXCLUDED.code, updated_at = NOW(), retired_at = NULL
+		WHERE (provinces.name, provinces.code) IS DISTINCT FROM (EXCLUDED.name, EXCLUDED.code)
+			OR provinces.retired_at IS NOT NULL`
+
+// UpsertAdminUnit inserts or updates an admin unit, including its province when it moved upstream.
+// Like UpsertProvince, it restores retired units and leaves unchanged rows alone.
 func (r *Repository) UpsertAdminUnit(ctx context.Context, u models.AdminUnit) error {
 	query := `
-		INSERT INTO admin_units (id, province_id, name, level, code, pre_merger_desc, lat, long, updated_at) 
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, NOW()) 
-		ON CONFLICT (id) 
-		DO UPDATE SET 
-			name=$3, level=$4, code=$5, pre_merger_desc=$6, lat=$7, long=$8, updated_at=NOW()`
+		INSERT INTO admin_units (id, province_id, name, level, code, pre_merger_desc, lat, long, level_code, updated_at) 
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, NOW()) ` + unitOnConflict
 
 	_, err := r.db.ExecContext(ctx, query,
-		u.ID, u.ProvinceID, u.Name, u.Level, u.Code, u.PreMergerDesc, u.Lat, u.Long)
+		u.ID, u.ProvinceID, u.Name, u.Level, u.Code, u.PreMergerDesc, u.Lat, u.Long, nullString(u.LevelCode))
 	if err != nil {
 		return fmt.Errorf("failed to upsert unit %d: %w", u.ID, err)
 	}
 	return nil
 }
 
-// GetProvinces returns all provinces
+// unitOnConflict completes an INSERT INTO admin_units into the upsert of UpsertAdminUnit
+const unitOnConflict = `
+		ON CONFLICT (id) 
+		DO UPDATE SET 
+			province_id=EXCLUDED.province_id, name=EXCLUDED.name, level=EXCLUDED.level, code=EXCLUDED.code,
+			pre_merger_desc=EXCLUDED.pre_merger_desc, lat=EXCLUDED.lat, long=EXCLUDED.long, level_code=EXCLUDED.level_code,
+			updated_at=NOW(), retired_at=NULL
+		WHERE (admin_units.province_id, admin_units.name, admin_units.level, admin_units.code, admin_units.pre_merger_desc,
+			admin_units.lat, admin_units.long, admin_units.level_code)
+			IS DISTINCT FROM (EXCLUDED.province_id, EXCLUDED.name, EXCLUDED.level, EXCLUDED.code, EXCLUDED.pre_merger_desc,
+			EXCLUDED.lat, EXCLUDED.long, EXCLUDED.level_code)
+			OR admin_units.retired_at IS NOT NULL`
+
+// SetGSOCodes stores official GSO codes for the given province and unit IDs in one transaction,
+// touching updated_at only when a code changes. Records not listed keep their code.
+func (r *Repository) SetGSOCodes(ctx context.Context, provinces, units map[int]string) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	for _, set := range []struct {
+		table string
+		codes map[int]string
+	}{{"provinces", provinces}, {"admin_units", units}} {
+		for id, code := range set.codes {
+			_, err := tx.ExecContext(ctx,
+				"UPDATE "+set.table+" SET gso_code = $2, updated_at = NOW() WHERE id = $1 AND gso_code IS DISTINCT FROM $2",
+				id, nullString(code))
+			if err != nil {
+				return fmt.Errorf("failed to set GSO code of %s %d: %w", set.table, id, err)
+			}
+		}
+	}
+	return tx.Commit()
+}
+
+// GetProvinces returns all live (not retired) provinces
 func (r *Repository) GetProvinces(ctx context.Context) ([]models.Province, error) {
-	rows, err := r.db.QueryContext(ctx, "SELECT id, name, code, updated_at FROM provinces ORDER BY id")
+	rows, err := r.db.QueryContext(ctx, "SELECT "+provinceColumns+" FROM provinces WHERE retired_at IS NULL ORDER BY id")
 	if err != nil {
 		return nil, err
 	}
+
+	return scanProvinces(rows)
+}
+
+// provinceColumns is the column list matching scanProvinces
+const provinceColumns = "id, name, code, updated_at, gso_code, retired_at"
+
+// scanProvinces reads rows selected with provinceColumns and closes them
+func scanProvinces(rows *sql.Rows) ([]models.Province, error) {
 	defer rows.Close()
 
 	provinces := make([]models.Province, 0)
 	for rows.Next() {
 		var p models.Province
-		var codeStr sql.NullString
+		var codeStr, gsoCode sql.NullString
+		var retiredAt sql.NullTime
 
-		if err := rows.Scan(&p.ID, &p.Name, &codeStr, &p.UpdatedAt); err != nil {
+		if err := rows.Scan(&p.ID, &p.Name, &codeStr, &p.UpdatedAt, &gsoCode, &retiredAt); err != nil {
 			return nil, err
 		}
+		p.GSOCode = gsoCode.String
+		if retiredAt.Valid {
+			p.RetiredAt = &retiredAt.Time
+		}
 		if codeStr.Valid {
 			if codeVal, err := strconv.Atoi(codeStr.String); err == nil {
 				p.Code = codeVal
@@ -126,63 +194,337 @@ func (r *Repository) GetProvinces(ctx context.Context) ([]models.Province, error
 		}
 		provinces = append(provinces, p)
 	}
-	return provinces, nil
+	return provinces, rows.Err()
 }
 
-// GetUnitsByProvince returns all admin units for a specific province
+// GetUnitsByProvince returns all live admin units for a specific province
 func (r *Repository) GetUnitsByProvince(ctx context.Context, provinceID int) ([]models.AdminUnit, error) {
 	rows, err := r.db.QueryContext(ctx,
-		"SELECT id, province_id, name, level, code, pre_merger_desc, lat, long, updated_at FROM admin_units WHERE province_id = $1 ORDER BY id",
+		"SELECT "+unitColumns+" FROM admin_units WHERE province_id = $1 AND retired_at IS NULL ORDER BY id",
 		provinceID)
 	if err != nil {
 		return nil, err
 	}
+	return scanUnits(rows)
+}
+
+// ListAdminUnits returns one keyset page of the units matching q, plus the total number of matches.
+// Names sort by code point (COLLATE "C") so the order agrees with the memory backend.
+func (r *Repository) ListAdminUnits(ctx context.Context, q models.UnitQuery) ([]models.AdminUnit, int, error) {
+	var where []string
+	var args []interface{}
+	arg := func(v interface{}) string {
+		args = append(args, v)
+		return "$" + strconv.Itoa(len(args))
+	}
+
+	if !q.IncludeRetired {
+		where = append(where, "retired_at IS NULL")
+	}
+	if q.ProvinceID != 0 {
+		where = append(where, "province_id = "+arg(q.ProvinceID))
+	}
+	if q.Level != "" {
+		where = append(where, "level_code = "+arg(q.Level))
+	}
+	if q.Name != "" {
+		where = append(where, "strpos(vn_fold(name), "+arg(foldName(q.Name))+") > 0")
+	}
+	if q.HasCoordinates != nil {
+		cond := "(lat <> 0 OR long <> 0)"
+		if !*q.HasCoordinates {
+			cond = "NOT " + cond
+		}
+		where = append(where, cond)
+	}
+	if !q.UpdatedSince.IsZero() {
+		// updated_at is a UTC timestamp without time zone
+		where = append(where, "updated_at > "+arg(q.UpdatedSince.UTC().Format("2006-01-02 15:04:05.999999"))+"::timestamp")
+	}
+
+	filter := ""
+	if len(where) > 0 {
+		filter = " WHERE " + strings.Join(where, " AND ")
+	}
+	var total int
+	if err := r.db.QueryRowContext(ctx, "SELECT COUNT(*) FROM admin_units"+filter, args...).Scan(&total); err != nil {
+		return nil, 0, err
+	}
+
+	key := "id"
+	switch q.Sort {
+	case models.UnitSortName:
+		key = `name COLLATE "C"`
+	case models.UnitSortUpdatedAt:
+		key = "updated_at"
+	}
+	dir, cmp := "ASC", ">"
+	if q.Desc {
+		dir, cmp = "DESC", "<"
+	}
+	if c := q.After; c != nil {
+		switch q.Sort {
+		case models.UnitSortName:
+			where = append(where, fmt.Sprintf("(%s, id) %s (%s, %s)", key, cmp, arg(c.Name), arg(c.ID)))
+		case models.UnitSortUpdatedAt:
+			where = append(where, fmt.Sprintf("(updated_at, id) %s (%s::timestamp, %s)",
+				cmp, arg(c.UpdatedAt.UTC().Format("2006-01-02 15:04:05.999999")), arg(c.ID)))
+		default:
+			where = append(where, "id "+cmp+" "+arg(c.ID))
+		}
+		filter = " WHERE " + strings.Join(where, " AND ")
+	}
+
+	order := key + " " + dir
+	if key != "id" {
+		order += ", id " + dir
+	}
+	rows, err := r.db.QueryContext(ctx,
+		"SELECT "+unitColumns+" FROM admin_units"+filter+" ORDER BY "+order+" LIMIT "+arg(q.Limit),
+		args...)
+	if err != nil {
+		return nil, 0, err
+	}
+	units, err := scanUnits(rows)
+	if err != nil {
+		return nil, 0, err
+	}
+	return units, total, nil
+}
+
+// GetAdminUnit returns a single unit, retired or not, or ErrNotFound
+func (r *Repository) GetAdminUnit(ctx context.Context, id int) (*models.AdminUnit, error) {
+	rows, err := r.db.QueryContext(ctx, "SELECT "+unitColumns+" FROM admin_units WHERE id = $1", id)
+	if err != nil {
+		return nil, err
+	}
+	units, err := scanUnits(rows)
+	if err != nil {
+		return nil, err
+	}
+	if len(units) == 0 {
+		return nil, ErrNotFound
+	}
+	return &units[0], nil
+}
+
+// GetAdminUnitByCode returns the unit with the given code (ma), ignoring leading zeros, or ErrNotFound.
+// A live unit wins over a retired one that had the same code.
+func (r *Repository) GetAdminUnitByCode(ctx context.Context, code string) (*models.AdminUnit, error) {
+	rows, err := r.db.QueryContext(ctx,
+		"SELECT "+unitColumns+" FROM admin_units WHERE ltrim(code, '0') = ltrim($1, '0') ORDER BY retired_at IS NOT NULL, id LIMIT 1", code)
+	if err != nil {
+		return nil, err
+	}
+	units, err := scanUnits(rows)
+	if err != nil {
+		return nil, err
+	}
+	if len(units) == 0 {
+		return nil, ErrNotFound
+	}
+	return &units[0], nil
+}
+
+// ReplacePredecessors stores the parsed lineage of a unit, replacing any previous rows
+func (r *Repository) ReplacePredecessors(ctx context.Context, unitID int, preds []models.Predecessor) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, "DELETE FROM unit_predecessors WHERE unit_id = $1", unitID); err != nil {
+		return fmt.Errorf("failed to clear predecessors of unit %d: %w", unitID, err)
+	}
+
+	query := `
+		INSERT INTO unit_predecessors (unit_id, seq, old_name, old_level, old_district, old_province, partial)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)`
+	for i, p := range preds {
+		if _, err := tx.ExecContext(ctx, query,
+			unitID, i, p.Name, p.Level, nullString(p.District), nullString(p.Province), p.Partial); err != nil {
+			return fmt.Errorf("failed to insert predecessor of unit %d: %w", unitID, err)
+		}
+	}
+	return tx.Commit()
+}
+
+// GetPredecessors returns the pre-merger units of a unit in description order
+func (r *Repository) GetPredecessors(ctx context.Context, unitID int) ([]models.Predecessor, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT unit_id, old_name, old_level, old_district, old_province, partial
+		FROM unit_predecessors
+		WHERE unit_id = $1
+		ORDER BY seq`, unitID)
+	if err != nil {
+		return nil, err
+	}
+	return scanPredecessors(rows)
+}
+
+// FindPredecessorsByName returns predecessor rows of live units whose old name equals name, ignoring
+// case and diacritics
+func (r *Repository) FindPredecessorsByName(ctx context.Context, name string) ([]models.Predecessor, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT p.unit_id, p.old_name, p.old_level, p.old_district, p.old_province, p.partial
+		FROM unit_predecessors p
+		JOIN admin_units u ON u.id = p.unit_id
+		WHERE vn_fold(p.old_name) = $1 AND u.retired_at IS NULL
+		ORDER BY p.unit_id, p.seq`, foldName(name))
+	if err != nil {
+		return nil, err
+	}
+	return scanPredecessors(rows)
+}
+
+// foldName normalizes a name the way vn_fold() does, with whitespace collapsed
+func foldName(name string) string {
+	return vntext.Fold(strings.Join(strings.Fields(name), " "))
+}
+
+func scanPredecessors(rows *sql.Rows) ([]models.Predecessor, error) {
 	defer rows.Close()
 
-	units := make([]models.AdminUnit, 0)
+	preds := make([]models.Predecessor, 0)
 	for rows.Next() {
-		var u models.AdminUnit
-		var preMerger sql.NullString
-
-		if err := rows.Scan(&u.ID, &u.ProvinceID, &u.Name, &u.Level, &u.Code, &preMerger, &u.Lat, &u.Long, &u.UpdatedAt); err != nil {
+		var p models.Predecessor
+		var level, district, province sql.NullString
+		if err := rows.Scan(&p.UnitID, &p.Name, &level, &district, &province, &p.Partial); err != nil {
 			return nil, err
 		}
-		if preMerger.Valid {
-			u.PreMergerDesc = preMerger.String
-		}
-		units = append(units, u)
+		p.Level, p.District, p.Province = level.String, district.String, province.String
+		preds = append(preds, p)
 	}
-	return units, nil
+	return preds, rows.Err()
 }
 
-// SearchAdminUnits searches for units by name or pre-merger description
-func (r *Repository) SearchAdminUnits(ctx context.Context, query string) ([]models.AdminUnit, error) {
-	// Simple ILIKE search. For better performance with large data, consider Full Text Search (tsvector).
+func nullString(s string) sql.NullString {
+	return sql.NullString{String: s, Valid: s != ""}
+}
+
+// SearchAdminUnits runs an accent-insensitive full-text search over name and pre-merger description,
+// plus trigram matches on the name when params.Fuzzy is set. The GIN indexes narrow candidates;
+// search.Rank applies the shared scoring. Retired units only match with params.IncludeRetired.
+func (r *Repository) SearchAdminUnits(ctx context.Context, params models.SearchParams) ([]models.SearchResult, error) {
+	matcher := search.NewMatcher(params.Query)
+	if matcher.Empty() {
+		return []models.SearchResult{}, nil
+	}
+
 	sqlQuery := `
-		SELECT id, province_id, name, level, code, pre_merger_desc, lat, long, updated_at 
-		FROM admin_units 
-		WHERE name ILIKE '%' || $1 || '%' OR pre_merger_desc ILIKE '%' || $1 || '%' 
-		ORDER BY id 
-		LIMIT 50`
+		SELECT ` + unitColumns + `
+		FROM admin_units
+		WHERE (search_vector @@ to_tsquery('simple', $1)
+		   OR ($3 AND vn_fold(name) % $4))
+		  AND ($5 = '' OR level_code = $5)
+		  AND ($6 OR retired_at IS NULL)
+		ORDER BY ts_rank(search_vector, to_tsquery('simple', $1)) DESC, similarity(vn_fold(name), $4) DESC, id
+		LIMIT $2`
 
-	rows, err := r.db.QueryContext(ctx, sqlQuery, query)
+	var candidates []models.AdminUnit
+	err := r.withSimilarityThreshold(ctx, params.MinScore, func(tx *sql.Tx) error {
+		rows, err := tx.QueryContext(ctx, sqlQuery,
+			prefixTSQuery(matcher.Tokens()), search.MaxCandidates, params.Fuzzy, matcher.Query(), params.Level, params.IncludeRetired)
+		if err != nil {
+			return err
+		}
+		candidates, err = scanUnits(rows)
+		return err
+	})
 	if err != nil {
 		return nil, err
 	}
+	return search.Rank(matcher, candidates, params), nil
+}
+
+// SuggestUnitName returns the live unit name most similar to query ("did you mean"), or "" if none is close
+func (r *Repository) SuggestUnitName(ctx context.Context, query string) (string, error) {
+	matcher := search.NewMatcher(query)
+	if matcher.Empty() {
+		return "", nil
+	}
+
+	sqlQuery := `
+		SELECT ` + unitColumns + `
+		FROM admin_units
+		WHERE vn_fold(name) % $1 AND retired_at IS NULL
+		ORDER BY similarity(vn_fold(name), $1) DESC, id
+		LIMIT 10`
+
+	var candidates []models.AdminUnit
+	err := r.withSimilarityThreshold(ctx, search.DefaultMinScore, func(tx *sql.Tx) error {
+		rows, err := tx.QueryContext(ctx, sqlQuery, matcher.Query())
+		if err != nil {
+			return err
+		}
+		candidates, err = scanUnits(rows)
+		return err
+	})
+	if err != nil {
+		return "", err
+	}
+	name, _ := search.Suggest(matcher, candidates)
+	return name, nil
+}
+
+// withSimilarityThreshold runs fn in a read-only transaction where the pg_trgm % operator
+// (which can use the trigram index, unlike similarity() >= x) uses the given threshold
+func (r *Repository) withSimilarityThreshold(ctx context.Context, threshold float64, fn func(tx *sql.Tx) error) error {
+	tx, err := r.db.BeginTx(ctx, &sql.TxOptions{ReadOnly: true})
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if threshold <= 0 {
+		threshold = search.DefaultMinScore
+	}
+	if _, err := tx.ExecContext(ctx,
+		"SELECT set_config('pg_trgm.similarity_threshold', $1, true)",
+		strconv.FormatFloat(threshold, 'f', -1, 64)); err != nil {
+		return err
+	}
+	if err := fn(tx); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// prefixTSQuery builds "tok1:* & tok2:*". Tokens come from vntext.Tokens, so they
+// contain only letters and digits and need no further escaping.
+func prefixTSQuery(tokens []string) string {
+	parts := make([]string, len(tokens))
+	for i, t := range tokens {
+		parts[i] = t + ":*"
+	}
+	return strings.Join(parts, " & ")
+}
+
+// unitColumns is the column list matching scanUnits
+const unitColumns = "id, province_id, name, level, code, pre_merger_desc, lat, long, updated_at, level_code, gso_code, retired_at"
+
+// scanUnits reads rows selected with unitColumns and closes them
+func scanUnits(rows *sql.Rows) ([]models.AdminUnit, error) {
 	defer rows.Close()
 
 	units := make([]models.AdminUnit, 0)
 	for rows.Next() {
 		var u models.AdminUnit
-		var preMerger sql.NullString
+		var preMerger, levelCode, gsoCode sql.NullString
+		var retiredAt sql.NullTime
 
-		if err := rows.Scan(&u.ID, &u.ProvinceID, &u.Name, &u.Level, &u.Code, &preMerger, &u.Lat, &u.Long, &u.UpdatedAt); err != nil {
+		if err := rows.Scan(&u.ID, &u.ProvinceID, &u.Name, &u.Level, &u.Code, &preMerger, &u.Lat, &u.Long, &u.UpdatedAt, &levelCode, &gsoCode, &retiredAt); err != nil {
 			return nil, err
 		}
+		if retiredAt.Valid {
+			u.RetiredAt = &retiredAt.Time
+		}
 		if preMerger.Valid {
 			u.PreMergerDesc = preMerger.String
 		}
+		u.LevelCode, u.GSOCode = levelCode.String, gsoCode.String
 		units = append(units, u)
 	}
-	return units, nil
+	return units, rows.Err()
 }
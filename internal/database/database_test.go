@@ -0,0 +1,106 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"testing"
+	"time"
+
+	"vn-admin-api/internal/config"
+	"vn-admin-api/internal/models"
+
+	"github.com/lib/pq"
+)
+
+// testRepository connects to the disposable database named by TEST_DB_HOST, TEST_DB_PORT,
+// TEST_DB_USER, TEST_DB_PASSWORD and TEST_DB_NAME, migrated to the latest version. The role's
+// default time zone is moved off UTC for the test, as on a server set up in Vietnam.
+func testRepository(t *testing.T) *Repository {
+	t.Helper()
+	if os.Getenv("TEST_DB_HOST") == "" {
+		t.Skip("TEST_DB_HOST is not set")
+	}
+	cfg := &config.Config{
+		DBHost:     os.Getenv("TEST_DB_HOST"),
+		DBPort:     os.Getenv("TEST_DB_PORT"),
+		DBUser:     os.Getenv("TEST_DB_USER"),
+		DBPassword: os.Getenv("TEST_DB_PASSWORD"),
+		DBName:     os.Getenv("TEST_DB_NAME"),
+		DBSSLMode:  "disable",
+	}
+	if cfg.DBPort == "" {
+		cfg.DBPort = "5432"
+	}
+
+	admin, err := Connect(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := admin.db.Exec("ALTER ROLE CURRENT_USER SET timezone = 'Asia/Ho_Chi_Minh'"); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		_, _ = admin.db.Exec("ALTER ROLE CURRENT_USER RESET timezone")
+		admin.Close()
+	})
+
+	// New sessions start in the role's zone unless Connect overrides it
+	repo, err := Connect(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { repo.Close() })
+	if _, err := repo.MigrateUp(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	return repo
+}
+
+func TestConnString_PinsUTC(t *testing.T) {
+	cfg := &config.Config{DBHost: "db", DBPort: "5432", DBUser: "u", DBPassword: "p", DBName: "vn_admin", DBSSLMode: "disable"}
+	pc, err := pq.NewConfig(connString(cfg))
+	if err != nil {
+		t.Fatalf("NewConfig: %v", err)
+	}
+	if tz := pc.Runtime["timezone"]; tz != "UTC" {
+		t.Errorf("Expected sessions to start in UTC, got time zone %q", tz)
+	}
+}
+
+func TestGetProvincesAsOf_NonUTCRole(t *testing.T) {
+	ctx := context.Background()
+	repo := testRepository(t)
+
+	var tz string
+	if err := repo.db.QueryRowContext(ctx, "SHOW TIME ZONE").Scan(&tz); err != nil {
+		t.Fatal(err)
+	}
+	if tz != "UTC" {
+		t.Fatalf("Expected a UTC session, got %q", tz)
+	}
+
+	// The trigger stamps the new version with NOW(); it must line up with as_of instants
+	name := fmt.Sprintf("Tỉnh Kiểm Thử %d", time.Now().UnixNano())
+	if err := repo.UpsertProvince(ctx, models.Province{ID: 990001, Name: name, Code: 9901}); err != nil {
+		t.Fatal(err)
+	}
+	asOf := func(at time.Time) string {
+		provinces, err := repo.GetProvincesAsOf(ctx, at)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, p := range provinces {
+			if p.ID == 990001 {
+				return p.Name
+			}
+		}
+		return ""
+	}
+	if got := asOf(time.Now().Add(time.Second)); got != name {
+		t.Errorf("Expected the new version as of now, got %q", got)
+	}
+	if got := asOf(time.Now().Add(-time.Minute)); got == name {
+		t.Errorf("Expected the new version to be missing a minute ago")
+	}
+}
@@ -1,6 +1,8 @@
 package database
 
-import _ "embed"
+import "embed"
 
-//go:embed schema.sql
-var SchemaSQL string
+// migrationFS holds the ordered up/down migrations (NNNN_name.up.sql / NNNN_name.down.sql)
+//
+//go:embed migrations/*.sql
+var migrationFS embed.FS
@@ -0,0 +1,105 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"vn-admin-api/internal/models"
+)
+
+// externalIDKeys maps a record kind to its unit_external_ids column
+var externalIDKeys = map[string]string{
+	models.RefKindProvince: "province_id",
+	models.RefKindUnit:     "unit_id",
+}
+
+// SaveExternalIDs upserts the IDs of one system in a single transaction. With replace, the
+// system's existing IDs are deleted first, so the result mirrors ids exactly.
+func (r *Repository) SaveExternalIDs(ctx context.Context, system string, ids []models.ExternalID, replace bool) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if replace {
+		if _, err := tx.ExecContext(ctx, "DELETE FROM unit_external_ids WHERE system = $1", system); err != nil {
+			return fmt.Errorf("failed to clear %s external IDs: %w", system, err)
+		}
+	}
+	stmt, err := tx.PrepareContext(ctx, `
+		INSERT INTO unit_external_ids (system, external_id, unit_id, province_id, updated_at)
+		VALUES ($1, $2, $3, $4, NOW())
+		ON CONFLICT (system, external_id)
+		DO UPDATE SET unit_id = $3, province_id = $4, updated_at = NOW()`)
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+
+	for _, id := range ids {
+		if id.System != system {
+			return fmt.Errorf("external ID %q belongs to system %q, not %q", id.ExternalID, id.System, system)
+		}
+		var unitID, provinceID sql.NullInt64
+		switch id.Kind {
+		case models.RefKindUnit:
+			unitID = sql.NullInt64{Int64: int64(id.RefID), Valid: true}
+		case models.RefKindProvince:
+			provinceID = sql.NullInt64{Int64: int64(id.RefID), Valid: true}
+		default:
+			return fmt.Errorf("unknown record kind %q", id.Kind)
+		}
+		if _, err := stmt.ExecContext(ctx, system, id.ExternalID, unitID, provinceID); err != nil {
+			return fmt.Errorf("failed to save %s external ID %q: %w", system, id.ExternalID, err)
+		}
+	}
+	return tx.Commit()
+}
+
+// GetExternalIDs returns the external IDs of a province or unit, ordered by system and ID
+func (r *Repository) GetExternalIDs(ctx context.Context, kind string, refID int) ([]models.ExternalID, error) {
+	key, ok := externalIDKeys[kind]
+	if !ok {
+		return nil, fmt.Errorf("unknown record kind %q", kind)
+	}
+	rows, err := r.db.QueryContext(ctx,
+		"SELECT system, external_id FROM unit_external_ids WHERE "+key+" = $1 ORDER BY system, external_id",
+		refID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	ids := make([]models.ExternalID, 0)
+	for rows.Next() {
+		id := models.ExternalID{Kind: kind, RefID: refID}
+		if err := rows.Scan(&id.System, &id.ExternalID); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	return ids, rows.Err()
+}
+
+// GetExternalID resolves an external ID of a system, or returns ErrNotFound
+func (r *Repository) GetExternalID(ctx context.Context, system, externalID string) (*models.ExternalID, error) {
+	var unitID, provinceID sql.NullInt64
+	err := r.db.QueryRowContext(ctx,
+		"SELECT unit_id, province_id FROM unit_external_ids WHERE system = $1 AND external_id = $2",
+		system, externalID).Scan(&unitID, &provinceID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	id := models.ExternalID{System: system, ExternalID: externalID, Kind: models.RefKindUnit, RefID: int(unitID.Int64)}
+	if provinceID.Valid {
+		id.Kind, id.RefID = models.RefKindProvince, int(provinceID.Int64)
+	}
+	return &id, nil
+}
@@ -0,0 +1,157 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"vn-admin-api/internal/models"
+)
+
+const jobColumns = "id, status, format, total, processed, matched, error, created_at, started_at, finished_at, attempt"
+
+// CreateJob stores a new queued job together with its input file
+func (r *Repository) CreateJob(ctx context.Context, job models.Job, input []byte) error {
+	query := `
+		INSERT INTO jobs (id, status, format, total, input, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6)`
+
+	_, err := r.db.ExecContext(ctx, query, job.ID, job.Status, job.Format, job.Total, input, job.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to create job %s: %w", job.ID, err)
+	}
+	return nil
+}
+
+// ClaimJob marks the oldest queued job (or a running job whose heartbeat is older than staleAfter)
+// as running and returns it with its input. SKIP LOCKED lets several replicas claim concurrently.
+// A stale job already claimed maxAttempts times is failed instead: its input keeps crashing or
+// stalling workers. Returns ErrNotFound when there is nothing to do.
+func (r *Repository) ClaimJob(ctx context.Context, staleAfter time.Duration, maxAttempts int) (*models.Job, []byte, error) {
+	if _, err := r.db.ExecContext(ctx, `
+		UPDATE jobs SET status = 'failed', error = $3, finished_at = NOW()
+		WHERE status = 'running' AND heartbeat_at < NOW() - make_interval(secs => $1) AND attempt >= $2`,
+		staleAfter.Seconds(), maxAttempts, AbandonedJobError(maxAttempts)); err != nil {
+		return nil, nil, fmt.Errorf("failed to fail abandoned jobs: %w", err)
+	}
+
+	query := `
+		UPDATE jobs SET status = 'running', processed = 0, matched = 0, attempt = attempt + 1,
+			started_at = NOW(), heartbeat_at = NOW()
+		WHERE id = (
+			SELECT id FROM jobs
+			WHERE status = 'queued'
+			   OR (status = 'running' AND heartbeat_at < NOW() - make_interval(secs => $1))
+			ORDER BY created_at
+			FOR UPDATE SKIP LOCKED
+			LIMIT 1
+		)
+		RETURNING ` + jobColumns + `, input`
+
+	var input []byte
+	job, err := scanJob(r.db.QueryRowContext(ctx, query, staleAfter.Seconds()), &input)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to claim job: %w", err)
+	}
+	return job, input, nil
+}
+
+// AbandonedJobError is the error message of a job failed after stalling maxAttempts workers
+func AbandonedJobError(maxAttempts int) string {
+	return fmt.Sprintf("abandoned after %d attempts: the worker stopped responding each time", maxAttempts)
+}
+
+// The updates below only apply to a running job still held by the claim that returned attempt;
+// otherwise they return ErrJobLost.
+
+// UpdateJobProgress records progress and refreshes the job heartbeat
+func (r *Repository) UpdateJobProgress(ctx context.Context, id string, attempt, processed, matched int) error {
+	return r.updateJob(ctx, "update", `
+		UPDATE jobs SET processed = $3, matched = $4, heartbeat_at = NOW()
+		WHERE id = $1 AND attempt = $2 AND status = 'running'`,
+		id, attempt, processed, matched)
+}
+
+// CompleteJob stores the result file and marks the job completed
+func (r *Repository) CompleteJob(ctx context.Context, id string, attempt, processed, matched int, result []byte) error {
+	return r.updateJob(ctx, "complete", `
+		UPDATE jobs SET status = 'completed', processed = $3, matched = $4, result = $5,
+			finished_at = NOW(), heartbeat_at = NOW()
+		WHERE id = $1 AND attempt = $2 AND status = 'running'`,
+		id, attempt, processed, matched, result)
+}
+
+// FailJob marks the job failed with an error message
+func (r *Repository) FailJob(ctx context.Context, id string, attempt int, message string) error {
+	return r.updateJob(ctx, "fail", `
+		UPDATE jobs SET status = 'failed', error = $3, finished_at = NOW()
+		WHERE id = $1 AND attempt = $2 AND status = 'running'`,
+		id, attempt, message)
+}
+
+// ReleaseJob puts a running job back in the queue (used on graceful shutdown)
+func (r *Repository) ReleaseJob(ctx context.Context, id string, attempt int) error {
+	return r.updateJob(ctx, "release", `
+		UPDATE jobs SET status = 'queued', processed = 0, matched = 0
+		WHERE id = $1 AND attempt = $2 AND status = 'running'`,
+		id, attempt)
+}
+
+// updateJob runs a job update whose first two arguments are the job ID and attempt
+func (r *Repository) updateJob(ctx context.Context, action, query string, args ...any) error {
+	res, err := r.db.ExecContext(ctx, query, args...)
+	if err != nil {
+		return fmt.Errorf("failed to %s job %s: %w", action, args[0], err)
+	}
+	if n, err := res.RowsAffected(); err != nil {
+		return err
+	} else if n == 0 {
+		return fmt.Errorf("failed to %s job %s: %w", action, args[0], ErrJobLost)
+	}
+	return nil
+}
+
+// GetJob returns a job without its input/result payloads, or ErrNotFound
+func (r *Repository) GetJob(ctx context.Context, id string) (*models.Job, error) {
+	job, err := scanJob(r.db.QueryRowContext(ctx, "SELECT "+jobColumns+" FROM jobs WHERE id = $1", id))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrNotFound
+	}
+	return job, err
+}
+
+// GetJobResult returns the result file of a completed job, or ErrNotFound
+func (r *Repository) GetJobResult(ctx context.Context, id string) ([]byte, error) {
+	var result []byte
+	err := r.db.QueryRowContext(ctx, "SELECT result FROM jobs WHERE id = $1 AND status = 'completed'", id).Scan(&result)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrNotFound
+	}
+	return result, err
+}
+
+// scanJob reads a row selected with jobColumns, plus any extra trailing columns
+func scanJob(row *sql.Row, extra ...any) (*models.Job, error) {
+	var j models.Job
+	var errMsg sql.NullString
+	var started, finished sql.NullTime
+
+	dest := append([]any{&j.ID, &j.Status, &j.Format, &j.Total, &j.Processed, &j.Matched,
+		&errMsg, &j.CreatedAt, &started, &finished, &j.Attempt}, extra...)
+	if err := row.Scan(dest...); err != nil {
+		return nil, err
+	}
+	j.Error = errMsg.String
+	if started.Valid {
+		j.StartedAt = &started.Time
+	}
+	if finished.Valid {
+		j.FinishedAt = &finished.Time
+	}
+	return &j, nil
+}
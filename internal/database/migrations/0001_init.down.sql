@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS admin_units;
+DROP TABLE IF EXISTS provinces;
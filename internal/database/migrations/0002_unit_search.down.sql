@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS idx_admin_units_search;
+ALTER TABLE admin_units DROP COLUMN IF EXISTS search_vector;
+DROP FUNCTION IF EXISTS vn_fold(TEXT);
+CREATE INDEX IF NOT EXISTS idx_admin_units_pre_merger ON admin_units(pre_merger_desc);
@@ -0,0 +1,21 @@
+-- Accent-folding used by the search index. Must stay in sync with vntext.Fold:
+-- strip Vietnamese diacritics (precomposed or combining), map đ -> d, lowercase.
+CREATE OR REPLACE FUNCTION vn_fold(input TEXT) RETURNS TEXT AS $$
+    SELECT lower(regexp_replace(
+        translate(input,
+            'àÀáÁảẢãÃạẠăĂằẰắẮẳẲẵẴặẶâÂầẦấẤẩẨẫẪậẬèÈéÉẻẺẽẼẹẸêÊềỀếẾểỂễỄệỆìÌíÍỉỈĩĨịỊòÒóÓỏỎõÕọỌôÔồỒốỐổỔỗỖộỘơƠờỜớỚởỞỡỠợỢùÙúÚủỦũŨụỤưƯừỪứỨửỬữỮựỰỳỲýÝỷỶỹỸỵỴđĐ',
+            'aaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaeeeeeeeeeeeeeeeeeeeeeeiiiiiiiiiioooooooooooooooooooooooooooooooooouuuuuuuuuuuuuuuuuuuuuuyyyyyyyyyydd'),
+        E'[\u0300-\u036F]', '', 'g'))
+$$ LANGUAGE SQL IMMUTABLE STRICT PARALLEL SAFE;
+
+-- Weighted, accent-folded token index: name (A) ranks above pre-merger description (B)
+ALTER TABLE admin_units ADD COLUMN IF NOT EXISTS search_vector tsvector
+    GENERATED ALWAYS AS (
+        setweight(to_tsvector('simple', coalesce(vn_fold(name), '')), 'A') ||
+        setweight(to_tsvector('simple', coalesce(vn_fold(pre_merger_desc), '')), 'B')
+    ) STORED;
+
+CREATE INDEX IF NOT EXISTS idx_admin_units_search ON admin_units USING GIN (search_vector);
+
+-- Superseded by the GIN index (a btree on free text never served ILIKE '%q%')
+DROP INDEX IF EXISTS idx_admin_units_pre_merger;
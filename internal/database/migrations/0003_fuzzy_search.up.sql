@@ -0,0 +1,4 @@
+-- Trigram similarity for typo-tolerant search ("Hoan Kiemm" -> "Hoàn Kiếm")
+CREATE EXTENSION IF NOT EXISTS pg_trgm;
+
+CREATE INDEX IF NOT EXISTS idx_admin_units_name_trgm ON admin_units USING GIN (vn_fold(name) gin_trgm_ops);
@@ -0,0 +1,13 @@
+-- Structured pre-merger lineage parsed from admin_units.pre_merger_desc (truocsapnhap)
+CREATE TABLE IF NOT EXISTS unit_predecessors (
+    unit_id INT NOT NULL REFERENCES admin_units(id) ON DELETE CASCADE,
+    seq INT NOT NULL,
+    old_name TEXT NOT NULL,
+    old_level TEXT,
+    old_district TEXT,
+    old_province TEXT,
+    partial BOOLEAN NOT NULL DEFAULT FALSE,
+    PRIMARY KEY (unit_id, seq)
+);
+
+CREATE INDEX IF NOT EXISTS idx_unit_predecessors_old_name ON unit_predecessors(vn_fold(old_name));
@@ -0,0 +1,19 @@
+-- Bulk address-conversion jobs. Input and result are kept here so jobs survive restarts;
+-- a running job whose heartbeat goes stale is picked up again by another worker.
+CREATE TABLE IF NOT EXISTS jobs (
+    id TEXT PRIMARY KEY,
+    status TEXT NOT NULL,
+    format TEXT NOT NULL,
+    total INT NOT NULL DEFAULT 0,
+    processed INT NOT NULL DEFAULT 0,
+    matched INT NOT NULL DEFAULT 0,
+    error TEXT,
+    input BYTEA NOT NULL,
+    result BYTEA,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    started_at TIMESTAMP,
+    finished_at TIMESTAMP,
+    heartbeat_at TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_jobs_status ON jobs(status, created_at);
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS unit_boundaries;
+DROP TABLE IF EXISTS province_boundaries;
@@ -0,0 +1,13 @@
+-- Province and unit outlines imported from GeoJSON (WGS84 Polygon / MultiPolygon geometry objects).
+-- Containment is tested in Go, so no PostGIS is required.
+CREATE TABLE IF NOT EXISTS province_boundaries (
+    province_id INT PRIMARY KEY REFERENCES provinces(id) ON DELETE CASCADE,
+    geometry JSONB NOT NULL,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE IF NOT EXISTS unit_boundaries (
+    unit_id INT PRIMARY KEY REFERENCES admin_units(id) ON DELETE CASCADE,
+    geometry JSONB NOT NULL,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
@@ -0,0 +1 @@
+DROP INDEX IF EXISTS idx_admin_units_code;
@@ -0,0 +1,2 @@
+-- Lookup by unit code (ma) ignoring leading zeros, so "00004" and "4" find the same unit
+CREATE INDEX IF NOT EXISTS idx_admin_units_code ON admin_units (ltrim(code, '0'));
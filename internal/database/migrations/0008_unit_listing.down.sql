@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_admin_units_updated_id;
+DROP INDEX IF EXISTS idx_admin_units_name_id;
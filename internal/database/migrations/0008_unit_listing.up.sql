@@ -0,0 +1,3 @@
+-- Keyset pagination of GET /api/v1/units by name and by updated_at
+CREATE INDEX IF NOT EXISTS idx_admin_units_name_id ON admin_units ((name COLLATE "C"), id);
+CREATE INDEX IF NOT EXISTS idx_admin_units_updated_id ON admin_units (updated_at, id);
@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_admin_units_level_code;
+ALTER TABLE admin_units DROP COLUMN IF EXISTS level_code;
+DROP TABLE IF EXISTS unit_levels;
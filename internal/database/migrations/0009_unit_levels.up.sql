@@ -0,0 +1,28 @@
+-- Controlled level taxonomy; mirrors internal/levels
+CREATE TABLE IF NOT EXISTS unit_levels (
+    code TEXT PRIMARY KEY,
+    name_vi TEXT NOT NULL UNIQUE,
+    name_en TEXT NOT NULL
+);
+
+INSERT INTO unit_levels (code, name_vi, name_en) VALUES
+    ('municipality', 'Thành phố trực thuộc trung ương', 'Municipality'),
+    ('province', 'Tỉnh', 'Province'),
+    ('city', 'Thành phố', 'City'),
+    ('town', 'Thị xã', 'Town'),
+    ('urban_district', 'Quận', 'Urban district'),
+    ('district', 'Huyện', 'District'),
+    ('ward', 'Phường', 'Ward'),
+    ('commune', 'Xã', 'Commune'),
+    ('township', 'Thị trấn', 'Township'),
+    ('special_zone', 'Đặc khu', 'Special zone')
+ON CONFLICT (code) DO UPDATE SET name_vi = EXCLUDED.name_vi, name_en = EXCLUDED.name_en;
+
+-- level keeps the upstream label; level_code is NULL when the label is unknown
+ALTER TABLE admin_units ADD COLUMN IF NOT EXISTS level_code TEXT REFERENCES unit_levels(code);
+
+UPDATE admin_units u SET level_code = l.code
+FROM unit_levels l
+WHERE u.level_code IS NULL AND vn_fold(btrim(u.level)) = vn_fold(l.name_vi);
+
+CREATE INDEX IF NOT EXISTS idx_admin_units_level_code ON admin_units (level_code);
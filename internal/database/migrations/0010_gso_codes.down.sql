@@ -0,0 +1,2 @@
+ALTER TABLE admin_units DROP COLUMN IF EXISTS gso_code;
+ALTER TABLE provinces DROP COLUMN IF EXISTS gso_code;
@@ -0,0 +1,3 @@
+-- Official GSO codes from the crosswalk import; the upstream codes stay in code
+ALTER TABLE provinces ADD COLUMN IF NOT EXISTS gso_code TEXT;
+ALTER TABLE admin_units ADD COLUMN IF NOT EXISTS gso_code TEXT;
@@ -0,0 +1,14 @@
+-- Crosswalk to identifiers of outside systems (shipping carriers, ...). Each row links to
+-- exactly one unit or province; several external IDs may link to the same record.
+CREATE TABLE IF NOT EXISTS unit_external_ids (
+    system TEXT NOT NULL,
+    external_id TEXT NOT NULL,
+    unit_id INT REFERENCES admin_units(id) ON DELETE CASCADE,
+    province_id INT REFERENCES provinces(id) ON DELETE CASCADE,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (system, external_id),
+    CHECK ((unit_id IS NULL) <> (province_id IS NULL))
+);
+
+CREATE INDEX IF NOT EXISTS idx_unit_external_ids_unit ON unit_external_ids (unit_id) WHERE unit_id IS NOT NULL;
+CREATE INDEX IF NOT EXISTS idx_unit_external_ids_province ON unit_external_ids (province_id) WHERE province_id IS NOT NULL;
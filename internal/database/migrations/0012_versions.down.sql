@@ -0,0 +1,6 @@
+DROP TRIGGER IF EXISTS admin_units_versions ON admin_units;
+DROP TRIGGER IF EXISTS provinces_versions ON provinces;
+DROP FUNCTION IF EXISTS admin_unit_versions_track();
+DROP FUNCTION IF EXISTS province_versions_track();
+DROP TABLE IF EXISTS admin_unit_versions;
+DROP TABLE IF EXISTS province_versions;
@@ -0,0 +1,85 @@
+-- Temporal history: every change to a province or unit closes its open version (valid_to)
+-- and opens a new one. Versions are valid on [valid_from, valid_to); valid_to is NULL while current.
+CREATE TABLE IF NOT EXISTS province_versions (
+    province_id INT NOT NULL REFERENCES provinces(id) ON DELETE CASCADE,
+    name TEXT NOT NULL,
+    code TEXT,
+    gso_code TEXT,
+    valid_from TIMESTAMP NOT NULL,
+    valid_to TIMESTAMP,
+    PRIMARY KEY (province_id, valid_from)
+);
+
+CREATE TABLE IF NOT EXISTS admin_unit_versions (
+    unit_id INT NOT NULL REFERENCES admin_units(id) ON DELETE CASCADE,
+    province_id INT NOT NULL,
+    name TEXT NOT NULL,
+    level TEXT,
+    level_code TEXT,
+    code TEXT,
+    gso_code TEXT,
+    pre_merger_desc TEXT,
+    lat FLOAT,
+    long FLOAT,
+    valid_from TIMESTAMP NOT NULL,
+    valid_to TIMESTAMP,
+    PRIMARY KEY (unit_id, valid_from)
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_province_versions_current ON province_versions (province_id) WHERE valid_to IS NULL;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_admin_unit_versions_current ON admin_unit_versions (unit_id) WHERE valid_to IS NULL;
+CREATE INDEX IF NOT EXISTS idx_admin_unit_versions_period ON admin_unit_versions (valid_from, valid_to);
+
+-- History starts with the rows as they are now
+INSERT INTO province_versions (province_id, name, code, gso_code, valid_from)
+SELECT id, name, code, gso_code, COALESCE(updated_at, NOW()) FROM provinces
+ON CONFLICT DO NOTHING;
+
+INSERT INTO admin_unit_versions (unit_id, province_id, name, level, level_code, code, gso_code, pre_merger_desc, lat, long, valid_from)
+SELECT id, province_id, name, level, level_code, code, gso_code, pre_merger_desc, lat, long, COALESCE(updated_at, NOW()) FROM admin_units
+ON CONFLICT DO NOTHING;
+
+-- Triggers keep the history for every writer; updates that change no versioned column are ignored.
+-- A second change in the same transaction (same NOW()) rewrites the version it opened.
+CREATE OR REPLACE FUNCTION province_versions_track() RETURNS trigger AS $$
+BEGIN
+    IF TG_OP = 'UPDATE' AND (OLD.name, OLD.code, OLD.gso_code) IS NOT DISTINCT FROM (NEW.name, NEW.code, NEW.gso_code) THEN
+        RETURN NEW;
+    END IF;
+    UPDATE province_versions SET valid_to = NOW()
+    WHERE province_id = NEW.id AND valid_to IS NULL AND valid_from < NOW();
+    INSERT INTO province_versions (province_id, name, code, gso_code, valid_from)
+    VALUES (NEW.id, NEW.name, NEW.code, NEW.gso_code, NOW())
+    ON CONFLICT (province_id, valid_from) DO UPDATE
+    SET name = EXCLUDED.name, code = EXCLUDED.code, gso_code = EXCLUDED.gso_code;
+    RETURN NEW;
+END
+$$ LANGUAGE plpgsql;
+
+CREATE OR REPLACE FUNCTION admin_unit_versions_track() RETURNS trigger AS $$
+BEGIN
+    IF TG_OP = 'UPDATE'
+       AND (OLD.province_id, OLD.name, OLD.level, OLD.level_code, OLD.code, OLD.gso_code, OLD.pre_merger_desc, OLD.lat, OLD.long)
+           IS NOT DISTINCT FROM
+           (NEW.province_id, NEW.name, NEW.level, NEW.level_code, NEW.code, NEW.gso_code, NEW.pre_merger_desc, NEW.lat, NEW.long) THEN
+        RETURN NEW;
+    END IF;
+    UPDATE admin_unit_versions SET valid_to = NOW()
+    WHERE unit_id = NEW.id AND valid_to IS NULL AND valid_from < NOW();
+    INSERT INTO admin_unit_versions (unit_id, province_id, name, level, level_code, code, gso_code, pre_merger_desc, lat, long, valid_from)
+    VALUES (NEW.id, NEW.province_id, NEW.name, NEW.level, NEW.level_code, NEW.code, NEW.gso_code, NEW.pre_merger_desc, NEW.lat, NEW.long, NOW())
+    ON CONFLICT (unit_id, valid_from) DO UPDATE
+    SET province_id = EXCLUDED.province_id, name = EXCLUDED.name, level = EXCLUDED.level, level_code = EXCLUDED.level_code,
+        code = EXCLUDED.code, gso_code = EXCLUDED.gso_code, pre_merger_desc = EXCLUDED.pre_merger_desc,
+        lat = EXCLUDED.lat, long = EXCLUDED.long;
+    RETURN NEW;
+END
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS provinces_versions ON provinces;
+CREATE TRIGGER provinces_versions AFTER INSERT OR UPDATE ON provinces
+    FOR EACH ROW EXECUTE FUNCTION province_versions_track();
+
+DROP TRIGGER IF EXISTS admin_units_versions ON admin_units;
+CREATE TRIGGER admin_units_versions AFTER INSERT OR UPDATE ON admin_units
+    FOR EACH ROW EXECUTE FUNCTION admin_unit_versions_track();
@@ -0,0 +1,16 @@
+-- What each crawl run changed compared with the stored data (see internal/changelog).
+-- ref_id is not a foreign key: removed provinces and units keep their entries.
+CREATE TABLE IF NOT EXISTS change_log (
+    id BIGSERIAL PRIMARY KEY,
+    run_id TEXT NOT NULL,
+    kind TEXT NOT NULL,
+    ref_id INT NOT NULL,
+    province_id INT NOT NULL,
+    name TEXT NOT NULL,
+    old_value TEXT,
+    new_value TEXT,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_change_log_run ON change_log (run_id);
+CREATE INDEX IF NOT EXISTS idx_change_log_created ON change_log (created_at);
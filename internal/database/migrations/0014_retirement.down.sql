@@ -0,0 +1,41 @@
+-- Restore the 0012 version triggers
+CREATE OR REPLACE FUNCTION province_versions_track() RETURNS trigger AS $$
+BEGIN
+    IF TG_OP = 'UPDATE' AND (OLD.name, OLD.code, OLD.gso_code) IS NOT DISTINCT FROM (NEW.name, NEW.code, NEW.gso_code) THEN
+        RETURN NEW;
+    END IF;
+    UPDATE province_versions SET valid_to = NOW()
+    WHERE province_id = NEW.id AND valid_to IS NULL AND valid_from < NOW();
+    INSERT INTO province_versions (province_id, name, code, gso_code, valid_from)
+    VALUES (NEW.id, NEW.name, NEW.code, NEW.gso_code, NOW())
+    ON CONFLICT (province_id, valid_from) DO UPDATE
+    SET name = EXCLUDED.name, code = EXCLUDED.code, gso_code = EXCLUDED.gso_code;
+    RETURN NEW;
+END
+$$ LANGUAGE plpgsql;
+
+CREATE OR REPLACE FUNCTION admin_unit_versions_track() RETURNS trigger AS $$
+BEGIN
+    IF TG_OP = 'UPDATE'
+       AND (OLD.province_id, OLD.name, OLD.level, OLD.level_code, OLD.code, OLD.gso_code, OLD.pre_merger_desc, OLD.lat, OLD.long)
+           IS NOT DISTINCT FROM
+           (NEW.province_id, NEW.name, NEW.level, NEW.level_code, NEW.code, NEW.gso_code, NEW.pre_merger_desc, NEW.lat, NEW.long) THEN
+        RETURN NEW;
+    END IF;
+    UPDATE admin_unit_versions SET valid_to = NOW()
+    WHERE unit_id = NEW.id AND valid_to IS NULL AND valid_from < NOW();
+    INSERT INTO admin_unit_versions (unit_id, province_id, name, level, level_code, code, gso_code, pre_merger_desc, lat, long, valid_from)
+    VALUES (NEW.id, NEW.province_id, NEW.name, NEW.level, NEW.level_code, NEW.code, NEW.gso_code, NEW.pre_merger_desc, NEW.lat, NEW.long, NOW())
+    ON CONFLICT (unit_id, valid_from) DO UPDATE
+    SET province_id = EXCLUDED.province_id, name = EXCLUDED.name, level = EXCLUDED.level, level_code = EXCLUDED.level_code,
+        code = EXCLUDED.code, gso_code = EXCLUDED.gso_code, pre_merger_desc = EXCLUDED.pre_merger_desc,
+        lat = EXCLUDED.lat, long = EXCLUDED.long;
+    RETURN NEW;
+END
+$$ LANGUAGE plpgsql;
+
+DROP INDEX IF EXISTS idx_admin_units_retired_at;
+DROP INDEX IF EXISTS idx_provinces_retired_at;
+DROP INDEX IF EXISTS idx_provinces_updated_at;
+ALTER TABLE admin_units DROP COLUMN IF EXISTS retired_at;
+ALTER TABLE provinces DROP COLUMN IF EXISTS retired_at;
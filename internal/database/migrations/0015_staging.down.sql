@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS staging_unit_predecessors;
+DROP TABLE IF EXISTS staging_admin_units;
+DROP TABLE IF EXISTS staging_provinces;
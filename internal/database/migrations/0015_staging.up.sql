@@ -0,0 +1,36 @@
+-- Staged crawls: a crawl run writes here, then Repository.Publish copies the run into the live
+-- tables in one transaction, so readers never see a half-updated dataset. Rows only live for the
+-- duration of a run; UNLOGGED because a crash discards the run anyway.
+CREATE UNLOGGED TABLE IF NOT EXISTS staging_provinces (
+    run_id TEXT NOT NULL,
+    id INT NOT NULL,
+    name TEXT NOT NULL,
+    code TEXT,
+    PRIMARY KEY (run_id, id)
+);
+
+CREATE UNLOGGED TABLE IF NOT EXISTS staging_admin_units (
+    run_id TEXT NOT NULL,
+    id INT NOT NULL,
+    province_id INT NOT NULL,
+    name TEXT NOT NULL,
+    level TEXT,
+    level_code TEXT,
+    code TEXT,
+    pre_merger_desc TEXT,
+    lat FLOAT,
+    long FLOAT,
+    PRIMARY KEY (run_id, id)
+);
+
+CREATE UNLOGGED TABLE IF NOT EXISTS staging_unit_predecessors (
+    run_id TEXT NOT NULL,
+    unit_id INT NOT NULL,
+    seq INT NOT NULL,
+    old_name TEXT NOT NULL,
+    old_level TEXT,
+    old_district TEXT,
+    old_province TEXT,
+    partial BOOLEAN NOT NULL DEFAULT FALSE,
+    PRIMARY KEY (run_id, unit_id, seq)
+);
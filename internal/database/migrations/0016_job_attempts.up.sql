@@ -0,0 +1,3 @@
+-- Each claim of a job increments attempt; a worker's writes only apply while its attempt is the
+-- current one, so a worker whose job was taken over as stale cannot overwrite the new result.
+ALTER TABLE jobs ADD COLUMN IF NOT EXISTS attempt INT NOT NULL DEFAULT 0;
@@ -0,0 +1,8 @@
+DROP TRIGGER IF EXISTS admin_units_sync_version ON admin_units;
+DROP TRIGGER IF EXISTS provinces_sync_version ON provinces;
+DROP FUNCTION IF EXISTS sync_version_stamp();
+DROP INDEX IF EXISTS idx_admin_units_sync_version;
+DROP INDEX IF EXISTS idx_provinces_sync_version;
+ALTER TABLE admin_units DROP COLUMN IF EXISTS sync_version;
+ALTER TABLE provinces DROP COLUMN IF EXISTS sync_version;
+DROP TABLE IF EXISTS sync_state;
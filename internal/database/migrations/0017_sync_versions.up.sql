@@ -0,0 +1,41 @@
+-- Delta sync positions that follow commit order. The first write of a transaction to provinces or
+-- admin_units bumps sync_state.version and stamps every row it writes with the new value. The
+-- sync_state row stays locked until commit, so the next writer gets a higher version only after
+-- this one committed: a reader that sees version V also sees every row stamped V or less.
+-- (Timestamps cannot do this: NOW() is the start of a transaction that may commit much later.)
+CREATE TABLE IF NOT EXISTS sync_state (
+    id BOOLEAN PRIMARY KEY DEFAULT TRUE CHECK (id),
+    version BIGINT NOT NULL
+);
+-- Versions start at 1, so 0 never names a position and since = 0 is a full sync
+INSERT INTO sync_state (version) VALUES (1) ON CONFLICT DO NOTHING;
+
+ALTER TABLE provinces ADD COLUMN IF NOT EXISTS sync_version BIGINT NOT NULL DEFAULT 1;
+ALTER TABLE admin_units ADD COLUMN IF NOT EXISTS sync_version BIGINT NOT NULL DEFAULT 1;
+CREATE INDEX IF NOT EXISTS idx_provinces_sync_version ON provinces (sync_version);
+CREATE INDEX IF NOT EXISTS idx_admin_units_sync_version ON admin_units (sync_version);
+
+CREATE OR REPLACE FUNCTION sync_version_stamp() RETURNS trigger AS $$
+DECLARE
+    v BIGINT;
+BEGIN
+    IF TG_OP = 'UPDATE' AND NEW IS NOT DISTINCT FROM OLD THEN
+        RETURN NEW;
+    END IF;
+    v := NULLIF(current_setting('vn_admin.sync_version', true), '')::BIGINT;
+    IF v IS NULL THEN
+        UPDATE sync_state SET version = version + 1 RETURNING version INTO v;
+        PERFORM set_config('vn_admin.sync_version', v::TEXT, true);
+    END IF;
+    NEW.sync_version := v;
+    RETURN NEW;
+END
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS provinces_sync_version ON provinces;
+CREATE TRIGGER provinces_sync_version BEFORE INSERT OR UPDATE ON provinces
+    FOR EACH ROW EXECUTE FUNCTION sync_version_stamp();
+
+DROP TRIGGER IF EXISTS admin_units_sync_version ON admin_units;
+CREATE TRIGGER admin_units_sync_version BEFORE INSERT OR UPDATE ON admin_units
+    FOR EACH ROW EXECUTE FUNCTION sync_version_stamp();
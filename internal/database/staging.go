@@ -0,0 +1,150 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+
+	"vn-admin-api/internal/changelog"
+	"vn-admin-api/internal/models"
+)
+
+// stagingLockKey is the pg_advisory_lock key held by the crawl that owns the staging tables
+const stagingLockKey int64 = 0x766e7374616765 // "vnstage"
+
+// ErrStagingBusy is returned by BeginStaging while another crawl holds the staging tables
+var ErrStagingBusy = errors.New("another crawl is staging")
+
+// BeginStaging takes the staging tables for one crawl run until release is called, then drops the
+// runs left behind by crashed crawls. The advisory lock is held by a dedicated connection, so it
+// is also released if the process dies; a second crawl fails with ErrStagingBusy instead of
+// wiping rows the first one staged.
+func (r *Repository) BeginStaging(ctx context.Context) (release func(), err error) {
+	conn, err := r.db.Conn(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to acquire connection: %w", err)
+	}
+	var locked bool
+	if err := conn.QueryRowContext(ctx, "SELECT pg_try_advisory_lock($1)", stagingLockKey).Scan(&locked); err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("failed to lock staging: %w", err)
+	}
+	if !locked {
+		conn.Close()
+		return nil, ErrStagingBusy
+	}
+	release = func() {
+		_, _ = conn.ExecContext(context.Background(), "SELECT pg_advisory_unlock($1)", stagingLockKey)
+		conn.Close()
+	}
+
+	if _, err := conn.ExecContext(ctx, "TRUNCATE staging_provinces, staging_admin_units, staging_unit_predecessors"); err != nil {
+		release()
+		return nil, fmt.Errorf("failed to reset staging: %w", err)
+	}
+	return release, nil
+}
+
+// StageProvince stages a province of a crawl run with its units and their predecessors in one
+// transaction. The live tables are untouched until Publish.
+func (r *Repository) StageProvince(ctx context.Context, runID string, p models.Province, units []models.AdminUnit, preds []models.Predecessor) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, `
+		INSERT INTO staging_provinces (run_id, id, name, code) VALUES ($1, $2, $3, $4)
+		ON CONFLICT (run_id, id) DO UPDATE SET name = EXCLUDED.name, code = EXCLUDED.code`,
+		runID, p.ID, p.Name, strconv.Itoa(p.Code)); err != nil {
+		return fmt.Errorf("failed to stage province %d: %w", p.ID, err)
+	}
+
+	unitStmt, err := tx.PrepareContext(ctx, `
+		INSERT INTO staging_admin_units (run_id, id, province_id, name, level, level_code, code, pre_merger_desc, lat, long)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+		ON CONFLICT (run_id, id) DO UPDATE SET
+			province_id = EXCLUDED.province_id, name = EXCLUDED.name, level = EXCLUDED.level, level_code = EXCLUDED.level_code,
+			code = EXCLUDED.code, pre_merger_desc = EXCLUDED.pre_merger_desc, lat = EXCLUDED.lat, long = EXCLUDED.long`)
+	if err != nil {
+		return err
+	}
+	defer unitStmt.Close()
+	for _, u := range units {
+		if _, err := unitStmt.ExecContext(ctx, runID, u.ID, u.ProvinceID, u.Name, u.Level, nullString(u.LevelCode),
+			u.Code, u.PreMergerDesc, u.Lat, u.Long); err != nil {
+			return fmt.Errorf("failed to stage unit %d: %w", u.ID, err)
+		}
+	}
+
+	predStmt, err := tx.PrepareContext(ctx, `
+		INSERT INTO staging_unit_predecessors (run_id, unit_id, seq, old_name, old_level, old_district, old_province, partial)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		ON CONFLICT (run_id, unit_id, seq) DO NOTHING`)
+	if err != nil {
+		return err
+	}
+	defer predStmt.Close()
+	seq := make(map[int]int)
+	for _, p := range preds {
+		if _, err := predStmt.ExecContext(ctx, runID, p.UnitID, seq[p.UnitID], p.Name, p.Level,
+			nullString(p.District), nullString(p.Province), p.Partial); err != nil {
+			return fmt.Errorf("failed to stage predecessor of unit %d: %w", p.UnitID, err)
+		}
+		seq[p.UnitID]++
+	}
+	return tx.Commit()
+}
+
+// Publish copies a staged crawl run into the live tables in one transaction, together with its
+// change log entries: staged provinces and units are upserted (unchanged rows are left alone, as
+// with UpsertProvince), staged units get their predecessors replaced, and the provinces and units
+// the changes report as removed are retired. The run's staging rows are then dropped.
+func (r *Repository) Publish(ctx context.Context, runID string, changes []models.Change) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	steps := []struct {
+		name  string
+		query string
+	}{
+		{"provinces", `
+			INSERT INTO provinces (id, name, code, updated_at)
+			SELECT id, name, code, NOW() FROM staging_provinces WHERE run_id = $1 ORDER BY id` + provinceOnConflict},
+		{"units", `
+			INSERT INTO admin_units (id, province_id, name, level, code, pre_merger_desc, lat, long, level_code, updated_at)
+			SELECT id, province_id, name, level, code, pre_merger_desc, lat, long, level_code, NOW()
+			FROM staging_admin_units WHERE run_id = $1 ORDER BY id` + unitOnConflict},
+		{"predecessors", `
+			DELETE FROM unit_predecessors WHERE unit_id IN (SELECT id FROM staging_admin_units WHERE run_id = $1)`},
+		{"predecessors", `
+			INSERT INTO unit_predecessors (unit_id, seq, old_name, old_level, old_district, old_province, partial)
+			SELECT unit_id, seq, old_name, old_level, old_district, old_province, partial
+			FROM staging_unit_predecessors WHERE run_id = $1`},
+	}
+	for _, step := range steps {
+		if _, err := tx.ExecContext(ctx, step.query, runID); err != nil {
+			return fmt.Errorf("failed to publish %s of run %s: %w", step.name, runID, err)
+		}
+	}
+
+	if err := saveChanges(ctx, tx, runID, changes); err != nil {
+		return err
+	}
+	provinceIDs, unitIDs := changelog.Removals(changes)
+	if err := retire(ctx, tx, provinceIDs, unitIDs); err != nil {
+		return err
+	}
+
+	for _, table := range []string{"staging_unit_predecessors", "staging_admin_units", "staging_provinces"} {
+		if _, err := tx.ExecContext(ctx, "DELETE FROM "+table+" WHERE run_id = $1", runID); err != nil {
+			return fmt.Errorf("failed to clear %s: %w", table, err)
+		}
+	}
+	return tx.Commit()
+}
@@ -0,0 +1,146 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/lib/pq"
+
+	"vn-admin-api/internal/models"
+)
+
+// Retire marks provinces and units as retired (disappeared upstream) in one transaction. Records
+// already retired keep their retired_at; upserting a retired record restores it.
+func (r *Repository) Retire(ctx context.Context, provinceIDs, unitIDs []int) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := retire(ctx, tx, provinceIDs, unitIDs); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// retire is Retire within tx
+func retire(ctx context.Context, tx *sql.Tx, provinceIDs, unitIDs []int) error {
+	// Units first, so a retired province never has live units
+	for _, set := range []struct {
+		table string
+		ids   []int
+	}{{"admin_units", unitIDs}, {"provinces", provinceIDs}} {
+		if len(set.ids) == 0 {
+			continue
+		}
+		if _, err := tx.ExecContext(ctx,
+			"UPDATE "+set.table+" SET retired_at = NOW() WHERE id = ANY($1) AND retired_at IS NULL",
+			pq.Array(set.ids)); err != nil {
+			return fmt.Errorf("failed to retire %s: %w", set.table, err)
+		}
+	}
+	return nil
+}
+
+// GetRetired returns every retired province and unit, ordered by ID
+func (r *Repository) GetRetired(ctx context.Context) ([]models.Province, []models.AdminUnit, error) {
+	rows, err := r.db.QueryContext(ctx, "SELECT "+provinceColumns+" FROM provinces WHERE retired_at IS NOT NULL ORDER BY id")
+	if err != nil {
+		return nil, nil, err
+	}
+	provinces, err := scanProvinces(rows)
+	if err != nil {
+		return nil, nil, err
+	}
+	rows, err = r.db.QueryContext(ctx, "SELECT "+unitColumns+" FROM admin_units WHERE retired_at IS NOT NULL ORDER BY id")
+	if err != nil {
+		return nil, nil, err
+	}
+	units, err := scanUnits(rows)
+	if err != nil {
+		return nil, nil, err
+	}
+	return provinces, units, nil
+}
+
+// GetSyncDelta returns what changed after the sync version since, up to the current one; since = 0
+// asks for a full sync. Versions follow commit order (see migration 0017), so the snapshot that
+// reads the current version holds every row stamped with it or an earlier one: a write is never
+// skipped, whichever role made it and however long its transaction ran.
+func (r *Repository) GetSyncDelta(ctx context.Context, since int64) (*models.SyncDelta, error) {
+	tx, err := r.db.BeginTx(ctx, &sql.TxOptions{Isolation: sql.LevelRepeatableRead, ReadOnly: true})
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	delta := &models.SyncDelta{}
+	if err := tx.QueryRowContext(ctx, "SELECT version FROM sync_state").Scan(&delta.Version); err != nil {
+		return nil, fmt.Errorf("failed to read sync version: %w", err)
+	}
+
+	window := "retired_at IS NULL"
+	var args []interface{}
+	if since > 0 {
+		window += " AND sync_version > $1"
+		args = append(args, since)
+	}
+
+	rows, err := tx.QueryContext(ctx, "SELECT "+provinceColumns+" FROM provinces WHERE "+window+" ORDER BY id", args...)
+	if err != nil {
+		return nil, err
+	}
+	if delta.Provinces, err = scanProvinces(rows); err != nil {
+		return nil, err
+	}
+	rows, err = tx.QueryContext(ctx, "SELECT "+unitColumns+" FROM admin_units WHERE "+window+" ORDER BY id", args...)
+	if err != nil {
+		return nil, err
+	}
+	if delta.Units, err = scanUnits(rows); err != nil {
+		return nil, err
+	}
+
+	if since == 0 {
+		delta.DeletedProvinces, delta.DeletedUnits = []models.Tombstone{}, []models.Tombstone{}
+		return delta, tx.Commit()
+	}
+	if delta.DeletedProvinces, err = queryTombstones(ctx, tx, "provinces", since); err != nil {
+		return nil, err
+	}
+	if delta.DeletedUnits, err = queryTombstones(ctx, tx, "admin_units", since); err != nil {
+		return nil, err
+	}
+	return delta, tx.Commit()
+}
+
+// GetSyncVersion returns the current sync version, which changes with every committed write
+func (r *Repository) GetSyncVersion(ctx context.Context) (int64, error) {
+	var version int64
+	if err := r.db.QueryRowContext(ctx, "SELECT version FROM sync_state").Scan(&version); err != nil {
+		return 0, fmt.Errorf("failed to read sync version: %w", err)
+	}
+	return version, nil
+}
+
+// queryTombstones lists the records of table retired after the sync version since
+func queryTombstones(ctx context.Context, tx *sql.Tx, table string, since int64) ([]models.Tombstone, error) {
+	rows, err := tx.QueryContext(ctx,
+		"SELECT id, retired_at FROM "+table+" WHERE retired_at IS NOT NULL AND sync_version > $1 ORDER BY id", since)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	tombstones := make([]models.Tombstone, 0)
+	for rows.Next() {
+		var t models.Tombstone
+		if err := rows.Scan(&t.ID, &t.RetiredAt); err != nil {
+			return nil, err
+		}
+		tombstones = append(tombstones, t)
+	}
+	return tombstones, rows.Err()
+}
@@ -0,0 +1,75 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"vn-admin-api/internal/models"
+)
+
+// unitVersionColumns selects admin_unit_versions in the column order of unitColumns, with
+// valid_from standing in for updated_at. Versions only describe live states: retired_at is NULL.
+const unitVersionColumns = "unit_id, province_id, name, level, code, pre_merger_desc, lat, long, valid_from, level_code, gso_code, NULL::timestamp"
+
+// validAt is the condition on a version table for the state at $1
+const validAt = "valid_from <= $1::timestamp AND (valid_to IS NULL OR valid_to > $1::timestamp)"
+
+// GetProvincesAsOf returns the provinces as they were at asOf, from the version history
+func (r *Repository) GetProvincesAsOf(ctx context.Context, asOf time.Time) ([]models.Province, error) {
+	rows, err := r.db.QueryContext(ctx,
+		"SELECT province_id, name, code, valid_from, gso_code, NULL::timestamp FROM province_versions WHERE "+validAt+" ORDER BY province_id",
+		timestamp(asOf))
+	if err != nil {
+		return nil, err
+	}
+	return scanProvinces(rows)
+}
+
+// GetUnitsAsOf returns the units as they were at asOf, of one province or of all when provinceID is 0
+func (r *Repository) GetUnitsAsOf(ctx context.Context, asOf time.Time, provinceID int) ([]models.AdminUnit, error) {
+	rows, err := r.db.QueryContext(ctx,
+		"SELECT "+unitVersionColumns+" FROM admin_unit_versions WHERE "+validAt+" AND ($2 = 0 OR province_id = $2) ORDER BY unit_id",
+		timestamp(asOf), provinceID)
+	if err != nil {
+		return nil, err
+	}
+	return scanUnits(rows)
+}
+
+// GetUnitHistory returns every version of a unit, oldest first; empty when the unit is unknown
+func (r *Repository) GetUnitHistory(ctx context.Context, id int) ([]models.UnitVersion, error) {
+	rows, err := r.db.QueryContext(ctx,
+		"SELECT "+unitVersionColumns+", valid_to FROM admin_unit_versions WHERE unit_id = $1 ORDER BY valid_from",
+		id)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	versions := make([]models.UnitVersion, 0)
+	for rows.Next() {
+		var v models.UnitVersion
+		var preMerger, levelCode, gsoCode sql.NullString
+		var retiredAt, validTo sql.NullTime
+
+		u := &v.AdminUnit
+		if err := rows.Scan(&u.ID, &u.ProvinceID, &u.Name, &u.Level, &u.Code, &preMerger, &u.Lat, &u.Long, &u.UpdatedAt,
+			&levelCode, &gsoCode, &retiredAt, &validTo); err != nil {
+			return nil, err
+		}
+		u.PreMergerDesc, u.LevelCode, u.GSOCode = preMerger.String, levelCode.String, gsoCode.String
+		v.ValidFrom = u.UpdatedAt
+		if validTo.Valid {
+			v.ValidTo = &validTo.Time
+		}
+		versions = append(versions, v)
+	}
+	return versions, rows.Err()
+}
+
+// timestamp formats t for comparison with the timestamp-without-time-zone columns, which hold UTC
+// because sessions are pinned to it (see connString)
+func timestamp(t time.Time) string {
+	return t.UTC().Format("2006-01-02 15:04:05.999999")
+}
@@ -0,0 +1,178 @@
+package geo
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"vn-admin-api/internal/logger"
+	"vn-admin-api/internal/models"
+)
+
+// BoundaryStore is the subset of storage.Store the boundary index is built from
+type BoundaryStore interface {
+	GetBoundaries(ctx context.Context, kind string) ([]models.Boundary, error)
+}
+
+// Outline is the parsed boundary of one province or unit
+type Outline struct {
+	ID    int
+	Shape *Shape
+}
+
+// shapeSet is an immutable built index; lookups never lock
+type shapeSet struct {
+	provinces []Outline // sorted by bbox area, tightest first
+	units     []Outline
+	version   string
+}
+
+// BoundaryIndex answers point-in-polygon lookups over the imported outlines.
+// Each lookup only runs the ray casting test on shapes whose bounding box contains the point.
+type BoundaryIndex struct {
+	store   BoundaryStore
+	current atomic.Pointer[shapeSet]
+	buildMu sync.Mutex // serializes rebuilds
+}
+
+func NewBoundaryIndex(store BoundaryStore) *BoundaryIndex {
+	return &BoundaryIndex{store: store}
+}
+
+// Ready reports whether the index has been built
+func (idx *BoundaryIndex) Ready() bool {
+	return idx.current.Load() != nil
+}
+
+// Len returns the number of indexed province and unit outlines
+func (idx *BoundaryIndex) Len() (provinces, units int) {
+	set := idx.current.Load()
+	if set == nil {
+		return 0, 0
+	}
+	return len(set.provinces), len(set.units)
+}
+
+// Version identifies the loaded outlines; it changes whenever a rebuild swaps in new data
+func (idx *BoundaryIndex) Version() string {
+	set := idx.current.Load()
+	if set == nil {
+		return ""
+	}
+	return set.version
+}
+
+// Rebuild reloads the outlines and swaps in a new index when they changed.
+// Geometries are validated on import, so one that fails to parse here is an error.
+func (idx *BoundaryIndex) Rebuild(ctx context.Context) (bool, error) {
+	idx.buildMu.Lock()
+	defer idx.buildMu.Unlock()
+
+	provinces, err := idx.store.GetBoundaries(ctx, models.BoundaryProvince)
+	if err != nil {
+		return false, err
+	}
+	units, err := idx.store.GetBoundaries(ctx, models.BoundaryUnit)
+	if err != nil {
+		return false, err
+	}
+
+	var latest time.Time
+	for _, list := range [][]models.Boundary{provinces, units} {
+		for _, b := range list {
+			if b.UpdatedAt.After(latest) {
+				latest = b.UpdatedAt
+			}
+		}
+	}
+	version := fmt.Sprintf("%s/%d/%d", latest.UTC().Format(time.RFC3339Nano), len(provinces), len(units))
+	if old := idx.current.Load(); old != nil && old.version == version {
+		return false, nil
+	}
+
+	set := &shapeSet{version: version}
+	if set.provinces, err = parseShapes(provinces); err != nil {
+		return false, err
+	}
+	if set.units, err = parseShapes(units); err != nil {
+		return false, err
+	}
+	idx.current.Store(set)
+	return true, nil
+}
+
+// Run rebuilds the index every interval until ctx is cancelled
+func (idx *BoundaryIndex) Run(ctx context.Context, interval time.Duration, log *logger.Logger) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		changed, err := idx.Rebuild(ctx)
+		switch {
+		case err != nil && ctx.Err() == nil:
+			log.Error("Failed to rebuild boundary index", "error", err)
+		case changed:
+			provinces, units := idx.Len()
+			log.Info("Boundary index rebuilt", "provinces", provinces, "units", units)
+		}
+	}
+}
+
+// Locate returns the IDs of the province and unit containing the point, 0 when none does.
+// Where outlines overlap, the one with the tightest bounding box wins.
+func (idx *BoundaryIndex) Locate(lat, lng float64) (provinceID, unitID int) {
+	set := idx.current.Load()
+	if set == nil {
+		return 0, 0
+	}
+	return locate(set.provinces, lat, lng), locate(set.units, lat, lng)
+}
+
+// Intersecting returns the province and unit outlines whose bounding box overlaps box
+func (idx *BoundaryIndex) Intersecting(box BBox) (provinces, units []Outline) {
+	set := idx.current.Load()
+	if set == nil {
+		return nil, nil
+	}
+	return intersecting(set.provinces, box), intersecting(set.units, box)
+}
+
+func intersecting(refs []Outline, box BBox) []Outline {
+	var found []Outline
+	for _, r := range refs {
+		if r.Shape.BBox.Intersects(box) {
+			found = append(found, r)
+		}
+	}
+	return found
+}
+
+func locate(refs []Outline, lat, lng float64) int {
+	for _, r := range refs {
+		if r.Shape.Contains(lat, lng) {
+			return r.ID
+		}
+	}
+	return 0
+}
+
+func parseShapes(boundaries []models.Boundary) ([]Outline, error) {
+	refs := make([]Outline, 0, len(boundaries))
+	for _, b := range boundaries {
+		shape, err := ParseGeometry(b.Geometry)
+		if err != nil {
+			return nil, fmt.Errorf("%s boundary %d: %w", b.Kind, b.RefID, err)
+		}
+		refs = append(refs, Outline{ID: b.RefID, Shape: shape})
+	}
+	sort.SliceStable(refs, func(i, j int) bool { return refs[i].Shape.BBox.Area() < refs[j].Shape.BBox.Area() })
+	return refs, nil
+}
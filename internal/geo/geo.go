@@ -0,0 +1,31 @@
+// Package geo holds the spatial helpers behind the coordinate endpoints: great-circle
+// distances and an in-memory grid index over unit centroids, so lookups never hit the Store.
+package geo
+
+import "math"
+
+// EarthRadiusKm is the mean Earth radius used for haversine distances
+const EarthRadiusKm = 6371.0088
+
+// kmPerDegree is the length of one degree of latitude
+const kmPerDegree = EarthRadiusKm * math.Pi / 180
+
+// Haversine returns the great-circle distance in km between two WGS84 coordinates
+func Haversine(lat1, lng1, lat2, lng2 float64) float64 {
+	rlat1, rlat2 := lat1*math.Pi/180, lat2*math.Pi/180
+	dLat := rlat2 - rlat1
+	dLng := (lng2 - lng1) * math.Pi / 180
+
+	a := math.Sin(dLat/2)*math.Sin(dLat/2) + math.Cos(rlat1)*math.Cos(rlat2)*math.Sin(dLng/2)*math.Sin(dLng/2)
+	return 2 * EarthRadiusKm * math.Asin(math.Min(1, math.Sqrt(a)))
+}
+
+// ValidCoordinate reports whether lat/lng are within WGS84 bounds
+func ValidCoordinate(lat, lng float64) bool {
+	return lat >= -90 && lat <= 90 && lng >= -180 && lng <= 180 && !math.IsNaN(lat) && !math.IsNaN(lng)
+}
+
+// HasCoordinates reports whether a unit has a centroid; upstream sends 0,0 when it is missing
+func HasCoordinates(lat, lng float64) bool {
+	return lat != 0 || lng != 0
+}
@@ -0,0 +1,123 @@
+package geo
+
+import (
+	"context"
+	"math"
+	"math/rand"
+	"sort"
+	"testing"
+
+	"vn-admin-api/internal/models"
+)
+
+func TestHaversine(t *testing.T) {
+	// One degree along a meridian
+	if got := Haversine(10, 106, 11, 106); math.Abs(got-111.195) > 0.01 {
+		t.Errorf("Haversine = %.3f km, want 111.195 km", got)
+	}
+	// One degree along the 60th parallel is about half as long
+	if got := Haversine(60, 10, 60, 11); math.Abs(got-55.6) > 0.1 {
+		t.Errorf("Haversine = %.3f km, want about 55.6 km", got)
+	}
+	if d := Haversine(21, 105, 21, 105); d != 0 {
+		t.Errorf("Haversine of identical points = %v, want 0", d)
+	}
+}
+
+type fakeStore struct {
+	units []models.AdminUnit
+}
+
+func (s *fakeStore) GetProvinces(ctx context.Context) ([]models.Province, error) {
+	return []models.Province{{ID: 1}}, nil
+}
+
+func (s *fakeStore) GetUnitsByProvince(ctx context.Context, provinceID int) ([]models.AdminUnit, error) {
+	return s.units, nil
+}
+
+func TestNearest(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	store := &fakeStore{units: []models.AdminUnit{{ID: 1, ProvinceID: 1}}} // no coordinates: not indexed
+	for id := 2; id <= 2000; id++ {
+		store.units = append(store.units, models.AdminUnit{
+			ID: id, ProvinceID: 1,
+			Lat: 8.5 + rng.Float64()*15, Long: 102 + rng.Float64()*7.5,
+		})
+	}
+	idx := NewIndex(store)
+	if _, err := idx.Rebuild(context.Background()); err != nil {
+		t.Fatalf("Rebuild failed: %v", err)
+	}
+	if idx.Len() != 1999 {
+		t.Fatalf("Expected 1999 indexed units, got %d", idx.Len())
+	}
+
+	queries := [][2]float64{{21.03, 105.85}, {10.77, 106.7}, {16, 108}, {0, 0}, {30, 120}}
+	for _, q := range queries {
+		got := idx.Nearest(q[0], q[1], 5)
+
+		want := make([]models.NearbyUnit, 0, len(store.units))
+		for _, u := range store.units[1:] {
+			want = append(want, models.NearbyUnit{AdminUnit: u, DistanceKm: Haversine(q[0], q[1], u.Lat, u.Long)})
+		}
+		sort.Slice(want, func(i, j int) bool { return want[i].DistanceKm < want[j].DistanceKm })
+
+		if len(got) != 5 {
+			t.Fatalf("Nearest(%v) returned %d units, want 5", q, len(got))
+		}
+		for i := range got {
+			if got[i].ID != want[i].ID {
+				t.Errorf("Nearest(%v)[%d] = unit %d (%.2f km), want unit %d (%.2f km)",
+					q, i, got[i].ID, got[i].DistanceKm, want[i].ID, want[i].DistanceKm)
+			}
+		}
+	}
+}
+
+func TestWithinAndInBBox(t *testing.T) {
+	rng := rand.New(rand.NewSource(2))
+	store := &fakeStore{}
+	for id := 1; id <= 2000; id++ {
+		store.units = append(store.units, models.AdminUnit{
+			ID: id, ProvinceID: 1,
+			Lat: 8.5 + rng.Float64()*15, Long: 102 + rng.Float64()*7.5,
+		})
+	}
+	idx := NewIndex(store)
+	if _, err := idx.Rebuild(context.Background()); err != nil {
+		t.Fatalf("Rebuild failed: %v", err)
+	}
+
+	lat, lng, radius := 21.03, 105.85, 60.0
+	var want []int
+	for _, u := range store.units {
+		if Haversine(lat, lng, u.Lat, u.Long) <= radius {
+			want = append(want, u.ID)
+		}
+	}
+	got := idx.Within(lat, lng, radius)
+	if len(got) != len(want) || len(got) == 0 {
+		t.Fatalf("Within returned %d units, want %d", len(got), len(want))
+	}
+	for i := 1; i < len(got); i++ {
+		if got[i].DistanceKm < got[i-1].DistanceKm {
+			t.Fatalf("Within results are not ordered by distance: %v", got)
+		}
+	}
+
+	box := BBox{MinLng: 105, MinLat: 20, MaxLng: 106.5, MaxLat: 21.5}
+	want = want[:0]
+	for _, u := range store.units {
+		if box.Contains(u.Lat, u.Long) {
+			want = append(want, u.ID)
+		}
+	}
+	ids := make([]int, 0)
+	for _, u := range idx.InBBox(box) {
+		ids = append(ids, u.ID)
+	}
+	if !sort.IntsAreSorted(ids) || len(ids) != len(want) {
+		t.Errorf("InBBox returned %v, want %v", ids, want)
+	}
+}
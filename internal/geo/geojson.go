@@ -0,0 +1,98 @@
+package geo
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+
+	"vn-admin-api/internal/models"
+)
+
+// Which geometries a FeatureCollection carries per record
+const (
+	GeometryAll      = "all"      // centroid Points plus boundary polygons where loaded
+	GeometryCentroid = "centroid" // Points only
+	GeometryBoundary = "boundary" // boundary polygons only
+)
+
+// Feature and FeatureCollection follow RFC 7946
+type Feature struct {
+	Type       string                 `json:"type"`
+	ID         string                 `json:"id,omitempty"`
+	Geometry   json.RawMessage        `json:"geometry"` // null when the record has no geometry
+	Properties map[string]interface{} `json:"properties"`
+}
+
+type FeatureCollection struct {
+	Type     string    `json:"type"`
+	Features []Feature `json:"features"`
+}
+
+// featureKind is stored in properties.feature so mixed collections can be styled or split
+const (
+	featureCentroid = "centroid"
+	featureBoundary = "boundary"
+)
+
+// UnitFeatures builds a FeatureCollection with a Point per unit (skipped when the unit has no
+// coordinates) and a polygon per unit found in boundaries, keyed by unit ID. Properties hold every
+// AdminUnit field under its JSON name.
+func UnitFeatures(units []models.AdminUnit, boundaries map[int]json.RawMessage, geometry string) (*FeatureCollection, error) {
+	fc := &FeatureCollection{Type: "FeatureCollection", Features: make([]Feature, 0, len(units))}
+	for _, u := range units {
+		props, err := properties(u)
+		if err != nil {
+			return nil, err
+		}
+		id := strconv.Itoa(u.ID)
+
+		if geometry != GeometryBoundary && HasCoordinates(u.Lat, u.Long) {
+			point, _ := json.Marshal(map[string]interface{}{"type": "Point", "coordinates": [2]float64{u.Long, u.Lat}})
+			fc.Features = append(fc.Features, newFeature(id, point, props, featureCentroid))
+		}
+		if b, ok := boundaries[u.ID]; ok && geometry != GeometryCentroid {
+			fc.Features = append(fc.Features, newFeature(id+"-boundary", b, props, featureBoundary))
+		}
+	}
+	return fc, nil
+}
+
+// ProvinceFeatures builds a FeatureCollection with one feature per province. Provinces have
+// no centroid, so the geometry is the boundary polygon, or null when none is loaded.
+func ProvinceFeatures(provinces []models.Province, boundaries map[int]json.RawMessage) (*FeatureCollection, error) {
+	fc := &FeatureCollection{Type: "FeatureCollection", Features: make([]Feature, 0, len(provinces))}
+	for _, p := range provinces {
+		props, err := properties(p)
+		if err != nil {
+			return nil, err
+		}
+		geom := json.RawMessage("null")
+		if b, ok := boundaries[p.ID]; ok {
+			geom = b
+		}
+		fc.Features = append(fc.Features, newFeature(strconv.Itoa(p.ID), geom, props, featureBoundary))
+	}
+	return fc, nil
+}
+
+func newFeature(id string, geometry json.RawMessage, props map[string]interface{}, kind string) Feature {
+	withKind := make(map[string]interface{}, len(props)+1)
+	for k, v := range props {
+		withKind[k] = v
+	}
+	withKind["feature"] = kind
+	return Feature{Type: "Feature", ID: id, Geometry: geometry, Properties: withKind}
+}
+
+// properties returns the JSON fields of a model as a map
+func properties(v interface{}) (map[string]interface{}, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	var props map[string]interface{}
+	if err := json.Unmarshal(data, &props); err != nil {
+		return nil, fmt.Errorf("failed to build feature properties: %w", err)
+	}
+	return props, nil
+}
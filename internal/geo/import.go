@@ -0,0 +1,234 @@
+package geo
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"vn-admin-api/internal/lineage"
+	"vn-admin-api/internal/models"
+	"vn-admin-api/internal/vn2000"
+	"vn-admin-api/internal/vntext"
+)
+
+// How GeoJSON features are linked to stored records
+const (
+	MatchID   = "id"   // provinces.id / admin_units.id
+	MatchCode = "code" // Province.Code (mahc) / AdminUnit.Code (ma)
+	MatchName = "name" // accent-folded name, ignoring the level word
+)
+
+// ImportStore is the subset of storage.Store the boundary importer needs
+type ImportStore interface {
+	Store
+	UpsertBoundary(ctx context.Context, b models.Boundary) error
+}
+
+// ImportOptions selects what a GeoJSON file contains and how its features are linked
+type ImportOptions struct {
+	Kind     string // models.BoundaryProvince or models.BoundaryUnit
+	MatchBy  string // MatchID, MatchCode or MatchName
+	Property string // Feature property holding the id/code/name; see DefaultProperty
+
+	CRS             string  // CRSWGS84 (default) or CRSVN2000
+	CentralMeridian float64 // VN-2000 zone; by default the TM3 zone of the record's province
+}
+
+// DefaultProperty returns the feature property read when ImportOptions.Property is empty.
+// Codes default to the upstream field names (mahc, ma).
+func DefaultProperty(kind, matchBy string) string {
+	switch {
+	case matchBy == MatchCode && kind == models.BoundaryProvince:
+		return "mahc"
+	case matchBy == MatchCode:
+		return "ma"
+	case matchBy == MatchName:
+		return "name"
+	default:
+		return "id"
+	}
+}
+
+// ImportReport summarizes an import
+type ImportReport struct {
+	Features  int      `json:"features"`
+	Imported  int      `json:"imported"`
+	Unmatched []string `json:"unmatched,omitempty"` // Key values that matched no record, or several
+	Invalid   []string `json:"invalid,omitempty"`   // Features whose geometry is not a valid Polygon/MultiPolygon
+}
+
+type featureCollection struct {
+	Type     string    `json:"type"`
+	Features []feature `json:"features"`
+}
+
+type feature struct {
+	ID         json.RawMessage            `json:"id"`
+	Properties map[string]json.RawMessage `json:"properties"`
+	Geometry   json.RawMessage            `json:"geometry"`
+}
+
+// Import reads a GeoJSON FeatureCollection of polygons from r and stores each feature
+// as the boundary of the province or unit it links to. VN-2000 input is converted to WGS84. Features that cannot be linked or
+// parsed are listed in the report instead of failing the whole file.
+func Import(ctx context.Context, store ImportStore, r io.Reader, opts ImportOptions) (*ImportReport, error) {
+	if opts.Kind != models.BoundaryProvince && opts.Kind != models.BoundaryUnit {
+		return nil, fmt.Errorf("unknown boundary kind %q", opts.Kind)
+	}
+	if opts.MatchBy == "" {
+		opts.MatchBy = MatchCode
+	}
+	if opts.Property == "" {
+		opts.Property = DefaultProperty(opts.Kind, opts.MatchBy)
+	}
+	if opts.CRS == "" {
+		opts.CRS = CRSWGS84
+	}
+	if opts.CRS != CRSWGS84 && opts.CRS != CRSVN2000 {
+		return nil, fmt.Errorf("unknown coordinate system %q", opts.CRS)
+	}
+	if opts.CentralMeridian != 0 {
+		if err := vn2000.TM3(opts.CentralMeridian).Validate(); err != nil {
+			return nil, err
+		}
+	}
+
+	var fc featureCollection
+	if err := json.NewDecoder(r).Decode(&fc); err != nil {
+		return nil, fmt.Errorf("failed to decode GeoJSON: %w", err)
+	}
+	if fc.Type != "FeatureCollection" {
+		return nil, fmt.Errorf("expected a GeoJSON FeatureCollection, got %q", fc.Type)
+	}
+
+	lookup, provinces, err := newRecordLookup(ctx, store, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &ImportReport{Features: len(fc.Features)}
+	for i, f := range fc.Features {
+		value := propertyValue(f, opts.Property)
+		label := value
+		if label == "" {
+			label = fmt.Sprintf("feature %d", i)
+		}
+
+		id, ok := lookup[normalizeKey(opts.MatchBy, value)]
+		if value == "" || !ok || id == 0 {
+			report.Unmatched = append(report.Unmatched, label)
+			continue
+		}
+		geometry := f.Geometry
+		if opts.CRS == CRSVN2000 {
+			zone, ok := vn2000.TM3(opts.CentralMeridian), opts.CentralMeridian != 0
+			if !ok {
+				p := provinces[id]
+				zone, ok = vn2000.ZoneOf(p.GSOCode, p.Code)
+			}
+			if !ok {
+				report.Invalid = append(report.Invalid, fmt.Sprintf("%s: no VN-2000 zone known for province %s", label, provinces[id].Name))
+				continue
+			}
+			if geometry, err = reprojectVN2000(f.Geometry, zone); err != nil {
+				report.Invalid = append(report.Invalid, fmt.Sprintf("%s: %v", label, err))
+				continue
+			}
+		}
+		if _, err := ParseGeometry(geometry); err != nil {
+			report.Invalid = append(report.Invalid, fmt.Sprintf("%s: %v", label, err))
+			continue
+		}
+		if err := store.UpsertBoundary(ctx, models.Boundary{Kind: opts.Kind, RefID: id, Geometry: geometry}); err != nil {
+			return report, err
+		}
+		report.Imported++
+	}
+	return report, nil
+}
+
+// newRecordLookup maps normalized keys to record IDs, and record IDs to their province.
+// Keys shared by several records map to 0.
+func newRecordLookup(ctx context.Context, store Store, opts ImportOptions) (map[string]int, map[int]models.Province, error) {
+	provinces, err := store.GetProvinces(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	lookup := make(map[string]int)
+	recordProvinces := make(map[int]models.Province)
+	add := func(key string, id int) {
+		key = normalizeKey(opts.MatchBy, key)
+		if prev, ok := lookup[key]; ok && prev != id {
+			id = 0 // ambiguous
+		}
+		lookup[key] = id
+	}
+
+	for _, p := range provinces {
+		if opts.Kind == models.BoundaryProvince {
+			add(recordKey(opts.MatchBy, p.ID, strconv.Itoa(p.Code), p.Name), p.ID)
+			recordProvinces[p.ID] = p
+			continue
+		}
+		units, err := store.GetUnitsByProvince(ctx, p.ID)
+		if err != nil {
+			return nil, nil, err
+		}
+		for _, u := range units {
+			add(recordKey(opts.MatchBy, u.ID, u.Code, u.Name), u.ID)
+			recordProvinces[u.ID] = p
+		}
+	}
+	return lookup, recordProvinces, nil
+}
+
+func recordKey(matchBy string, id int, code, name string) string {
+	switch matchBy {
+	case MatchCode:
+		return code
+	case MatchName:
+		return name
+	default:
+		return strconv.Itoa(id)
+	}
+}
+
+// normalizeKey makes codes compare without leading zeros ("00004" = 4) and names without
+// case, diacritics or the level word
+func normalizeKey(matchBy, value string) string {
+	value = strings.TrimSpace(value)
+	switch matchBy {
+	case MatchName:
+		_, name := lineage.SplitLevel(value)
+		return strings.Join(vntext.Tokens(name), " ")
+	default:
+		if trimmed := strings.TrimLeft(value, "0"); trimmed != "" {
+			return trimmed
+		}
+		return value
+	}
+}
+
+// propertyValue reads a string or number property; "id" also falls back to the feature id
+func propertyValue(f feature, name string) string {
+	raw, ok := f.Properties[name]
+	if !ok && name == "id" {
+		raw = f.ID
+	}
+	if len(raw) == 0 {
+		return ""
+	}
+	var s string
+	if err := json.Unmarshal(raw, &s); err == nil {
+		return s
+	}
+	var n json.Number
+	if err := json.Unmarshal(raw, &n); err == nil {
+		return n.String()
+	}
+	return ""
+}
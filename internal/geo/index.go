@@ -0,0 +1,281 @@
+package geo
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"vn-admin-api/internal/logger"
+	"vn-admin-api/internal/models"
+)
+
+// cellDegrees is the grid cell size (~11 km of latitude); a ward centroid is rarely further
+// than a couple of cells from its neighbours.
+const cellDegrees = 0.1
+
+// Store is the subset of storage.Store the index is built from
+type Store interface {
+	GetProvinces(ctx context.Context) ([]models.Province, error)
+	GetUnitsByProvince(ctx context.Context, provinceID int) ([]models.AdminUnit, error)
+}
+
+type cell struct {
+	row, col int
+}
+
+// grid is an immutable built index; lookups never lock
+type grid struct {
+	units     []models.AdminUnit
+	cells     map[cell][]int // indexes into units
+	minRow    int
+	maxRow    int
+	minCol    int
+	maxCol    int
+	minCellKm float64 // narrowest cell width in km, for the nearest-neighbour stop condition
+	version   string
+}
+
+// Index is a rebuildable geohash-style grid over unit centroids.
+// Units without coordinates are left out.
+type Index struct {
+	store   Store
+	current atomic.Pointer[grid]
+	buildMu sync.Mutex // serializes rebuilds
+}
+
+func NewIndex(store Store) *Index {
+	return &Index{store: store}
+}
+
+// Ready reports whether the index has been built
+func (idx *Index) Ready() bool {
+	return idx.current.Load() != nil
+}
+
+// Len returns the number of indexed units
+func (idx *Index) Len() int {
+	g := idx.current.Load()
+	if g == nil {
+		return 0
+	}
+	return len(g.units)
+}
+
+// Version identifies the indexed data; it changes whenever a rebuild swaps in a new grid
+func (idx *Index) Version() string {
+	g := idx.current.Load()
+	if g == nil {
+		return ""
+	}
+	return g.version
+}
+
+// Rebuild reloads the units and swaps in a new grid when the data changed.
+// It reports whether a new grid was installed.
+func (idx *Index) Rebuild(ctx context.Context) (bool, error) {
+	idx.buildMu.Lock()
+	defer idx.buildMu.Unlock()
+
+	provinces, err := idx.store.GetProvinces(ctx)
+	if err != nil {
+		return false, err
+	}
+	var units []models.AdminUnit
+	var latest time.Time
+	for _, p := range provinces {
+		pu, err := idx.store.GetUnitsByProvince(ctx, p.ID)
+		if err != nil {
+			return false, err
+		}
+		for _, u := range pu {
+			if u.UpdatedAt.After(latest) {
+				latest = u.UpdatedAt
+			}
+			if HasCoordinates(u.Lat, u.Long) {
+				units = append(units, u)
+			}
+		}
+	}
+
+	version := fmt.Sprintf("%s/%d", latest.UTC().Format(time.RFC3339Nano), len(units))
+	if old := idx.current.Load(); old != nil && old.version == version {
+		return false, nil
+	}
+	idx.current.Store(buildGrid(units, version))
+	return true, nil
+}
+
+// Run rebuilds the index every interval until ctx is cancelled
+func (idx *Index) Run(ctx context.Context, interval time.Duration, log *logger.Logger) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		changed, err := idx.Rebuild(ctx)
+		switch {
+		case err != nil && ctx.Err() == nil:
+			log.Error("Failed to rebuild spatial index", "error", err)
+		case changed:
+			log.Info("Spatial index rebuilt", "units", idx.Len())
+		}
+	}
+}
+
+// Nearest returns the k units closest to lat/lng, nearest first.
+// Rings of cells are scanned outwards until no unscanned cell can hold a closer unit.
+func (idx *Index) Nearest(lat, lng float64, k int) []models.NearbyUnit {
+	results := make([]models.NearbyUnit, 0)
+	g := idx.current.Load()
+	if g == nil || len(g.units) == 0 || k <= 0 {
+		return results
+	}
+
+	center := cellOf(lat, lng)
+	maxRing := max(
+		abs(center.row-g.minRow), abs(center.row-g.maxRow),
+		abs(center.col-g.minCol), abs(center.col-g.maxCol),
+	)
+	for r := 0; r <= maxRing; r++ {
+		g.ring(center, r, func(i int) {
+			u := g.units[i]
+			results = append(results, models.NearbyUnit{AdminUnit: u, DistanceKm: Haversine(lat, lng, u.Lat, u.Long)})
+		})
+		if len(results) < k {
+			continue
+		}
+		sortNearby(results)
+		results = results[:k]
+		// Anything outside ring r is at least r cells away
+		if results[k-1].DistanceKm <= float64(r)*g.minCellKm {
+			break
+		}
+	}
+	sortNearby(results)
+	if len(results) > k {
+		results = results[:k]
+	}
+	return results
+}
+
+// Within returns every unit within radiusKm of lat/lng, nearest first
+func (idx *Index) Within(lat, lng, radiusKm float64) []models.NearbyUnit {
+	results := make([]models.NearbyUnit, 0)
+	g := idx.current.Load()
+	if g == nil || radiusKm <= 0 {
+		return results
+	}
+
+	// Degrees of longitude shrink towards the poles; widen the box at the latitude furthest from the equator
+	latSpan := radiusKm / kmPerDegree
+	maxLat := math.Min(math.Abs(lat)+latSpan, 89)
+	lngSpan := math.Min(radiusKm/(kmPerDegree*math.Cos(maxLat*math.Pi/180)), 180)
+	box := BBox{MinLng: lng - lngSpan, MinLat: lat - latSpan, MaxLng: lng + lngSpan, MaxLat: lat + latSpan}
+
+	g.scan(box, func(i int) {
+		u := g.units[i]
+		if d := Haversine(lat, lng, u.Lat, u.Long); d <= radiusKm {
+			results = append(results, models.NearbyUnit{AdminUnit: u, DistanceKm: d})
+		}
+	})
+	sortNearby(results)
+	return results
+}
+
+// InBBox returns every unit whose centroid lies inside the box, ordered by ID
+func (idx *Index) InBBox(box BBox) []models.AdminUnit {
+	results := make([]models.AdminUnit, 0)
+	g := idx.current.Load()
+	if g == nil {
+		return results
+	}
+	g.scan(box, func(i int) {
+		if u := g.units[i]; box.Contains(u.Lat, u.Long) {
+			results = append(results, u)
+		}
+	})
+	sort.Slice(results, func(i, j int) bool { return results[i].ID < results[j].ID })
+	return results
+}
+
+// scan calls fn for every unit in the cells overlapping box
+func (g *grid) scan(box BBox, fn func(int)) {
+	lo, hi := cellOf(box.MinLat, box.MinLng), cellOf(box.MaxLat, box.MaxLng)
+	for row := max(lo.row, g.minRow); row <= min(hi.row, g.maxRow); row++ {
+		for col := max(lo.col, g.minCol); col <= min(hi.col, g.maxCol); col++ {
+			for _, i := range g.cells[cell{row, col}] {
+				fn(i)
+			}
+		}
+	}
+}
+
+// ring calls fn for every unit in the cells exactly r steps (Chebyshev distance) from center,
+// clipped to the occupied part of the grid
+func (g *grid) ring(center cell, r int, fn func(int)) {
+	visit := func(row, col int) {
+		if col < g.minCol || col > g.maxCol {
+			return
+		}
+		for _, i := range g.cells[cell{row, col}] {
+			fn(i)
+		}
+	}
+	for row := max(center.row-r, g.minRow); row <= min(center.row+r, g.maxRow); row++ {
+		if row == center.row-r || row == center.row+r {
+			for col := center.col - r; col <= center.col+r; col++ {
+				visit(row, col)
+			}
+			continue
+		}
+		// Interior rows only contribute their two side cells
+		visit(row, center.col-r)
+		visit(row, center.col+r)
+	}
+}
+
+func buildGrid(units []models.AdminUnit, version string) *grid {
+	g := &grid{units: units, cells: make(map[cell][]int), version: version}
+	maxAbsLat := 0.0
+	for i, u := range units {
+		c := cellOf(u.Lat, u.Long)
+		g.cells[c] = append(g.cells[c], i)
+		if i == 0 {
+			g.minRow, g.maxRow, g.minCol, g.maxCol = c.row, c.row, c.col, c.col
+		}
+		g.minRow, g.maxRow = min(g.minRow, c.row), max(g.maxRow, c.row)
+		g.minCol, g.maxCol = min(g.minCol, c.col), max(g.maxCol, c.col)
+		maxAbsLat = math.Max(maxAbsLat, math.Abs(u.Lat))
+	}
+	// A degree of longitude shrinks with latitude; use the narrowest cell in the data
+	g.minCellKm = cellDegrees * kmPerDegree * math.Cos(math.Min(maxAbsLat+cellDegrees, 89)*math.Pi/180)
+	return g
+}
+
+func cellOf(lat, lng float64) cell {
+	return cell{row: int(math.Floor(lat / cellDegrees)), col: int(math.Floor(lng / cellDegrees))}
+}
+
+func sortNearby(results []models.NearbyUnit) {
+	sort.SliceStable(results, func(i, j int) bool {
+		if results[i].DistanceKm != results[j].DistanceKm {
+			return results[i].DistanceKm < results[j].DistanceKm
+		}
+		return results[i].ID < results[j].ID
+	})
+}
+
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
@@ -0,0 +1,129 @@
+package geo
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+)
+
+// BBox is a WGS84 bounding box
+type BBox struct {
+	MinLng, MinLat, MaxLng, MaxLat float64
+}
+
+// Contains reports whether the point lies inside or on the edge of the box
+func (b BBox) Contains(lat, lng float64) bool {
+	return lat >= b.MinLat && lat <= b.MaxLat && lng >= b.MinLng && lng <= b.MaxLng
+}
+
+// Intersects reports whether the two boxes overlap or touch
+func (b BBox) Intersects(o BBox) bool {
+	return b.MinLng <= o.MaxLng && o.MinLng <= b.MaxLng && b.MinLat <= o.MaxLat && o.MinLat <= b.MaxLat
+}
+
+// Area returns the box area in square degrees, used to prefer the tightest of overlapping shapes
+func (b BBox) Area() float64 {
+	return (b.MaxLng - b.MinLng) * (b.MaxLat - b.MinLat)
+}
+
+func (b *BBox) extend(lng, lat float64) {
+	b.MinLng, b.MaxLng = math.Min(b.MinLng, lng), math.Max(b.MaxLng, lng)
+	b.MinLat, b.MaxLat = math.Min(b.MinLat, lat), math.Max(b.MaxLat, lat)
+}
+
+// Ring is a closed sequence of [lng, lat] positions, as in GeoJSON
+type Ring [][2]float64
+
+// Polygon is an outer ring followed by its holes
+type Polygon []Ring
+
+// Shape is a parsed Polygon or MultiPolygon geometry
+type Shape struct {
+	Polygons []Polygon
+	BBox     BBox
+}
+
+// ErrUnsupportedGeometry is returned for GeoJSON geometries other than Polygon and MultiPolygon
+var ErrUnsupportedGeometry = errors.New("unsupported geometry type")
+
+// ParseGeometry reads a GeoJSON Polygon or MultiPolygon geometry object
+func ParseGeometry(raw json.RawMessage) (*Shape, error) {
+	var g struct {
+		Type        string          `json:"type"`
+		Coordinates json.RawMessage `json:"coordinates"`
+	}
+	if err := json.Unmarshal(raw, &g); err != nil {
+		return nil, fmt.Errorf("invalid geometry: %w", err)
+	}
+
+	var polygons []Polygon
+	switch g.Type {
+	case "Polygon":
+		var p Polygon
+		if err := json.Unmarshal(g.Coordinates, &p); err != nil {
+			return nil, fmt.Errorf("invalid Polygon coordinates: %w", err)
+		}
+		polygons = []Polygon{p}
+	case "MultiPolygon":
+		if err := json.Unmarshal(g.Coordinates, &polygons); err != nil {
+			return nil, fmt.Errorf("invalid MultiPolygon coordinates: %w", err)
+		}
+	default:
+		return nil, fmt.Errorf("%w %q", ErrUnsupportedGeometry, g.Type)
+	}
+
+	s := &Shape{BBox: BBox{MinLng: math.Inf(1), MinLat: math.Inf(1), MaxLng: math.Inf(-1), MaxLat: math.Inf(-1)}}
+	for _, p := range polygons {
+		if len(p) == 0 || len(p[0]) < 4 {
+			return nil, errors.New("invalid geometry: polygon ring needs at least 4 positions")
+		}
+		for _, pos := range p[0] {
+			if !ValidCoordinate(pos[1], pos[0]) {
+				return nil, fmt.Errorf("invalid geometry: position %v is not WGS84 lng/lat", pos)
+			}
+			s.BBox.extend(pos[0], pos[1])
+		}
+		s.Polygons = append(s.Polygons, p)
+	}
+	if len(s.Polygons) == 0 {
+		return nil, errors.New("invalid geometry: no polygons")
+	}
+	return s, nil
+}
+
+// Contains reports whether the point lies inside the shape (inside an outer ring and outside its holes)
+func (s *Shape) Contains(lat, lng float64) bool {
+	if !s.BBox.Contains(lat, lng) {
+		return false
+	}
+	for _, p := range s.Polygons {
+		if !p[0].contains(lat, lng) {
+			continue
+		}
+		inHole := false
+		for _, hole := range p[1:] {
+			if hole.contains(lat, lng) {
+				inHole = true
+				break
+			}
+		}
+		if !inHole {
+			return true
+		}
+	}
+	return false
+}
+
+// contains is the even-odd ray casting test
+func (r Ring) contains(lat, lng float64) bool {
+	inside := false
+	for i, j := 0, len(r)-1; i < len(r); j, i = i, i+1 {
+		xi, yi := r[i][0], r[i][1]
+		xj, yj := r[j][0], r[j][1]
+		if (yi > lat) != (yj > lat) && lng < (xj-xi)*(lat-yi)/(yj-yi)+xi {
+			inside = !inside
+		}
+	}
+	return inside
+}
@@ -0,0 +1,172 @@
+package geo
+
+import (
+	"context"
+	"encoding/json"
+	"math"
+	"reflect"
+	"strings"
+	"testing"
+
+	"vn-admin-api/internal/models"
+	"vn-admin-api/internal/storage"
+	"vn-admin-api/internal/vn2000"
+)
+
+// A 10x10 square at (100..110, 10..20) with a 2x2 hole in the middle, and a separate island
+const multiPolygon = `{"type": "MultiPolygon", "coordinates": [
+	[[[100, 10], [110, 10], [110, 20], [100, 20], [100, 10]],
+	 [[104, 14], [106, 14], [106, 16], [104, 16], [104, 14]]],
+	[[[120, 10], [121, 10], [121, 11], [120, 10]]]
+]}`
+
+func TestShapeContains(t *testing.T) {
+	shape, err := ParseGeometry(json.RawMessage(multiPolygon))
+	if err != nil {
+		t.Fatalf("ParseGeometry failed: %v", err)
+	}
+	if want := (BBox{MinLng: 100, MinLat: 10, MaxLng: 121, MaxLat: 20}); shape.BBox != want {
+		t.Errorf("BBox = %+v, want %+v", shape.BBox, want)
+	}
+
+	tests := []struct {
+		name     string
+		lat, lng float64
+		want     bool
+	}{
+		{"inside outer ring", 12, 102, true},
+		{"inside hole", 15, 105, false},
+		{"inside island", 10.2, 120.8, true},
+		{"in bbox but outside every polygon", 15, 115, false},
+		{"outside bbox", 30, 105, false},
+	}
+	for _, tt := range tests {
+		if got := shape.Contains(tt.lat, tt.lng); got != tt.want {
+			t.Errorf("%s: Contains(%v, %v) = %v, want %v", tt.name, tt.lat, tt.lng, got, tt.want)
+		}
+	}
+
+	if _, err := ParseGeometry(json.RawMessage(`{"type": "Point", "coordinates": [105, 21]}`)); err == nil {
+		t.Error("Expected an error for a Point geometry")
+	}
+}
+
+func TestImport(t *testing.T) {
+	ctx := context.Background()
+	store := storage.NewMemoryStore()
+	if err := store.UpsertProvince(ctx, models.Province{ID: 1, Name: "Thành phố Hà Nội", Code: 1}); err != nil {
+		t.Fatal(err)
+	}
+	for _, u := range []models.AdminUnit{
+		{ID: 101, ProvinceID: 1, Name: "Phường Ba Đình", Code: "00004"},
+		{ID: 102, ProvinceID: 1, Name: "Xã Sơn Tây", Code: "09574"},
+	} {
+		if err := store.UpsertAdminUnit(ctx, u); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	square := `{"type": "Polygon", "coordinates": [[[105, 21], [106, 21], [106, 22], [105, 22], [105, 21]]]}`
+	file := `{"type": "FeatureCollection", "features": [
+		{"type": "Feature", "properties": {"ma": 4}, "geometry": ` + square + `},
+		{"type": "Feature", "properties": {"ma": "09574"}, "geometry": {"type": "Point", "coordinates": [105, 21]}},
+		{"type": "Feature", "properties": {"ma": "99999"}, "geometry": ` + square + `}
+	]}`
+	report, err := Import(ctx, store, strings.NewReader(file), ImportOptions{Kind: models.BoundaryUnit})
+	if err != nil {
+		t.Fatalf("Import failed: %v", err)
+	}
+	if report.Features != 3 || report.Imported != 1 || !reflect.DeepEqual(report.Unmatched, []string{"99999"}) || len(report.Invalid) != 1 {
+		t.Errorf("Unexpected report %+v", report)
+	}
+
+	// Link by name, ignoring diacritics and the level word
+	file = `{"type": "FeatureCollection", "features": [
+		{"type": "Feature", "properties": {"name": "son tay"}, "geometry": ` + square + `}
+	]}`
+	if report, err = Import(ctx, store, strings.NewReader(file), ImportOptions{Kind: models.BoundaryUnit, MatchBy: MatchName}); err != nil || report.Imported != 1 {
+		t.Fatalf("Import by name: report %+v, err %v", report, err)
+	}
+
+	boundaries, err := store.GetBoundaries(ctx, models.BoundaryUnit)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(boundaries) != 2 || boundaries[0].RefID != 101 || boundaries[1].RefID != 102 {
+		t.Errorf("Unexpected stored boundaries %+v", boundaries)
+	}
+}
+
+func TestImportVN2000(t *testing.T) {
+	ctx := context.Background()
+	store := storage.NewMemoryStore()
+	if err := store.UpsertProvince(ctx, models.Province{ID: 1, Name: "Thành phố Hà Nội", Code: 1}); err != nil {
+		t.Fatal(err)
+	}
+
+	// The square 105.8-105.9°E, 21-21.1°N in Hà Nội's TM3 zone (105°), as [easting, northing]
+	zone, _ := vn2000.ProvinceZone(1)
+	var ring [][2]float64
+	for _, p := range [][2]float64{{21, 105.8}, {21, 105.9}, {21.1, 105.9}, {21.1, 105.8}, {21, 105.8}} {
+		x, y := zone.FromWGS84(p[0], p[1])
+		ring = append(ring, [2]float64{y, x})
+	}
+	geometry, _ := json.Marshal(map[string]interface{}{"type": "Polygon", "coordinates": [][][2]float64{ring}})
+	file := `{"type": "FeatureCollection", "features": [{"type": "Feature", "properties": {"mahc": "01"}, "geometry": ` + string(geometry) + `}]}`
+
+	report, err := Import(ctx, store, strings.NewReader(file), ImportOptions{Kind: models.BoundaryProvince, CRS: CRSVN2000})
+	if err != nil || report.Imported != 1 {
+		t.Fatalf("Import: report %+v, err %v", report, err)
+	}
+	boundaries, err := store.GetBoundaries(ctx, models.BoundaryProvince)
+	if err != nil || len(boundaries) != 1 {
+		t.Fatalf("GetBoundaries: %+v, %v", boundaries, err)
+	}
+	shape, err := ParseGeometry(boundaries[0].Geometry)
+	if err != nil {
+		t.Fatalf("Stored geometry is not WGS84: %v", err)
+	}
+	box := shape.BBox
+	if math.Abs(box.MinLng-105.8) > 1e-7 || math.Abs(box.MaxLat-21.1) > 1e-7 {
+		t.Errorf("Stored bbox %+v, want 105.8-105.9°E, 21-21.1°N", box)
+	}
+}
+
+func TestBoundaryIndexLocate(t *testing.T) {
+	square := func(minLng, minLat, size float64) json.RawMessage {
+		b, _ := json.Marshal(map[string]interface{}{
+			"type": "Polygon",
+			"coordinates": [][][2]float64{{
+				{minLng, minLat}, {minLng + size, minLat}, {minLng + size, minLat + size}, {minLng, minLat + size}, {minLng, minLat},
+			}},
+		})
+		return b
+	}
+	store := fakeBoundaryStore{
+		models.BoundaryProvince: {{Kind: models.BoundaryProvince, RefID: 1, Geometry: square(105, 20, 2)}},
+		models.BoundaryUnit: {
+			{Kind: models.BoundaryUnit, RefID: 101, Geometry: square(105, 20, 1)},
+			{Kind: models.BoundaryUnit, RefID: 102, Geometry: square(106, 21, 1)},
+		},
+	}
+	idx := NewBoundaryIndex(store)
+	if _, err := idx.Rebuild(context.Background()); err != nil {
+		t.Fatalf("Rebuild failed: %v", err)
+	}
+
+	if p, u := idx.Locate(20.5, 105.5); p != 1 || u != 101 {
+		t.Errorf("Locate = (%d, %d), want (1, 101)", p, u)
+	}
+	if p, u := idx.Locate(20.5, 106.5); p != 1 || u != 0 {
+		t.Errorf("Locate = (%d, %d), want (1, 0)", p, u)
+	}
+	if p, u := idx.Locate(10, 100); p != 0 || u != 0 {
+		t.Errorf("Locate = (%d, %d), want (0, 0)", p, u)
+	}
+}
+
+type fakeBoundaryStore map[string][]models.Boundary
+
+func (s fakeBoundaryStore) GetBoundaries(ctx context.Context, kind string) ([]models.Boundary, error) {
+	return s[kind], nil
+}
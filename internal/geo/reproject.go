@@ -0,0 +1,60 @@
+package geo
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"vn-admin-api/internal/vn2000"
+)
+
+// Coordinate reference systems of imported geometries
+const (
+	CRSWGS84  = "wgs84"  // [lng, lat] degrees
+	CRSVN2000 = "vn2000" // VN-2000 TM3 [easting, northing] metres, i.e. [Y, X]
+)
+
+// reprojectVN2000 rewrites a Polygon or MultiPolygon from VN-2000 TM3 positions to WGS84.
+// Heights, if present, are dropped.
+func reprojectVN2000(raw json.RawMessage, zone vn2000.Zone) (json.RawMessage, error) {
+	var g struct {
+		Type        string          `json:"type"`
+		Coordinates json.RawMessage `json:"coordinates"`
+	}
+	if err := json.Unmarshal(raw, &g); err != nil {
+		return nil, fmt.Errorf("invalid geometry: %w", err)
+	}
+
+	var polygons [][][][]float64
+	switch g.Type {
+	case "Polygon":
+		var p [][][]float64
+		if err := json.Unmarshal(g.Coordinates, &p); err != nil {
+			return nil, fmt.Errorf("invalid Polygon coordinates: %w", err)
+		}
+		polygons = [][][][]float64{p}
+	case "MultiPolygon":
+		if err := json.Unmarshal(g.Coordinates, &polygons); err != nil {
+			return nil, fmt.Errorf("invalid MultiPolygon coordinates: %w", err)
+		}
+	default:
+		return nil, fmt.Errorf("%w %q", ErrUnsupportedGeometry, g.Type)
+	}
+
+	for _, p := range polygons {
+		for _, ring := range p {
+			for i, pos := range ring {
+				if len(pos) < 2 {
+					return nil, fmt.Errorf("invalid geometry: position %v needs easting and northing", pos)
+				}
+				lat, lng := zone.ToWGS84(pos[1], pos[0])
+				ring[i] = []float64{lng, lat}
+			}
+		}
+	}
+
+	var coordinates interface{} = polygons
+	if g.Type == "Polygon" {
+		coordinates = polygons[0]
+	}
+	return json.Marshal(map[string]interface{}{"type": g.Type, "coordinates": coordinates})
+}
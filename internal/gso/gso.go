@@ -0,0 +1,198 @@
+// Package gso imports the official General Statistics Office (GSO) code list and reconciles it
+// with the crawled provinces and units. Upstream codes (mahc, ma) usually equal the GSO codes,
+// but not always; each GSO entry is linked by code when the accent-folded names agree, and
+// otherwise by name alone. Entries that cannot be linked either way are reported, not guessed.
+package gso
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"vn-admin-api/internal/lineage"
+	"vn-admin-api/internal/models"
+	"vn-admin-api/internal/vntext"
+)
+
+// Store is the subset of storage.Store the importer needs
+type Store interface {
+	GetProvinces(ctx context.Context) ([]models.Province, error)
+	GetUnitsByProvince(ctx context.Context, provinceID int) ([]models.AdminUnit, error)
+	SetGSOCodes(ctx context.Context, provinces, units map[int]string) error
+}
+
+// Record kinds in a Mismatch
+const (
+	KindProvince = "province"
+	KindUnit     = "unit"
+)
+
+// Mismatch is a GSO entry that could not be linked to a stored record
+type Mismatch struct {
+	Kind    string `json:"kind"`
+	GSOCode string `json:"gso_code"`
+	Name    string `json:"name"`
+	Reason  string `json:"reason"`
+}
+
+func (m Mismatch) String() string {
+	return fmt.Sprintf("%s %s %q: %s", m.Kind, m.GSOCode, m.Name, m.Reason)
+}
+
+// Report summarizes an import
+type Report struct {
+	Entries    int        `json:"entries"`
+	Provinces  int        `json:"provinces"` // Provinces linked
+	Units      int        `json:"units"`     // Units linked
+	ByName     int        `json:"by_name"`   // Links made by name because the codes differ
+	Mismatches []Mismatch `json:"mismatches,omitempty"`
+	Missing    []string   `json:"missing,omitempty"` // Stored records no GSO entry links to
+}
+
+// Import links entries to stored records and, unless dryRun, saves the GSO codes
+func Import(ctx context.Context, store Store, entries []Entry, dryRun bool) (*Report, error) {
+	provinces, err := store.GetProvinces(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var units []models.AdminUnit
+	for _, p := range provinces {
+		list, err := store.GetUnitsByProvince(ctx, p.ID)
+		if err != nil {
+			return nil, err
+		}
+		units = append(units, list...)
+	}
+
+	report := &Report{Entries: len(entries)}
+	provinceCodes := make(map[int]string)
+	unitCodes := make(map[int]string)
+	linkedBy := make(map[string]int) // GSO province code -> province ID
+
+	provinceIdx := newIndex()
+	for _, p := range provinces {
+		provinceIdx.add(strconv.Itoa(p.Code), p.Name, 0, p.ID)
+	}
+	unitIdx := newIndex()
+	for _, u := range units {
+		unitIdx.add(u.Code, u.Name, u.ProvinceID, u.ID)
+	}
+
+	link := func(kind string, codes map[int]string, idx *index, code, name string, scope int) (int, bool) {
+		id, byName, reason := idx.match(code, name, scope)
+		if reason == "" {
+			if prev, ok := codes[id]; ok && prev != code {
+				reason = fmt.Sprintf("record %d is already linked to %s", id, prev)
+			}
+		}
+		if reason != "" {
+			report.Mismatches = append(report.Mismatches, Mismatch{Kind: kind, GSOCode: code, Name: name, Reason: reason})
+			return 0, false
+		}
+		if _, seen := codes[id]; !seen && byName {
+			report.ByName++
+		}
+		codes[id] = code
+		return id, true
+	}
+
+	for _, e := range entries {
+		provinceID, linked := linkedBy[e.ProvinceCode]
+		if !linked && e.ProvinceCode != "" {
+			if id, ok := link(KindProvince, provinceCodes, provinceIdx, e.ProvinceCode, e.ProvinceName, 0); ok {
+				provinceID = id
+			}
+			linkedBy[e.ProvinceCode] = provinceID // 0 when unlinked, so it is reported once
+		}
+		if e.UnitCode != "" {
+			// Unit names repeat across provinces: match names within the linked province
+			link(KindUnit, unitCodes, unitIdx, e.UnitCode, e.UnitName, provinceID)
+		}
+	}
+
+	report.Provinces, report.Units = len(provinceCodes), len(unitCodes)
+	for _, p := range provinces {
+		if _, ok := provinceCodes[p.ID]; !ok {
+			report.Missing = append(report.Missing, fmt.Sprintf("%s %d %s", KindProvince, p.ID, p.Name))
+		}
+	}
+	for _, u := range units {
+		if _, ok := unitCodes[u.ID]; !ok {
+			report.Missing = append(report.Missing, fmt.Sprintf("%s %d %s", KindUnit, u.ID, u.Name))
+		}
+	}
+
+	if dryRun {
+		return report, nil
+	}
+	if err := store.SetGSOCodes(ctx, provinceCodes, unitCodes); err != nil {
+		return report, err
+	}
+	return report, nil
+}
+
+// index finds records by normalized code and by folded name
+type index struct {
+	byCode map[string]indexed
+	byName map[string][]indexed
+}
+
+type indexed struct {
+	id, scope int
+	name      string // nameKey of the record
+}
+
+func newIndex() *index {
+	return &index{byCode: make(map[string]indexed), byName: make(map[string][]indexed)}
+}
+
+func (x *index) add(code, name string, scope, id int) {
+	rec := indexed{id: id, scope: scope, name: nameKey(name)}
+	if c := codeKey(code); c != "" {
+		x.byCode[c] = rec
+	}
+	x.byName[rec.name] = append(x.byName[rec.name], rec)
+}
+
+// match links a GSO code and name. Scope, when not 0, restricts name matches to records of
+// that province. An empty reason means success; byName reports that the codes differ.
+func (x *index) match(code, name string, scope int) (id int, byName bool, reason string) {
+	key := nameKey(name)
+	rec, codeFound := x.byCode[codeKey(code)]
+	if codeFound && rec.name == key {
+		return rec.id, false, ""
+	}
+
+	var named []indexed
+	for _, r := range x.byName[key] {
+		if scope == 0 || r.scope == scope {
+			named = append(named, r)
+		}
+	}
+	switch {
+	case key != "" && len(named) == 1:
+		return named[0].id, true, ""
+	case len(named) > 1:
+		return 0, false, fmt.Sprintf("name matches %d records", len(named))
+	case codeFound:
+		return 0, false, fmt.Sprintf("code belongs to record %d with a different name", rec.id)
+	default:
+		return 0, false, "no record with this code or name"
+	}
+}
+
+// codeKey compares codes without leading zeros ("01" = "1")
+func codeKey(code string) string {
+	code = strings.TrimSpace(code)
+	if trimmed := strings.TrimLeft(code, "0"); trimmed != "" {
+		return trimmed
+	}
+	return code
+}
+
+// nameKey compares names without case, diacritics, punctuation or the level word
+func nameKey(name string) string {
+	_, rest := lineage.SplitLevel(strings.TrimSpace(name))
+	return strings.Join(vntext.Tokens(rest), " ")
+}
@@ -0,0 +1,168 @@
+package gso
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+
+	"vn-admin-api/internal/models"
+	"vn-admin-api/internal/storage"
+)
+
+// seed returns a store holding the given provinces and units
+func seed(t *testing.T, provinces []models.Province, units []models.AdminUnit) *storage.MemoryStore {
+	t.Helper()
+	store := storage.NewMemoryStore()
+	for _, p := range provinces {
+		if err := store.UpsertProvince(context.Background(), p); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for _, u := range units {
+		if err := store.UpsertAdminUnit(context.Background(), u); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return store
+}
+
+const testCSV = `Danh mục đơn vị hành chính,,,
+Mã TP,Tỉnh / Thành Phố,Mã PX,Phường Xã
+01,Thành phố Hà Nội,00004,Phường Ba Đình
+01,Thành phố Hà Nội,09577,Xã Sơn Tây
+01,Thành phố Hà Nội,09901,Xã Tân Lập
+79,Thành phố Hồ Chí Minh,26740,Phường Bến Thành
+79,Thành phố Hồ Chí Minh,26999,Phường Không Có
+98,Tỉnh Không Có,,
+`
+
+func TestImport(t *testing.T) {
+	ctx := context.Background()
+	// Upstream codes of 102 and 103 differ from the GSO list, and 202 is not on it
+	store := seed(t, []models.Province{
+		{ID: 1, Name: "Thành phố Hà Nội", Code: 1},
+		{ID: 2, Name: "Thành phố Hồ Chí Minh", Code: 79},
+	}, []models.AdminUnit{
+		{ID: 101, ProvinceID: 1, Name: "Phường Ba Đình", Code: "00004"},
+		{ID: 102, ProvinceID: 1, Name: "Xã Sơn Tây", Code: "09574"},
+		{ID: 103, ProvinceID: 1, Name: "Xã Tân Lập", Code: "09900"},
+		{ID: 201, ProvinceID: 2, Name: "Phường Bến Thành", Code: "26740"},
+		{ID: 202, ProvinceID: 2, Name: "Xã Tân Lập", Code: "27000"},
+	})
+
+	entries, err := ReadCSV(strings.NewReader(testCSV))
+	if err != nil {
+		t.Fatalf("ReadCSV: %v", err)
+	}
+	if len(entries) != 6 {
+		t.Fatalf("Expected 6 entries, got %d", len(entries))
+	}
+
+	report, err := Import(ctx, store, entries, false)
+	if err != nil {
+		t.Fatalf("Import: %v", err)
+	}
+	if report.Provinces != 2 || report.Units != 4 || report.ByName != 2 {
+		t.Errorf("Unexpected counts %+v", report)
+	}
+	wantMismatches := []Mismatch{
+		{KindUnit, "26999", "Phường Không Có", "no record with this code or name"},
+		{KindProvince, "98", "Tỉnh Không Có", "no record with this code or name"},
+	}
+	if !reflect.DeepEqual(report.Mismatches, wantMismatches) {
+		t.Errorf("Expected mismatches %v, got %v", wantMismatches, report.Mismatches)
+	}
+	if !reflect.DeepEqual(report.Missing, []string{"unit 202 Xã Tân Lập"}) {
+		t.Errorf("Expected unit 202 missing, got %v", report.Missing)
+	}
+
+	// Sơn Tây and the Hà Nội Tân Lập were linked by name; their upstream codes are untouched
+	for id, want := range map[int]string{101: "00004", 102: "09577", 103: "09901", 201: "26740", 202: ""} {
+		u, err := store.GetAdminUnit(ctx, id)
+		if err != nil {
+			t.Fatalf("GetAdminUnit(%d): %v", id, err)
+		}
+		if u.GSOCode != want {
+			t.Errorf("Unit %d: expected GSO code %q, got %q", id, want, u.GSOCode)
+		}
+	}
+
+	// A re-crawl keeps the imported code
+	if err := store.UpsertAdminUnit(ctx, models.AdminUnit{ID: 102, ProvinceID: 1, Name: "Xã Sơn Tây", Code: "09574"}); err != nil {
+		t.Fatalf("UpsertAdminUnit: %v", err)
+	}
+	if u, _ := store.GetAdminUnit(ctx, 102); u.GSOCode != "09577" {
+		t.Errorf("Expected GSO code to survive the upsert, got %q", u.GSOCode)
+	}
+}
+
+func TestImportDryRun(t *testing.T) {
+	ctx := context.Background()
+	store := seed(t,
+		[]models.Province{{ID: 1, Name: "Thành phố Hà Nội", Code: 1}},
+		[]models.AdminUnit{{ID: 101, ProvinceID: 1, Name: "Phường Ba Đình", Code: "00004"}})
+	entries := []Entry{{ProvinceCode: "01", ProvinceName: "Thành phố Hà Nội", UnitCode: "00004", UnitName: "Phường Ba Đình"}}
+
+	if _, err := Import(ctx, store, entries, true); err != nil {
+		t.Fatalf("Import: %v", err)
+	}
+	if u, _ := store.GetAdminUnit(ctx, 101); u.GSOCode != "" {
+		t.Errorf("Expected dry run to store nothing, got %q", u.GSOCode)
+	}
+}
+
+func TestImportAmbiguousName(t *testing.T) {
+	// Without a linked province, "Xã Tân Lập" matches units in both provinces
+	store := seed(t, []models.Province{
+		{ID: 1, Name: "Thành phố Hà Nội", Code: 1},
+		{ID: 2, Name: "Thành phố Hồ Chí Minh", Code: 79},
+	}, []models.AdminUnit{
+		{ID: 103, ProvinceID: 1, Name: "Xã Tân Lập", Code: "09900"},
+		{ID: 202, ProvinceID: 2, Name: "Xã Tân Lập", Code: "27000"},
+	})
+	entries := []Entry{{ProvinceCode: "50", ProvinceName: "Tỉnh Khác", UnitCode: "12345", UnitName: "Xã Tân Lập"}}
+	report, err := Import(context.Background(), store, entries, true)
+	if err != nil {
+		t.Fatalf("Import: %v", err)
+	}
+	if len(report.Mismatches) != 2 || report.Mismatches[1].Reason != "name matches 2 records" {
+		t.Errorf("Expected an ambiguous unit, got %v", report.Mismatches)
+	}
+}
+
+func TestReadXLSX(t *testing.T) {
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	files := map[string]string{
+		"xl/sharedStrings.xml": `<sst xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main">` +
+			`<si><t>Mã TP</t></si><si><r><t>Tỉnh / </t></r><r><t>Thành Phố</t></r></si><si><t>Mã PX</t></si></sst>`,
+		"xl/worksheets/sheet1.xml": `<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main"><sheetData>` +
+			`<row r="1"><c r="A1" t="s"><v>0</v></c><c r="B1" t="s"><v>1</v></c><c r="C1" t="s"><v>2</v></c>` +
+			`<c r="D1" t="inlineStr"><is><t>Phường Xã</t></is></c></row>` +
+			`<row r="2"><c r="A2"><v>1</v></c><c r="B2" t="inlineStr"><is><t>Thành phố Hà Nội</t></is></c>` +
+			`<c r="C2"><v>4</v></c><c r="D2" t="inlineStr"><is><t>Phường Ba Đình</t></is></c></row>` +
+			`</sheetData></worksheet>`,
+	}
+	for name, content := range files {
+		w, err := zw.Create(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		w.Write([]byte(content))
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	entries, err := ReadXLSX(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatalf("ReadXLSX: %v", err)
+	}
+	want := []Entry{{ProvinceCode: "01", ProvinceName: "Thành phố Hà Nội", UnitCode: "00004", UnitName: "Phường Ba Đình"}}
+	if !reflect.DeepEqual(entries, want) {
+		t.Errorf("Expected %+v, got %+v", want, entries)
+	}
+}
@@ -0,0 +1,248 @@
+package gso
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/csv"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+
+	"vn-admin-api/internal/vntext"
+)
+
+// Entry is one row of the GSO code list: a province, or a unit with its province
+type Entry struct {
+	ProvinceCode string
+	ProvinceName string
+	UnitCode     string // Empty on province-only rows
+	UnitName     string
+}
+
+// Header names of each column, as token-folded text ("Tỉnh / Thành Phố" -> "tinh thanh pho").
+// They cover the exports of danhmuchanhchinh.gso.gov.vn before and after the 2025 reform.
+var columnHeaders = [4][]string{
+	{"ma tp", "ma tinh", "ma tinh tp", "ma tinh thanh pho"},
+	{"tinh thanh pho", "tinh tp", "ten tinh", "ten tinh thanh pho", "ten tinh tp", "tinh"},
+	{"ma px", "ma xa", "ma phuong xa", "ma xa phuong"},
+	{"phuong xa", "xa phuong", "ten px", "ten xa", "ten phuong xa", "ten xa phuong"},
+}
+
+// ReadFile reads a GSO code list from a .csv or .xlsx file
+func ReadFile(name string) ([]Entry, error) {
+	switch strings.ToLower(filepath.Ext(name)) {
+	case ".xlsx":
+		data, err := os.ReadFile(name)
+		if err != nil {
+			return nil, err
+		}
+		return ReadXLSX(bytes.NewReader(data), int64(len(data)))
+	case ".csv":
+		f, err := os.Open(name)
+		if err != nil {
+			return nil, err
+		}
+		defer f.Close()
+		return ReadCSV(f)
+	default:
+		return nil, fmt.Errorf("unsupported file type %q (expected .csv or .xlsx)", filepath.Ext(name))
+	}
+}
+
+// ReadCSV reads a comma-separated GSO code list with a header row
+func ReadCSV(r io.Reader) ([]Entry, error) {
+	cr := csv.NewReader(r)
+	cr.FieldsPerRecord = -1
+	cr.LazyQuotes = true
+	rows, err := cr.ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read CSV: %w", err)
+	}
+	return parseRows(rows)
+}
+
+// ReadXLSX reads the first worksheet of an Excel workbook
+func ReadXLSX(r io.ReaderAt, size int64) ([]Entry, error) {
+	zr, err := zip.NewReader(r, size)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open XLSX: %w", err)
+	}
+	rows, err := readFirstSheet(zr)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read XLSX: %w", err)
+	}
+	return parseRows(rows)
+}
+
+// parseRows finds the header row, then reads every row below it.
+// Excel drops leading zeros from numeric cells, so codes are padded back to their GSO width.
+func parseRows(rows [][]string) ([]Entry, error) {
+	for h, row := range rows {
+		cols := [4]int{-1, -1, -1, -1}
+		for i, cell := range row {
+			key := strings.Join(vntext.Tokens(cell), " ")
+			for c, names := range columnHeaders {
+				if cols[c] < 0 && slices.Contains(names, key) {
+					cols[c] = i
+				}
+			}
+		}
+		if cols[0] < 0 || cols[1] < 0 {
+			continue
+		}
+
+		get := func(row []string, c int) string {
+			if cols[c] < 0 || cols[c] >= len(row) {
+				return ""
+			}
+			return strings.TrimSpace(row[cols[c]])
+		}
+		entries := make([]Entry, 0, len(rows)-h-1)
+		for _, row := range rows[h+1:] {
+			e := Entry{
+				ProvinceCode: padCode(get(row, 0), 2),
+				ProvinceName: get(row, 1),
+				UnitCode:     padCode(get(row, 2), 5),
+				UnitName:     get(row, 3),
+			}
+			if e.ProvinceCode == "" && e.UnitCode == "" {
+				continue // blank or footer row
+			}
+			entries = append(entries, e)
+		}
+		return entries, nil
+	}
+	return nil, fmt.Errorf("no header row with province code and name columns found")
+}
+
+// padCode left-pads numeric codes with zeros to width ("1" -> "01")
+func padCode(code string, width int) string {
+	code = strings.TrimSuffix(code, ".0")
+	if code == "" || strings.Trim(code, "0123456789") != "" || len(code) >= width {
+		return code
+	}
+	return strings.Repeat("0", width-len(code)) + code
+}
+
+// Minimal SpreadsheetML: shared strings and the cells of one worksheet
+
+type xlsxText struct {
+	T    string `xml:"t"`
+	Runs []struct {
+		T string `xml:"t"`
+	} `xml:"r"`
+}
+
+func (t xlsxText) String() string {
+	var b strings.Builder
+	b.WriteString(t.T)
+	for _, r := range t.Runs {
+		b.WriteString(r.T)
+	}
+	return b.String()
+}
+
+type xlsxSharedStrings struct {
+	Items []xlsxText `xml:"si"`
+}
+
+type xlsxWorksheet struct {
+	Rows []struct {
+		Cells []struct {
+			Ref    string   `xml:"r,attr"`
+			Type   string   `xml:"t,attr"`
+			Value  string   `xml:"v"`
+			Inline xlsxText `xml:"is"`
+		} `xml:"c"`
+	} `xml:"sheetData>row"`
+}
+
+func readFirstSheet(zr *zip.Reader) ([][]string, error) {
+	var shared xlsxSharedStrings
+	var sheets []*zip.File
+	for _, f := range zr.File {
+		switch {
+		case f.Name == "xl/sharedStrings.xml":
+			if err := decodeZipXML(f, &shared); err != nil {
+				return nil, err
+			}
+		case path.Dir(f.Name) == "xl/worksheets" && path.Ext(f.Name) == ".xml":
+			sheets = append(sheets, f)
+		}
+	}
+	if len(sheets) == 0 {
+		return nil, fmt.Errorf("workbook has no worksheets")
+	}
+	// sheet1.xml, sheet2.xml, ...: the shortest name sorts first
+	sort.Slice(sheets, func(i, j int) bool {
+		a, b := sheets[i].Name, sheets[j].Name
+		return len(a) < len(b) || (len(a) == len(b) && a < b)
+	})
+
+	var ws xlsxWorksheet
+	if err := decodeZipXML(sheets[0], &ws); err != nil {
+		return nil, err
+	}
+	rows := make([][]string, 0, len(ws.Rows))
+	for _, r := range ws.Rows {
+		var row []string
+		for i, c := range r.Cells {
+			col := columnIndex(c.Ref)
+			if col < 0 {
+				col = i
+			}
+			for len(row) <= col {
+				row = append(row, "")
+			}
+			switch c.Type {
+			case "s":
+				var idx int
+				if _, err := fmt.Sscan(c.Value, &idx); err != nil || idx < 0 || idx >= len(shared.Items) {
+					return nil, fmt.Errorf("cell %s: invalid shared string index %q", c.Ref, c.Value)
+				}
+				row[col] = shared.Items[idx].String()
+			case "inlineStr":
+				row[col] = c.Inline.String()
+			default:
+				row[col] = c.Value
+			}
+		}
+		rows = append(rows, row)
+	}
+	return rows, nil
+}
+
+func decodeZipXML(f *zip.File, v interface{}) error {
+	rc, err := f.Open()
+	if err != nil {
+		return err
+	}
+	defer rc.Close()
+	if err := xml.NewDecoder(rc).Decode(v); err != nil {
+		return fmt.Errorf("%s: %w", f.Name, err)
+	}
+	return nil
+}
+
+// columnIndex converts the letters of a cell reference to a 0-based column ("C7" -> 2)
+func columnIndex(ref string) int {
+	col := 0
+	n := 0
+	for _, r := range ref {
+		if r < 'A' || r > 'Z' {
+			break
+		}
+		col = col*26 + int(r-'A'+1)
+		n++
+	}
+	if n == 0 {
+		return -1
+	}
+	return col - 1
+}
@@ -0,0 +1,214 @@
+package jobs
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"vn-admin-api/internal/convert"
+)
+
+// Supported upload formats
+const (
+	FormatCSV    = "csv"
+	FormatNDJSON = "ndjson"
+)
+
+// Columns appended to every output row
+var resultColumns = []string{
+	"new_province_id", "new_province_name", "new_unit_id", "new_unit_name", "match_quality", "split",
+}
+
+// Recognized input columns / NDJSON keys
+const (
+	colAddress  = "address"
+	colWard     = "ward"
+	colDistrict = "district"
+	colProvince = "province"
+)
+
+// ErrNoAddressColumn is returned when the input has neither an address nor a ward column
+var ErrNoAddressColumn = errors.New("input needs an \"address\" or \"ward\" column")
+
+// record is one input row with enough of the original to reproduce it in the output
+type record struct {
+	input  convert.Input
+	fields []string       // CSV: original fields
+	object map[string]any // NDJSON: original object
+}
+
+// parseInput decodes an uploaded file into records
+func parseInput(format string, data []byte) ([]record, []string, error) {
+	switch format {
+	case FormatCSV:
+		return parseCSV(data)
+	case FormatNDJSON:
+		recs, err := parseNDJSON(data)
+		return recs, nil, err
+	default:
+		return nil, nil, fmt.Errorf("unsupported format %q", format)
+	}
+}
+
+func parseCSV(data []byte) ([]record, []string, error) {
+	r := csv.NewReader(bytes.NewReader(bytes.TrimPrefix(data, []byte("\xef\xbb\xbf")))) // Excel BOM
+	r.FieldsPerRecord = -1
+
+	header, err := r.Read()
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to read CSV header: %w", err)
+	}
+	index := make(map[string]int)
+	for i, h := range header {
+		index[strings.ToLower(strings.TrimSpace(h))] = i
+	}
+	_, hasAddress := index[colAddress]
+	_, hasWard := index[colWard]
+	if !hasAddress && !hasWard {
+		return nil, nil, ErrNoAddressColumn
+	}
+
+	field := func(row []string, name string) string {
+		if i, ok := index[name]; ok && i < len(row) {
+			return strings.TrimSpace(row[i])
+		}
+		return ""
+	}
+
+	var recs []record
+	for line := 2; ; line++ {
+		row, err := r.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, nil, fmt.Errorf("CSV line %d: %w", line, err)
+		}
+		for len(row) < len(header) {
+			row = append(row, "") // keep result columns aligned
+		}
+		recs = append(recs, record{
+			input: convert.Input{
+				Address:  field(row, colAddress),
+				Ward:     field(row, colWard),
+				District: field(row, colDistrict),
+				Province: field(row, colProvince),
+			},
+			fields: row,
+		})
+	}
+	return recs, header, nil
+}
+
+func parseNDJSON(data []byte) ([]record, error) {
+	sc := bufio.NewScanner(bytes.NewReader(data))
+	sc.Buffer(make([]byte, 64*1024), 1024*1024)
+
+	var recs []record
+	for line := 1; sc.Scan(); line++ {
+		text := bytes.TrimSpace(sc.Bytes())
+		if len(text) == 0 {
+			continue
+		}
+		var obj map[string]any
+		if err := json.Unmarshal(text, &obj); err != nil {
+			return nil, fmt.Errorf("NDJSON line %d: %w", line, err)
+		}
+		str := func(key string) string {
+			s, _ := obj[key].(string)
+			return strings.TrimSpace(s)
+		}
+		recs = append(recs, record{
+			input: convert.Input{
+				Address:  str(colAddress),
+				Ward:     str(colWard),
+				District: str(colDistrict),
+				Province: str(colProvince),
+			},
+			object: obj,
+		})
+	}
+	if err := sc.Err(); err != nil {
+		return nil, err
+	}
+	return recs, nil
+}
+
+// resultWriter writes output rows in the job's format
+type resultWriter struct {
+	format string
+	buf    bytes.Buffer
+	csv    *csv.Writer
+}
+
+func newResultWriter(format string, header []string) (*resultWriter, error) {
+	w := &resultWriter{format: format}
+	if format == FormatCSV {
+		w.csv = csv.NewWriter(&w.buf)
+		if err := w.csv.Write(append(append([]string{}, header...), resultColumns...)); err != nil {
+			return nil, err
+		}
+	}
+	return w, nil
+}
+
+// write appends rec annotated with the conversion result (res may be nil when the row could not be converted)
+func (w *resultWriter) write(rec record, res *convert.Result) error {
+	provinceID, provinceName, unitID, unitName := "", "", "", ""
+	quality, split := convert.ConfidenceNone, false
+	if res != nil {
+		quality, split = res.Confidence, res.Split
+		if len(res.Matches) > 0 {
+			m := res.Matches[0]
+			unitID, unitName = strconv.Itoa(m.Unit.ID), m.Unit.Name
+			provinceID = strconv.Itoa(m.Unit.ProvinceID)
+			if m.Province != nil {
+				provinceName = m.Province.Name
+			}
+		}
+	}
+
+	if w.format == FormatCSV {
+		row := append(append([]string{}, rec.fields...),
+			provinceID, provinceName, unitID, unitName, quality, strconv.FormatBool(split))
+		return w.csv.Write(row)
+	}
+
+	obj := rec.object
+	obj["new_province_id"] = nullableInt(provinceID)
+	obj["new_province_name"] = provinceName
+	obj["new_unit_id"] = nullableInt(unitID)
+	obj["new_unit_name"] = unitName
+	obj["match_quality"] = quality
+	obj["split"] = split
+	line, err := json.Marshal(obj)
+	if err != nil {
+		return err
+	}
+	w.buf.Write(line)
+	w.buf.WriteByte('\n')
+	return nil
+}
+
+func (w *resultWriter) bytes() ([]byte, error) {
+	if w.csv != nil {
+		w.csv.Flush()
+		if err := w.csv.Error(); err != nil {
+			return nil, err
+		}
+	}
+	return w.buf.Bytes(), nil
+}
+
+func nullableInt(s string) any {
+	if n, err := strconv.Atoi(s); err == nil {
+		return n
+	}
+	return nil
+}
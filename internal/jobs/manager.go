@@ -0,0 +1,231 @@
+// Package jobs runs bulk address conversions (CSV / NDJSON uploads) on a bounded
+// worker pool. Job state lives in the Store, so queued and interrupted jobs are
+// picked up again after a restart.
+package jobs
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"vn-admin-api/internal/convert"
+	"vn-admin-api/internal/logger"
+	"vn-admin-api/internal/models"
+	"vn-admin-api/internal/storage"
+)
+
+const (
+	// pollInterval is how often idle workers look for queued jobs
+	pollInterval = 2 * time.Second
+	// staleAfter is how long a running job may go without a heartbeat before another worker takes it over
+	staleAfter = 2 * time.Minute
+	// maxAttempts is how many workers may go stale on a job before it is failed
+	maxAttempts = 3
+	// progressEvery is how many rows are processed between progress/heartbeat updates
+	progressEvery = 500
+)
+
+// Store is the subset of storage.Store the job manager needs
+type Store interface {
+	convert.Store
+	CreateJob(ctx context.Context, job models.Job, input []byte) error
+	ClaimJob(ctx context.Context, staleAfter time.Duration, maxAttempts int) (*models.Job, []byte, error)
+	UpdateJobProgress(ctx context.Context, id string, attempt, processed, matched int) error
+	CompleteJob(ctx context.Context, id string, attempt, processed, matched int, result []byte) error
+	FailJob(ctx context.Context, id string, attempt int, message string) error
+	ReleaseJob(ctx context.Context, id string, attempt int) error
+}
+
+// ErrInvalidInput wraps errors caused by a malformed upload
+var ErrInvalidInput = errors.New("invalid input")
+
+// Manager accepts jobs and processes them on a fixed number of workers
+type Manager struct {
+	store     Store
+	converter *convert.Converter
+	log       *logger.Logger
+	workers   int
+	wake      chan struct{}
+	wg        sync.WaitGroup
+}
+
+func NewManager(store Store, log *logger.Logger, workers int) *Manager {
+	if workers < 1 {
+		workers = 1
+	}
+	return &Manager{
+		store:     store,
+		converter: convert.New(store),
+		log:       log,
+		workers:   workers,
+		wake:      make(chan struct{}, workers),
+	}
+}
+
+// Start launches the workers. They stop when ctx is cancelled; call Wait to block until they have.
+func (m *Manager) Start(ctx context.Context) {
+	for i := 0; i < m.workers; i++ {
+		m.wg.Add(1)
+		go func() {
+			defer m.wg.Done()
+			m.work(ctx)
+		}()
+	}
+	m.log.Info("Job workers started", "workers", m.workers)
+}
+
+// Wait blocks until all workers have exited
+func (m *Manager) Wait() {
+	m.wg.Wait()
+}
+
+// Submit validates an uploaded file and queues it as a new job
+func (m *Manager) Submit(ctx context.Context, format string, data []byte) (*models.Job, error) {
+	recs, _, err := parseInput(format, data)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidInput, err)
+	}
+	if len(recs) == 0 {
+		return nil, fmt.Errorf("%w: no rows", ErrInvalidInput)
+	}
+
+	id, err := newJobID()
+	if err != nil {
+		return nil, err
+	}
+	job := models.Job{
+		ID:        id,
+		Status:    models.JobQueued,
+		Format:    format,
+		Total:     len(recs),
+		CreatedAt: time.Now().UTC(),
+	}
+	if err := m.store.CreateJob(ctx, job, data); err != nil {
+		return nil, err
+	}
+
+	// Nudge an idle worker; if all are busy the job is found on the next poll
+	select {
+	case m.wake <- struct{}{}:
+	default:
+	}
+	return &job, nil
+}
+
+func (m *Manager) work(ctx context.Context) {
+	ticker := time.NewTicker(pollInterval)
+	defer ticker.Stop()
+
+	for {
+		// Drain the queue before waiting again
+		for ctx.Err() == nil {
+			job, input, err := m.store.ClaimJob(ctx, staleAfter, maxAttempts)
+			if err != nil {
+				if !errors.Is(err, storage.ErrNotFound) && ctx.Err() == nil {
+					m.log.Error("Failed to claim job", "error", err)
+				}
+				break
+			}
+			m.run(ctx, job, input)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-m.wake:
+		case <-ticker.C:
+		}
+	}
+}
+
+// run processes one claimed job to completion, failure, or shutdown
+func (m *Manager) run(ctx context.Context, job *models.Job, input []byte) {
+	m.log.Info("Job started", "job_id", job.ID, "format", job.Format, "total", job.Total)
+	start := time.Now()
+
+	processed, matched, result, err := m.process(ctx, job, input)
+	// The final write must land even when shutdown cancels ctx, or the finished job runs again
+	final := context.WithoutCancel(ctx)
+	switch {
+	case errors.Is(err, storage.ErrJobLost):
+		m.log.Warn("Job taken over by another worker", "job_id", job.ID, "processed", processed)
+	case ctx.Err() != nil:
+		// Shutting down: hand the job back so the next start picks it up
+		if err := m.store.ReleaseJob(final, job.ID, job.Attempt); err != nil {
+			m.log.Error("Failed to release job", "job_id", job.ID, "error", err)
+		}
+		m.log.Info("Job interrupted", "job_id", job.ID, "processed", processed)
+	case err != nil:
+		if err := m.store.FailJob(final, job.ID, job.Attempt, err.Error()); err != nil {
+			m.log.Error("Failed to mark job failed", "job_id", job.ID, "error", err)
+		}
+		m.log.Error("Job failed", "job_id", job.ID, "error", err)
+	default:
+		if err := m.store.CompleteJob(final, job.ID, job.Attempt, processed, matched, result); err != nil {
+			m.log.Error("Failed to complete job", "job_id", job.ID, "error", err)
+			return
+		}
+		m.log.Info("Job completed", "job_id", job.ID, "processed", processed, "matched", matched,
+			"duration", time.Since(start))
+	}
+}
+
+func (m *Manager) process(ctx context.Context, job *models.Job, input []byte) (int, int, []byte, error) {
+	recs, header, err := parseInput(job.Format, input)
+	if err != nil {
+		return 0, 0, nil, err
+	}
+	out, err := newResultWriter(job.Format, header)
+	if err != nil {
+		return 0, 0, nil, err
+	}
+
+	// Spreadsheets repeat the same addresses a lot
+	memo := make(map[convert.Input]*convert.Result)
+	processed, matched := 0, 0
+
+	for _, rec := range recs {
+		if ctx.Err() != nil {
+			return processed, matched, nil, ctx.Err()
+		}
+
+		res, ok := memo[rec.input]
+		if !ok {
+			res, err = m.converter.Convert(ctx, rec.input)
+			if err != nil && !errors.Is(err, convert.ErrMissingWard) {
+				return processed, matched, nil, fmt.Errorf("row %d: %w", processed+1, err)
+			}
+			memo[rec.input] = res
+		}
+		if res != nil && len(res.Matches) > 0 {
+			matched++
+		}
+		if err := out.write(rec, res); err != nil {
+			return processed, matched, nil, err
+		}
+
+		processed++
+		if processed%progressEvery == 0 {
+			if err := m.store.UpdateJobProgress(ctx, job.ID, job.Attempt, processed, matched); errors.Is(err, storage.ErrJobLost) {
+				return processed, matched, nil, err
+			} else if err != nil {
+				m.log.Warn("Failed to update job progress", "job_id", job.ID, "error", err)
+			}
+		}
+	}
+
+	result, err := out.bytes()
+	return processed, matched, result, err
+}
+
+func newJobID() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate job id: %w", err)
+	}
+	return hex.EncodeToString(b), nil
+}
@@ -0,0 +1,68 @@
+// Package levels is the controlled taxonomy of administrative levels. Upstream data labels each
+// unit in Vietnamese ("Phường", "Xã", "Đặc khu"); the taxonomy gives every level a stable code
+// clients can branch on, with Vietnamese and English names. The unit_levels table mirrors it.
+package levels
+
+import (
+	"strings"
+
+	"vn-admin-api/internal/vntext"
+)
+
+// Level codes
+const (
+	Municipality  = "municipality"   // Thành phố trực thuộc trung ương
+	Province      = "province"       // Tỉnh
+	City          = "city"           // Thành phố (district-level, before 2025)
+	Town          = "town"           // Thị xã (before 2025)
+	UrbanDistrict = "urban_district" // Quận (before 2025)
+	District      = "district"       // Huyện (before 2025)
+	Ward          = "ward"           // Phường
+	Commune       = "commune"        // Xã
+	Township      = "township"       // Thị trấn (before 2025)
+	SpecialZone   = "special_zone"   // Đặc khu
+)
+
+// Level is one entry of the taxonomy
+type Level struct {
+	Code   string `json:"code"`
+	NameVI string `json:"name_vi"`
+	NameEN string `json:"name_en"`
+}
+
+// All lists the levels from the highest tier to the lowest
+var All = []Level{
+	{Municipality, "Thành phố trực thuộc trung ương", "Municipality"},
+	{Province, "Tỉnh", "Province"},
+	{City, "Thành phố", "City"},
+	{Town, "Thị xã", "Town"},
+	{UrbanDistrict, "Quận", "Urban district"},
+	{District, "Huyện", "District"},
+	{Ward, "Phường", "Ward"},
+	{Commune, "Xã", "Commune"},
+	{Township, "Thị trấn", "Township"},
+	{SpecialZone, "Đặc khu", "Special zone"},
+}
+
+// byKey indexes levels by code and by folded Vietnamese name
+var byKey = func() map[string]Level {
+	m := make(map[string]Level, 2*len(All))
+	for _, l := range All {
+		m[l.Code] = l
+		m[vntext.Fold(l.NameVI)] = l
+	}
+	return m
+}()
+
+// Lookup finds a level by code or by Vietnamese name, ignoring case, diacritics and extra spaces
+// ("ward", "Phường" and "phuong" all find Ward)
+func Lookup(s string) (Level, bool) {
+	l, ok := byKey[vntext.Fold(strings.Join(strings.Fields(s), " "))]
+	return l, ok
+}
+
+// Code returns the level code for an upstream label, or "" when the label is unknown
+func Code(label string) string {
+	l, _ := Lookup(label)
+	return l.Code
+}
@@ -0,0 +1,32 @@
+package levels
+
+import "testing"
+
+func TestLookup(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{"Phường", Ward},
+		{"phuong", Ward},
+		{"  XÃ ", Commune},
+		{"Đặc khu", SpecialZone},
+		{"dac  khu", SpecialZone},
+		{"Thị trấn", Township},
+		{"special_zone", SpecialZone},
+		{"Ward", Ward},
+		{"Thôn", ""},
+		{"", ""},
+	}
+	for _, tt := range tests {
+		if got := Code(tt.in); got != tt.want {
+			t.Errorf("Code(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestAllUnique(t *testing.T) {
+	if len(byKey) != 2*len(All) {
+		t.Errorf("Expected %d distinct codes and names, got %d", 2*len(All), len(byKey))
+	}
+}
@@ -0,0 +1,349 @@
+// Package lineage parses the free-text pre-merger description (truocsapnhap) into
+// structured predecessor units, e.g. "Xã A, Xã B và một phần Xã C (huyện X, tỉnh Y)".
+package lineage
+
+import (
+	"strings"
+	"unicode"
+
+	"vn-admin-api/internal/models"
+	"vn-admin-api/internal/vntext"
+)
+
+// Administrative levels as they appear in descriptions, longest first so
+// "thị trấn" wins over a shorter prefix. Matching is on lowercase text with diacritics,
+// which keeps "Xã" distinct from names such as "Xa Lý".
+var unitLevels = []struct {
+	word  string
+	label string
+}{
+	{"thị trấn", "Thị trấn"},
+	{"thị xã", "Thị xã"},
+	{"thành phố", "Thành phố"},
+	{"đặc khu", "Đặc khu"},
+	{"phường", "Phường"},
+	{"xã", "Xã"},
+	{"huyện", "Huyện"},
+	{"quận", "Quận"},
+	{"tỉnh", "Tỉnh"},
+}
+
+// Province-level cities; any other "thành phố" is a district-level city
+var centralCities = map[string]bool{
+	"ha noi":      true,
+	"ho chi minh": true,
+	"hai phong":   true,
+	"da nang":     true,
+	"can tho":     true,
+	"hue":         true,
+}
+
+// Phrases that qualify an item rather than name it. Longer phrases come first.
+var partialPrefixes = []string{"một phần còn lại của", "phần còn lại của", "một phần của", "một phần"}
+var fillerPrefixes = []string{"toàn bộ", "diện tích tự nhiên", "quy mô dân số", "và", "của", "các"}
+
+// Parse extracts the predecessor units from a pre-merger description.
+// Items without a recognizable level (e.g. "Giữ nguyên hiện trạng") are skipped.
+func Parse(desc string) []models.Predecessor {
+	desc = strings.Join(strings.Fields(desc), " ")
+	desc = strings.TrimRight(desc, ". ")
+	// "diện tích tự nhiên và quy mô dân số của X" must not be split on "và"
+	desc = replaceFold(desc, "diện tích tự nhiên và quy mô dân số", "diện tích tự nhiên")
+	desc = replaceFold(desc, "diện tích tự nhiên, quy mô dân số", "diện tích tự nhiên")
+
+	preds := make([]models.Predecessor, 0)
+	level := ""       // carried over: "Xã A, B và C" are all communes
+	runStart := 0     // first item not yet covered by a district/province context
+	prevRunStart := 0 // start of the list the last context applied to
+
+	for _, seg := range splitItems(desc) {
+		text, context := extractContext(seg)
+		var p models.Predecessor
+
+		words := strings.Fields(text)
+		for {
+			if n := matchPrefix(words, partialPrefixes); n > 0 {
+				p.Partial = true
+				words = words[n:]
+				continue
+			}
+			if n := matchPrefix(words, fillerPrefixes); n > 0 {
+				words = words[n:]
+				continue
+			}
+			break
+		}
+
+		// "thuộc huyện X" / "(huyện X, tỉnh Y)" describe where the old unit was
+		if i := indexWord(words, "thuộc"); i >= 0 {
+			context = append(context, strings.Join(words[i+1:], " "))
+			words = words[:i]
+		}
+
+		if lbl, n := matchLevel(words); n > 0 {
+			if lbl == "Tỉnh" {
+				// A bare "tỉnh Y" item continues the previous item's location
+				context = append(context, strings.Join(words, " "))
+				words = nil
+			} else {
+				level = lbl
+				words = words[n:]
+			}
+		}
+		if len(words) > 0 && strings.EqualFold(words[len(words)-1], "cũ") {
+			words = words[:len(words)-1]
+		}
+
+		district, province := parseContext(context)
+		if district == "" && province == "" {
+			if len(words) > 0 && level != "" {
+				p.Name = strings.Join(words, " ")
+				p.Level = level
+				preds = append(preds, p)
+			}
+			continue
+		}
+
+		start := runStart
+		if len(words) > 0 && level != "" {
+			p.Name = strings.Join(words, " ")
+			p.Level = level
+			preds = append(preds, p)
+		} else if runStart == len(preds) {
+			// Context-only item ("..., tỉnh Y") extends the previous list's location
+			start = prevRunStart
+		}
+
+		// Context written after a list applies to every item of that list
+		for i := start; i < len(preds); i++ {
+			if preds[i].District == "" {
+				preds[i].District = district
+			}
+			if preds[i].Province == "" {
+				preds[i].Province = province
+			}
+		}
+		prevRunStart, runStart = start, len(preds)
+	}
+	return preds
+}
+
+// splitItems splits on ",", ";" and the conjunction "và" outside parentheses
+func splitItems(s string) []string {
+	var items []string
+	var cur []rune
+	depth := 0
+	runes := []rune(s)
+
+	flush := func() {
+		if item := strings.TrimSpace(string(cur)); item != "" {
+			items = append(items, item)
+		}
+		cur = cur[:0]
+	}
+
+	for i := 0; i < len(runes); i++ {
+		r := runes[i]
+		switch {
+		case r == '(':
+			depth++
+		case r == ')' && depth > 0:
+			depth--
+		case depth == 0 && (r == ',' || r == ';'):
+			flush()
+			continue
+		case depth == 0 && r == ' ' && hasWordAt(runes, i+1, "và"):
+			flush()
+			i += len([]rune("và"))
+			continue
+		}
+		cur = append(cur, r)
+	}
+	flush()
+	return items
+}
+
+// hasWordAt reports whether word (case-insensitive) starts at runes[i] and is followed by a space or the end
+func hasWordAt(runes []rune, i int, word string) bool {
+	w := []rune(word)
+	if i+len(w) > len(runes) {
+		return false
+	}
+	for j, r := range w {
+		if unicode.ToLower(runes[i+j]) != r {
+			return false
+		}
+	}
+	return i+len(w) == len(runes) || runes[i+len(w)] == ' '
+}
+
+// extractContext removes parenthesised parts and returns them separately
+func extractContext(seg string) (string, []string) {
+	var text strings.Builder
+	var context []string
+	for {
+		open := strings.IndexRune(seg, '(')
+		if open < 0 {
+			text.WriteString(seg)
+			break
+		}
+		close := strings.IndexRune(seg[open:], ')')
+		if close < 0 {
+			text.WriteString(seg[:open])
+			context = append(context, seg[open+1:])
+			break
+		}
+		text.WriteString(seg[:open])
+		context = append(context, seg[open+1:open+close])
+		seg = seg[open+close+1:]
+	}
+	return strings.TrimSpace(text.String()), context
+}
+
+// parseContext reads "huyện X, tỉnh Y" style fragments into a district and a province
+func parseContext(parts []string) (district, province string) {
+	var fragments []string
+	for _, p := range parts {
+		fragments = append(fragments, splitItems(p)...)
+	}
+
+	for i, f := range fragments {
+		words := strings.Fields(f)
+		for len(words) > 0 {
+			if n := matchPrefix(words, []string{"thuộc", "của", "trước đây", "cũ"}); n > 0 {
+				words = words[n:]
+				continue
+			}
+			break
+		}
+		if len(words) > 0 && strings.EqualFold(words[len(words)-1], "cũ") {
+			words = words[:len(words)-1]
+		}
+
+		lbl, n := matchLevel(words)
+		if n == 0 || n == len(words) {
+			continue
+		}
+		name := strings.Join(words[n:], " ")
+		full := lbl + " " + name
+
+		switch lbl {
+		case "Tỉnh":
+			province = full
+		case "Huyện", "Quận", "Thị xã":
+			district = full
+		case "Thành phố":
+			// Province-level when it is a central city or the last fragment after a district
+			last := i == len(fragments)-1
+			if IsCentralCity(name) || (last && district != "") {
+				province = full
+			} else {
+				district = full
+			}
+		}
+	}
+	return district, province
+}
+
+// SplitLevel separates a leading level word from a unit name
+// ("Phường Trúc Bạch" -> "Phường", "Trúc Bạch"). Input typed entirely without
+// diacritics ("phuong truc bach") is accepted as well; accented input is matched
+// strictly so "Xa Lộ Hà Nội" is not read as a commune.
+func SplitLevel(name string) (level, rest string) {
+	words := strings.Fields(name)
+	lbl, n := matchLevel(words)
+	if n == 0 && !vntext.HasDiacritics(name) {
+		lbl, n = matchLevelFolded(words)
+	}
+	if n == 0 || n == len(words) {
+		return "", strings.Join(words, " ")
+	}
+	return lbl, strings.Join(words[n:], " ")
+}
+
+// IsCentralCity reports whether a "thành phố" name is a province-level city
+func IsCentralCity(name string) bool {
+	return centralCities[vntext.Fold(name)]
+}
+
+// matchLevel returns the canonical level label and how many words it spans
+func matchLevel(words []string) (string, int) {
+	for _, l := range unitLevels {
+		if n := matchPhrase(words, l.word); n > 0 {
+			return l.label, n
+		}
+	}
+	return "", 0
+}
+
+// matchLevelFolded is matchLevel ignoring diacritics
+func matchLevelFolded(words []string) (string, int) {
+	for _, l := range unitLevels {
+		pw := strings.Fields(vntext.Fold(l.word))
+		if len(words) < len(pw) {
+			continue
+		}
+		matched := true
+		for i, w := range pw {
+			if vntext.Fold(words[i]) != w {
+				matched = false
+				break
+			}
+		}
+		if matched {
+			return l.label, len(pw)
+		}
+	}
+	return "", 0
+}
+
+// matchPrefix returns the word count of the first phrase that starts words
+func matchPrefix(words []string, phrases []string) int {
+	for _, p := range phrases {
+		if n := matchPhrase(words, p); n > 0 {
+			return n
+		}
+	}
+	return 0
+}
+
+func matchPhrase(words []string, phrase string) int {
+	pw := strings.Fields(phrase)
+	if len(words) < len(pw) {
+		return 0
+	}
+	for i, w := range pw {
+		if strings.ToLower(words[i]) != w {
+			return 0
+		}
+	}
+	return len(pw)
+}
+
+func indexWord(words []string, word string) int {
+	for i, w := range words {
+		if strings.ToLower(w) == word {
+			return i
+		}
+	}
+	return -1
+}
+
+// replaceFold replaces old (lowercase) in s case-insensitively
+func replaceFold(s, old, new string) string {
+	lower := strings.ToLower(s)
+	if len(lower) != len(s) {
+		return s // case mapping changed byte offsets; leave untouched
+	}
+	var b strings.Builder
+	for {
+		i := strings.Index(lower, old)
+		if i < 0 {
+			b.WriteString(s)
+			return b.String()
+		}
+		b.WriteString(s[:i])
+		b.WriteString(new)
+		s, lower = s[i+len(old):], lower[i+len(old):]
+	}
+}
@@ -0,0 +1,72 @@
+package lineage
+
+import (
+	"reflect"
+	"testing"
+
+	"vn-admin-api/internal/models"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name string
+		desc string
+		want []models.Predecessor
+	}{
+		{
+			name: "list with partial",
+			desc: "Xã A, Xã B và một phần Xã C",
+			want: []models.Predecessor{
+				{Name: "A", Level: "Xã"},
+				{Name: "B", Level: "Xã"},
+				{Name: "C", Level: "Xã", Partial: true},
+			},
+		},
+		{
+			name: "level carried over",
+			desc: "Phường Phúc Xá, Trúc Bạch và Quán Thánh",
+			want: []models.Predecessor{
+				{Name: "Phúc Xá", Level: "Phường"},
+				{Name: "Trúc Bạch", Level: "Phường"},
+				{Name: "Quán Thánh", Level: "Phường"},
+			},
+		},
+		{
+			name: "parenthesised location applies to the list before it",
+			desc: "Xã Tân An, Thị trấn Tân Hòa (huyện Ba Vì, thành phố Hà Nội) và xã Đường Lâm (thị xã Sơn Tây)",
+			want: []models.Predecessor{
+				{Name: "Tân An", Level: "Xã", District: "Huyện Ba Vì", Province: "Thành phố Hà Nội"},
+				{Name: "Tân Hòa", Level: "Thị trấn", District: "Huyện Ba Vì", Province: "Thành phố Hà Nội"},
+				{Name: "Đường Lâm", Level: "Xã", District: "Thị xã Sơn Tây"},
+			},
+		},
+		{
+			name: "inline location and legal wording",
+			desc: "Toàn bộ diện tích tự nhiên và quy mô dân số của xã Hòa Bình thuộc huyện Kim Bôi, tỉnh Hòa Bình cũ; một phần còn lại của xã Vĩnh Đồng.",
+			want: []models.Predecessor{
+				{Name: "Hòa Bình", Level: "Xã", District: "Huyện Kim Bôi", Province: "Tỉnh Hòa Bình"},
+				{Name: "Vĩnh Đồng", Level: "Xã", Partial: true},
+			},
+		},
+		{
+			name: "district-level city",
+			desc: "Phường Lê Lợi (thành phố Quy Nhơn, tỉnh Bình Định)",
+			want: []models.Predecessor{
+				{Name: "Lê Lợi", Level: "Phường", District: "Thành phố Quy Nhơn", Province: "Tỉnh Bình Định"},
+			},
+		},
+		{
+			name: "no structure",
+			desc: "Giữ nguyên hiện trạng",
+			want: []models.Predecessor{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Parse(tt.desc); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Parse(%q)\n got  %+v\n want %+v", tt.desc, got, tt.want)
+			}
+		})
+	}
+}
@@ -1,24 +1,236 @@
 package models
 
-import "time"
+import (
+	"encoding/json"
+	"time"
+)
 
 // Province represents the payload from /pcotinh
 type Province struct {
-	ID        int       `json:"id" db:"id"`
-	Name      string    `json:"tentinh" db:"name"`
-	Code      int       `json:"mahc" db:"code"` // Note: API sometimes uses int for code in list
-	UpdatedAt time.Time `json:"updated_at" db:"updated_at"`
+	ID        int        `json:"id" db:"id"`
+	Name      string     `json:"tentinh" db:"name"`
+	Code      int        `json:"mahc" db:"code"`         // Note: API sometimes uses int for code in list
+	GSOCode   string     `json:"gso_code" db:"gso_code"` // Official GSO code, set by the crosswalk import
+	UpdatedAt time.Time  `json:"updated_at" db:"updated_at"`
+	RetiredAt *time.Time `json:"retired_at,omitempty" db:"retired_at"` // Set when it disappeared upstream
 }
 
 // AdminUnit represents the payload from /ptracuu (Wards)
 type AdminUnit struct {
-	ID            int       `json:"id" db:"id"`
-	ProvinceID    int       `json:"matinh" db:"province_id"`
-	Name          string    `json:"tenhc" db:"name"`
-	Level         string    `json:"loai" db:"level"`
-	Code          string    `json:"ma" db:"code"`
-	PreMergerDesc string    `json:"truocsapnhap" db:"pre_merger_desc"`
-	Lat           float64   `json:"vido" db:"lat"`
-	Long          float64   `json:"kinhdo" db:"long"`
-	UpdatedAt     time.Time `json:"updated_at" db:"updated_at"`
+	ID            int        `json:"id" db:"id"`
+	ProvinceID    int        `json:"matinh" db:"province_id"`
+	Name          string     `json:"tenhc" db:"name"`
+	Level         string     `json:"loai" db:"level"`            // Upstream label, e.g. "Phường"
+	LevelCode     string     `json:"level_code" db:"level_code"` // levels code, "" when the label is unknown
+	Code          string     `json:"ma" db:"code"`
+	GSOCode       string     `json:"gso_code" db:"gso_code"` // Official GSO code, set by the crosswalk import
+	PreMergerDesc string     `json:"truocsapnhap" db:"pre_merger_desc"`
+	Lat           float64    `json:"vido" db:"lat"`
+	Long          float64    `json:"kinhdo" db:"long"`
+	UpdatedAt     time.Time  `json:"updated_at" db:"updated_at"`
+	RetiredAt     *time.Time `json:"retired_at,omitempty" db:"retired_at"` // Set when it disappeared upstream
+}
+
+// ProvinceVersion is one state of a province, valid from ValidFrom until ValidTo (nil while current)
+type ProvinceVersion struct {
+	Province
+	ValidFrom time.Time  `json:"valid_from" db:"valid_from"`
+	ValidTo   *time.Time `json:"valid_to" db:"valid_to"`
+}
+
+// ValidAt reports whether the version was the current state at t
+func (v ProvinceVersion) ValidAt(t time.Time) bool {
+	return validAt(v.ValidFrom, v.ValidTo, t)
+}
+
+// UnitVersion is one state of a unit, valid from ValidFrom until ValidTo (nil while current)
+type UnitVersion struct {
+	AdminUnit
+	ValidFrom time.Time  `json:"valid_from" db:"valid_from"`
+	ValidTo   *time.Time `json:"valid_to" db:"valid_to"`
+}
+
+// ValidAt reports whether the version was the current state at t
+func (v UnitVersion) ValidAt(t time.Time) bool {
+	return validAt(v.ValidFrom, v.ValidTo, t)
+}
+
+func validAt(from time.Time, to *time.Time, t time.Time) bool {
+	return !from.After(t) && (to == nil || to.After(t))
+}
+
+// SearchParams describes a unit search request
+type SearchParams struct {
+	Query    string
+	Limit    int
+	Fuzzy    bool    // Also return typo-tolerant (trigram) matches
+	MinScore float64 // Minimum trigram similarity for fuzzy matches
+	Level    string  // Only units with this level code
+
+	IncludeRetired bool // Also units retired (gone upstream)
+}
+
+// Sort keys for UnitQuery.Sort
+const (
+	UnitSortID        = "id"
+	UnitSortName      = "name"
+	UnitSortUpdatedAt = "updated_at"
+)
+
+// UnitQuery filters and pages the unit collection. Paging is keyset-based: After is the
+// position of the last unit of the previous page under the same Sort and Desc.
+type UnitQuery struct {
+	ProvinceID     int
+	Level          string // Level code (levels.Ward, ...)
+	Name           string // Substring of the name, without case or diacritics
+	HasCoordinates *bool
+	UpdatedSince   time.Time
+	Sort           string // UnitSortID (default), UnitSortName or UnitSortUpdatedAt
+	Desc           bool
+	Limit          int
+	After          *UnitCursor
+	IncludeRetired bool // Also units retired (gone upstream)
+}
+
+// UnitCursor is a keyset position: the sort key and ID of a unit
+type UnitCursor struct {
+	Name      string    `json:"n,omitempty"`
+	UpdatedAt time.Time `json:"u,omitempty"`
+	ID        int       `json:"i"`
+}
+
+// Match types reported in SearchResult.Match
+const (
+	MatchExact = "exact" // Every query word matched a word of the name or pre-merger description
+	MatchFuzzy = "fuzzy" // Name is trigram-similar to the query
+)
+
+// SearchResult is an AdminUnit annotated with its relevance score (0..1)
+type SearchResult struct {
+	AdminUnit
+	Score float64 `json:"score"`
+	Match string  `json:"match"`
+}
+
+// NearbyUnit is an AdminUnit annotated with its distance from a queried coordinate
+type NearbyUnit struct {
+	AdminUnit
+	DistanceKm float64 `json:"distance_km"`
+}
+
+// Boundary kinds
+const (
+	BoundaryProvince = "province"
+	BoundaryUnit     = "unit"
+)
+
+// Boundary is the outline of a province or unit, imported from GeoJSON
+type Boundary struct {
+	Kind      string          `json:"kind"`                   // "province" or "unit"
+	RefID     int             `json:"ref_id" db:"ref_id"`     // provinces.id or admin_units.id
+	Geometry  json.RawMessage `json:"geometry" db:"geometry"` // GeoJSON Polygon or MultiPolygon (WGS84)
+	UpdatedAt time.Time       `json:"updated_at" db:"updated_at"`
+}
+
+// Record kinds an external ID refers to
+const (
+	RefKindProvince = "province"
+	RefKindUnit     = "unit"
+)
+
+// ExternalID maps a province or unit to its identifier in an outside system (e.g. a shipping carrier).
+// An external ID names exactly one record per system; a record may have several per system.
+type ExternalID struct {
+	System     string `json:"system" db:"system"`           // e.g. "ghn", "ghtk", "viettel_post"
+	ExternalID string `json:"external_id" db:"external_id"` // As the system spells it
+	Kind       string `json:"kind"`                         // RefKindProvince or RefKindUnit
+	RefID      int    `json:"ref_id"`                       // provinces.id or admin_units.id
+}
+
+// Location is the province and unit whose boundaries contain a point
+type Location struct {
+	Province *Province  `json:"province"`
+	Unit     *AdminUnit `json:"unit"`
+}
+
+// Predecessor is a pre-merger unit that became (part of) an AdminUnit, parsed from truocsapnhap
+type Predecessor struct {
+	UnitID   int    `json:"unit_id" db:"unit_id"`
+	Name     string `json:"old_name" db:"old_name"`
+	Level    string `json:"old_level" db:"old_level"`
+	District string `json:"old_district,omitempty" db:"old_district"`
+	Province string `json:"old_province,omitempty" db:"old_province"`
+	Partial  bool   `json:"partial" db:"partial"` // Only part of the old unit merged in
+}
+
+// Job statuses
+const (
+	JobQueued    = "queued"
+	JobRunning   = "running"
+	JobCompleted = "completed"
+	JobFailed    = "failed"
+)
+
+// Job is a bulk address-conversion job
+type Job struct {
+	ID         string     `json:"id" db:"id"`
+	Status     string     `json:"status" db:"status"`
+	Format     string     `json:"format" db:"format"` // csv or ndjson
+	Total      int        `json:"total" db:"total"`
+	Processed  int        `json:"processed" db:"processed"`
+	Matched    int        `json:"matched" db:"matched"`
+	Error      string     `json:"error,omitempty" db:"error"`
+	CreatedAt  time.Time  `json:"created_at" db:"created_at"`
+	StartedAt  *time.Time `json:"started_at,omitempty" db:"started_at"`
+	FinishedAt *time.Time `json:"finished_at,omitempty" db:"finished_at"`
+	Attempt    int        `json:"-" db:"attempt"` // Bumped by every claim; identifies the worker running it
+}
+
+// Change kinds recorded in the change log
+const (
+	ChangeProvinceAdded   = "province_added"
+	ChangeProvinceRemoved = "province_removed"
+	ChangeProvinceRenamed = "province_renamed"
+	ChangeUnitAdded       = "unit_added"
+	ChangeUnitRemoved     = "unit_removed"
+	ChangeUnitRenamed     = "unit_renamed"
+	ChangeUnitMoved       = "unit_moved"       // To another province; values are province IDs
+	ChangeUnitCoordinates = "unit_coordinates" // Values are "lat,long"
+)
+
+// Change is one difference a crawl run found between upstream and the stored data
+type Change struct {
+	ID         int64     `json:"id" db:"id"`
+	RunID      string    `json:"run_id" db:"run_id"`
+	Kind       string    `json:"kind" db:"kind"`
+	RefID      int       `json:"ref_id" db:"ref_id"`           // provinces.id for province_*, admin_units.id for unit_*
+	ProvinceID int       `json:"province_id" db:"province_id"` // The province, or the unit's (new) province
+	Name       string    `json:"name" db:"name"`               // Current name; the last known one for removals
+	OldValue   string    `json:"old_value,omitempty" db:"old_value"`
+	NewValue   string    `json:"new_value,omitempty" db:"new_value"`
+	CreatedAt  time.Time `json:"created_at" db:"created_at"`
+}
+
+// ChangeQuery filters the change log. Results are ordered by ID; AfterID resumes after a page.
+type ChangeQuery struct {
+	Since   time.Time // Only changes recorded after this instant
+	RunID   string
+	AfterID int64
+	Limit   int
+}
+
+// Tombstone records that a province or unit was retired (disappeared upstream)
+type Tombstone struct {
+	ID        int       `json:"id"`
+	RetiredAt time.Time `json:"retired_at"`
+}
+
+// SyncDelta is what changed between two sync versions: records inserted or updated (upserted) and
+// retired (deleted). A full sync carries every live record and no tombstones.
+type SyncDelta struct {
+	Provinces        []Province  `json:"provinces"`
+	Units            []AdminUnit `json:"units"`
+	DeletedProvinces []Tombstone `json:"deleted_provinces"`
+	DeletedUnits     []Tombstone `json:"deleted_units"`
+	Version          int64       `json:"-"` // Sync version the delta reaches: where the next one starts
 }
@@ -0,0 +1,152 @@
+// Package search defines how admin units are matched and ranked.
+// Storage backends only retrieve candidates; scoring lives here so every backend ranks identically.
+package search
+
+import (
+	"sort"
+	"strings"
+
+	"vn-admin-api/internal/models"
+	"vn-admin-api/internal/vntext"
+)
+
+const (
+	// DefaultLimit is the number of results returned when none is requested
+	DefaultLimit = 50
+	// MaxCandidates caps how many rows a backend retrieves before ranking
+	MaxCandidates = 1000
+	// DefaultMinScore is the trigram similarity threshold (pg_trgm's default)
+	DefaultMinScore = 0.3
+)
+
+// Per-token weights: a word of the current name beats a word of the pre-merger description,
+// and a whole-word hit beats a prefix hit.
+const (
+	weightNameExact  = 1.0
+	weightNamePrefix = 0.8
+	weightPreExact   = 0.5
+	weightPrePrefix  = 0.4
+)
+
+// Matcher scores units against one accent-folded query
+type Matcher struct {
+	query  string
+	tokens []string
+}
+
+func NewMatcher(query string) *Matcher {
+	return &Matcher{query: vntext.Fold(query), tokens: vntext.Tokens(query)}
+}
+
+// Query returns the folded query string
+func (m *Matcher) Query() string {
+	return m.query
+}
+
+// Tokens returns the folded query words
+func (m *Matcher) Tokens() []string {
+	return m.tokens
+}
+
+// Empty reports whether the query has no searchable words
+func (m *Matcher) Empty() bool {
+	return len(m.tokens) == 0
+}
+
+// Score returns the relevance of u in [0, 1]. Every query word must prefix-match a word of
+// the name or the pre-merger description (AND semantics, like a tsquery); ok is false otherwise.
+func (m *Matcher) Score(u models.AdminUnit) (score float64, ok bool) {
+	if m.Empty() {
+		return 0, false
+	}
+	name := vntext.Tokens(u.Name)
+	pre := vntext.Tokens(u.PreMergerDesc)
+
+	var total float64
+	for _, t := range m.tokens {
+		w := tokenWeight(t, name, weightNameExact, weightNamePrefix)
+		if w == 0 {
+			w = tokenWeight(t, pre, weightPreExact, weightPrePrefix)
+		}
+		if w == 0 {
+			return 0, false
+		}
+		total += w
+	}
+	return total / float64(len(m.tokens)), true
+}
+
+// Similarity returns the trigram similarity between the query and the unit name
+func (m *Matcher) Similarity(u models.AdminUnit) float64 {
+	return vntext.Similarity(m.query, u.Name)
+}
+
+func tokenWeight(token string, words []string, exact, prefix float64) float64 {
+	best := 0.0
+	for _, w := range words {
+		if w == token {
+			return exact
+		}
+		if strings.HasPrefix(w, token) {
+			best = prefix
+		}
+	}
+	return best
+}
+
+// Sort orders results by descending score, then by ID for a stable response
+func Sort(results []models.SearchResult) {
+	sort.SliceStable(results, func(i, j int) bool {
+		if results[i].Score != results[j].Score {
+			return results[i].Score > results[j].Score
+		}
+		return results[i].ID < results[j].ID
+	})
+}
+
+// Rank scores candidates, drops non-matches and returns the best params.Limit results.
+// Exact matches keep their token score; with params.Fuzzy the remaining candidates are
+// kept when their trigram similarity reaches params.MinScore (DefaultMinScore when unset).
+// Units of another level than params.Level, when set, are dropped.
+func Rank(m *Matcher, candidates []models.AdminUnit, params models.SearchParams) []models.SearchResult {
+	minScore := params.MinScore
+	if minScore <= 0 {
+		minScore = DefaultMinScore
+	}
+
+	results := make([]models.SearchResult, 0)
+	for _, u := range candidates {
+		if params.Level != "" && u.LevelCode != params.Level {
+			continue
+		}
+		if score, ok := m.Score(u); ok {
+			results = append(results, models.SearchResult{AdminUnit: u, Score: score, Match: models.MatchExact})
+			continue
+		}
+		if !params.Fuzzy {
+			continue
+		}
+		if sim := m.Similarity(u); sim >= minScore {
+			results = append(results, models.SearchResult{AdminUnit: u, Score: sim, Match: models.MatchFuzzy})
+		}
+	}
+	Sort(results)
+	if params.Limit > 0 && len(results) > params.Limit {
+		results = results[:params.Limit]
+	}
+	return results
+}
+
+// Suggest returns the candidate name most similar to the query ("did you mean"),
+// or false when none reaches DefaultMinScore
+func Suggest(m *Matcher, candidates []models.AdminUnit) (string, bool) {
+	best, bestID, bestScore := "", 0, DefaultMinScore
+	found := false
+	for _, u := range candidates {
+		sim := m.Similarity(u)
+		if sim > bestScore || (sim == bestScore && (!found || u.ID < bestID)) {
+			best, bestID, bestScore, found = u.Name, u.ID, sim, true
+		}
+	}
+	return best, found
+}
@@ -0,0 +1,457 @@
+package storage
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"vn-admin-api/internal/levels"
+	"vn-admin-api/internal/lineage"
+	"vn-admin-api/internal/models"
+	"vn-admin-api/internal/search"
+	"vn-admin-api/internal/vntext"
+)
+
+// MemoryStore is a pure-Go Store kept entirely in memory.
+// It can be seeded from and flushed to a JSON snapshot file.
+type MemoryStore struct {
+	mu           sync.RWMutex
+	provinces    map[int]models.Province
+	units        map[int]models.AdminUnit
+	predecessors map[int][]models.Predecessor
+	boundaries   map[boundaryKey]models.Boundary
+	externalIDs  map[externalKey]models.ExternalID
+	changes      []models.Change // Change log in ID order; IDs are positions + 1
+	jobs         map[string]*memoryJob
+	staging      map[string]*memoryStaging // Staged crawl runs by run ID; not saved to the file
+	stagingBusy  bool                      // A crawl holds the staging area
+
+	// Version history, oldest first; the last version of a live record is open (ValidTo nil)
+	provinceVersions map[int][]models.ProvinceVersion
+	unitVersions     map[int][]models.UnitVersion
+}
+
+// snapshot is the on-disk format used by LoadFile/SaveFile
+type snapshot struct {
+	Provinces    []models.Province    `json:"provinces"`
+	Units        []models.AdminUnit   `json:"units"`
+	Predecessors []models.Predecessor `json:"predecessors,omitempty"`
+	Boundaries   []models.Boundary    `json:"boundaries,omitempty"`
+	ExternalIDs  []models.ExternalID  `json:"external_ids,omitempty"`
+	Changes      []models.Change      `json:"changes,omitempty"`
+
+	ProvinceVersions []models.ProvinceVersion `json:"province_versions,omitempty"`
+	UnitVersions     []models.UnitVersion     `json:"unit_versions,omitempty"`
+}
+
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		provinces:    make(map[int]models.Province),
+		units:        make(map[int]models.AdminUnit),
+		predecessors: make(map[int][]models.Predecessor),
+		boundaries:   make(map[boundaryKey]models.Boundary),
+		externalIDs:  make(map[externalKey]models.ExternalID),
+		jobs:         make(map[string]*memoryJob),
+		staging:      make(map[string]*memoryStaging),
+
+		provinceVersions: make(map[int][]models.ProvinceVersion),
+		unitVersions:     make(map[int][]models.UnitVersion),
+	}
+}
+
+// LoadFile replaces the store contents with a JSON snapshot
+func (s *MemoryStore) LoadFile(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read data file: %w", err)
+	}
+
+	var snap snapshot
+	if err := json.Unmarshal(data, &snap); err != nil {
+		return fmt.Errorf("failed to decode data file %s: %w", path, err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.provinces = make(map[int]models.Province, len(snap.Provinces))
+	for _, p := range snap.Provinces {
+		s.provinces[p.ID] = p
+	}
+	s.units = make(map[int]models.AdminUnit, len(snap.Units))
+	for _, u := range snap.Units {
+		if u.LevelCode == "" {
+			u.LevelCode = levels.Code(u.Level) // Snapshots written before the level taxonomy
+		}
+		s.units[u.ID] = u
+	}
+	s.predecessors = make(map[int][]models.Predecessor)
+	for _, p := range snap.Predecessors {
+		s.predecessors[p.UnitID] = append(s.predecessors[p.UnitID], p)
+	}
+	s.boundaries = make(map[boundaryKey]models.Boundary, len(snap.Boundaries))
+	for _, b := range snap.Boundaries {
+		s.boundaries[boundaryKey{b.Kind, b.RefID}] = b
+	}
+	s.externalIDs = make(map[externalKey]models.ExternalID, len(snap.ExternalIDs))
+	for _, id := range snap.ExternalIDs {
+		s.externalIDs[externalKey{id.System, id.ExternalID}] = id
+	}
+	s.changes = snap.Changes
+	s.loadVersions(snap)
+	if len(snap.Predecessors) == 0 {
+		// Snapshots written before lineage parsing existed: derive it now
+		for _, u := range snap.Units {
+			for _, p := range lineage.Parse(u.PreMergerDesc) {
+				p.UnitID = u.ID
+				s.predecessors[u.ID] = append(s.predecessors[u.ID], p)
+			}
+		}
+	}
+	return nil
+}
+
+// SaveFile writes the store contents as a JSON snapshot
+func (s *MemoryStore) SaveFile(path string) error {
+	s.mu.RLock()
+	snap := snapshot{
+		Provinces: sortedProvinces(s.provinces),
+		Units:     s.filterUnits(func(models.AdminUnit) bool { return true }),
+	}
+	for _, u := range snap.Units {
+		snap.Predecessors = append(snap.Predecessors, s.predecessors[u.ID]...)
+	}
+	snap.Boundaries = s.filterBoundaries(func(models.Boundary) bool { return true })
+	snap.ExternalIDs = s.filterExternalIDs(func(models.ExternalID) bool { return true })
+	snap.Changes = s.changes
+	snap.ProvinceVersions, snap.UnitVersions = s.allVersions()
+	s.mu.RUnlock()
+
+	data, err := json.Marshal(snap)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+
+	// Write to a temp file first so readers never see a partial snapshot
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return fmt.Errorf("failed to write data file: %w", err)
+	}
+	return os.Rename(tmp, path)
+}
+
+func (s *MemoryStore) Ping(ctx context.Context) error {
+	return nil // Memory is always available
+}
+
+func (s *MemoryStore) Close() error {
+	return nil
+}
+
+func (s *MemoryStore) UpsertProvince(ctx context.Context, p models.Province) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.upsertProvince(p, time.Now())
+	return nil
+}
+
+func (s *MemoryStore) UpsertAdminUnit(ctx context.Context, u models.AdminUnit) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.upsertUnit(u, time.Now())
+}
+
+// upsertProvince stores p as of now. Caller must hold s.mu.
+func (s *MemoryStore) upsertProvince(p models.Province, now time.Time) {
+	old, ok := s.provinces[p.ID]
+	p.GSOCode = old.GSOCode // Owned by SetGSOCodes, like the Postgres column
+	p.RetiredAt = nil       // Upserting restores a retired province
+	if ok && sameProvince(old, p) {
+		return // Unchanged: keep updated_at and the open version, like the Postgres backend
+	}
+	p.UpdatedAt = now
+	s.provinces[p.ID] = p
+	s.trackProvince(p)
+}
+
+// upsertUnit stores u as of now. Caller must hold s.mu.
+func (s *MemoryStore) upsertUnit(u models.AdminUnit, now time.Time) error {
+	if _, ok := s.provinces[u.ProvinceID]; !ok {
+		return fmt.Errorf("failed to upsert unit %d: unknown province %d", u.ID, u.ProvinceID)
+	}
+	old, ok := s.units[u.ID]
+	u.GSOCode = old.GSOCode // Owned by SetGSOCodes, like the Postgres column
+	u.RetiredAt = nil       // Upserting restores a retired unit
+	if ok && sameUnit(old, u) {
+		return nil
+	}
+	u.UpdatedAt = now
+	s.units[u.ID] = u
+	s.trackUnit(u)
+	return nil
+}
+
+// SetGSOCodes stores official GSO codes; every ID must exist, or nothing is changed
+func (s *MemoryStore) SetGSOCodes(ctx context.Context, provinces, units map[int]string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for id := range provinces {
+		if _, ok := s.provinces[id]; !ok {
+			return fmt.Errorf("failed to set GSO code: unknown province %d", id)
+		}
+	}
+	for id := range units {
+		if _, ok := s.units[id]; !ok {
+			return fmt.Errorf("failed to set GSO code: unknown unit %d", id)
+		}
+	}
+	now := time.Now()
+	for id, code := range provinces {
+		if p := s.provinces[id]; p.GSOCode != code {
+			p.GSOCode, p.UpdatedAt = code, now
+			s.provinces[id] = p
+			s.trackProvince(p)
+		}
+	}
+	for id, code := range units {
+		if u := s.units[id]; u.GSOCode != code {
+			u.GSOCode, u.UpdatedAt = code, now
+			s.units[id] = u
+			s.trackUnit(u)
+		}
+	}
+	return nil
+}
+
+func (s *MemoryStore) GetProvinces(ctx context.Context) ([]models.Province, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	provinces := sortedProvinces(s.provinces)
+	return slices.DeleteFunc(provinces, func(p models.Province) bool { return p.RetiredAt != nil }), nil
+}
+
+func (s *MemoryStore) GetUnitsByProvince(ctx context.Context, provinceID int) ([]models.AdminUnit, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return s.filterUnits(func(u models.AdminUnit) bool { return u.ProvinceID == provinceID && live(u) }), nil
+}
+
+// ListAdminUnits filters, sorts and pages units the same way as the Postgres backend
+func (s *MemoryStore) ListAdminUnits(ctx context.Context, q models.UnitQuery) ([]models.AdminUnit, int, error) {
+	s.mu.RLock()
+	units := s.allUnits()
+	s.mu.RUnlock()
+
+	page, total := PageUnits(units, q)
+	return page, total, nil
+}
+
+// PageUnits applies the filters, sort and keyset page of q to units, as ListAdminUnits does, and
+// returns the page plus the number of units matching the filters. units is reordered in place.
+func PageUnits(units []models.AdminUnit, q models.UnitQuery) ([]models.AdminUnit, int) {
+	match := UnitMatcher(q)
+	units = slices.DeleteFunc(units, func(u models.AdminUnit) bool { return !match(u) })
+
+	less := func(a, b models.AdminUnit) bool {
+		switch q.Sort {
+		case models.UnitSortName:
+			if a.Name != b.Name {
+				return a.Name < b.Name
+			}
+		case models.UnitSortUpdatedAt:
+			if !a.UpdatedAt.Equal(b.UpdatedAt) {
+				return a.UpdatedAt.Before(b.UpdatedAt)
+			}
+		}
+		return a.ID < b.ID
+	}
+	if q.Desc {
+		asc := less
+		less = func(a, b models.AdminUnit) bool { return asc(b, a) }
+	}
+	sort.SliceStable(units, func(i, j int) bool { return less(units[i], units[j]) })
+
+	total := len(units)
+	if c := q.After; c != nil {
+		after := models.AdminUnit{ID: c.ID, Name: c.Name, UpdatedAt: c.UpdatedAt}
+		units = units[sort.Search(len(units), func(i int) bool { return less(after, units[i]) }):]
+	}
+	return units[:min(q.Limit, len(units))], total
+}
+
+// UnitMatcher returns a predicate applying the filters of q (sorting and paging fields are ignored)
+func UnitMatcher(q models.UnitQuery) func(models.AdminUnit) bool {
+	name := vntext.Fold(strings.Join(strings.Fields(q.Name), " "))
+	return func(u models.AdminUnit) bool {
+		if !q.IncludeRetired && !live(u) {
+			return false
+		}
+		if q.ProvinceID != 0 && u.ProvinceID != q.ProvinceID {
+			return false
+		}
+		if q.Level != "" && u.LevelCode != q.Level {
+			return false
+		}
+		if name != "" && !strings.Contains(vntext.Fold(u.Name), name) {
+			return false
+		}
+		if q.HasCoordinates != nil && (u.Lat != 0 || u.Long != 0) != *q.HasCoordinates {
+			return false
+		}
+		return q.UpdatedSince.IsZero() || u.UpdatedAt.After(q.UpdatedSince)
+	}
+}
+
+func (s *MemoryStore) GetAdminUnit(ctx context.Context, id int) (*models.AdminUnit, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	u, ok := s.units[id]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return &u, nil
+}
+
+func (s *MemoryStore) GetAdminUnitByCode(ctx context.Context, code string) (*models.AdminUnit, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	code = strings.TrimLeft(code, "0")
+	units := s.filterUnits(func(u models.AdminUnit) bool { return strings.TrimLeft(u.Code, "0") == code })
+	if len(units) == 0 {
+		return nil, ErrNotFound
+	}
+	if i := slices.IndexFunc(units, live); i > 0 {
+		return &units[i], nil // A live unit wins over a retired one that had the same code
+	}
+	return &units[0], nil
+}
+
+func (s *MemoryStore) ReplacePredecessors(ctx context.Context, unitID int, preds []models.Predecessor) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.units[unitID]; !ok {
+		return fmt.Errorf("failed to store predecessors: unknown unit %d", unitID)
+	}
+	s.replacePredecessors(unitID, preds)
+	return nil
+}
+
+// replacePredecessors stores the lineage of a unit. Caller must hold s.mu.
+func (s *MemoryStore) replacePredecessors(unitID int, preds []models.Predecessor) {
+	stored := make([]models.Predecessor, len(preds))
+	for i, p := range preds {
+		p.UnitID = unitID
+		stored[i] = p
+	}
+	s.predecessors[unitID] = stored
+}
+
+func (s *MemoryStore) GetPredecessors(ctx context.Context, unitID int) ([]models.Predecessor, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	preds := make([]models.Predecessor, len(s.predecessors[unitID]))
+	copy(preds, s.predecessors[unitID])
+	return preds, nil
+}
+
+func (s *MemoryStore) FindPredecessorsByName(ctx context.Context, name string) ([]models.Predecessor, error) {
+	folded := vntext.Fold(strings.Join(strings.Fields(name), " "))
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	preds := make([]models.Predecessor, 0)
+	for _, u := range s.filterUnits(live) {
+		for _, p := range s.predecessors[u.ID] {
+			if vntext.Fold(p.Name) == folded {
+				preds = append(preds, p)
+			}
+		}
+	}
+	return preds, nil
+}
+
+// SearchAdminUnits ranks every unit with the same scoring as the Postgres backend
+func (s *MemoryStore) SearchAdminUnits(ctx context.Context, params models.SearchParams) ([]models.SearchResult, error) {
+	matcher := search.NewMatcher(params.Query)
+	if matcher.Empty() {
+		return []models.SearchResult{}, nil
+	}
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	units := s.allUnits()
+	if !params.IncludeRetired {
+		units = slices.DeleteFunc(units, func(u models.AdminUnit) bool { return !live(u) })
+	}
+	return search.Rank(matcher, units, params), nil
+}
+
+func (s *MemoryStore) SuggestUnitName(ctx context.Context, query string) (string, error) {
+	matcher := search.NewMatcher(query)
+	if matcher.Empty() {
+		return "", nil
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	name, _ := search.Suggest(matcher, slices.DeleteFunc(s.allUnits(), func(u models.AdminUnit) bool { return !live(u) }))
+	return name, nil
+}
+
+// live reports whether u is not retired
+func live(u models.AdminUnit) bool {
+	return u.RetiredAt == nil
+}
+
+// allUnits returns every unit, retired ones included, in map order. Caller must hold s.mu.
+func (s *MemoryStore) allUnits() []models.AdminUnit {
+	units := make([]models.AdminUnit, 0, len(s.units))
+	for _, u := range s.units {
+		units = append(units, u)
+	}
+	return units
+}
+
+// filterUnits returns matching units ordered by ID. Caller must hold s.mu.
+func (s *MemoryStore) filterUnits(keep func(models.AdminUnit) bool) []models.AdminUnit {
+	units := make([]models.AdminUnit, 0)
+	for _, u := range s.units {
+		if keep(u) {
+			units = append(units, u)
+		}
+	}
+	sort.Slice(units, func(i, j int) bool { return units[i].ID < units[j].ID })
+	return units
+}
+
+func sortedProvinces(m map[int]models.Province) []models.Province {
+	provinces := make([]models.Province, 0, len(m))
+	for _, p := range m {
+		provinces = append(provinces, p)
+	}
+	sort.Slice(provinces, func(i, j int) bool { return provinces[i].ID < provinces[j].ID })
+	return provinces
+}
+
+var _ Store = (*MemoryStore)(nil)
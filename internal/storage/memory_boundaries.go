@@ -0,0 +1,73 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"vn-admin-api/internal/models"
+)
+
+// boundaryKey identifies one outline in MemoryStore.boundaries
+type boundaryKey struct {
+	kind string
+	id   int
+}
+
+func (s *MemoryStore) UpsertBoundary(ctx context.Context, b models.Boundary) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	switch b.Kind {
+	case models.BoundaryProvince:
+		if _, ok := s.provinces[b.RefID]; !ok {
+			return fmt.Errorf("failed to upsert boundary: unknown province %d", b.RefID)
+		}
+	case models.BoundaryUnit:
+		if _, ok := s.units[b.RefID]; !ok {
+			return fmt.Errorf("failed to upsert boundary: unknown unit %d", b.RefID)
+		}
+	default:
+		return fmt.Errorf("unknown boundary kind %q", b.Kind)
+	}
+	b.UpdatedAt = time.Now()
+	s.boundaries[boundaryKey{b.Kind, b.RefID}] = b
+	return nil
+}
+
+func (s *MemoryStore) GetBoundaries(ctx context.Context, kind string) ([]models.Boundary, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return s.filterBoundaries(func(b models.Boundary) bool { return b.Kind == kind }), nil
+}
+
+func (s *MemoryStore) GetBoundariesByIDs(ctx context.Context, kind string, ids []int) ([]models.Boundary, error) {
+	wanted := make(map[int]bool, len(ids))
+	for _, id := range ids {
+		wanted[id] = true
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return s.filterBoundaries(func(b models.Boundary) bool { return b.Kind == kind && wanted[b.RefID] }), nil
+}
+
+// filterBoundaries returns matching outlines ordered by kind and ID. Caller must hold s.mu.
+func (s *MemoryStore) filterBoundaries(keep func(models.Boundary) bool) []models.Boundary {
+	boundaries := make([]models.Boundary, 0)
+	for _, b := range s.boundaries {
+		if keep(b) {
+			boundaries = append(boundaries, b)
+		}
+	}
+	sort.Slice(boundaries, func(i, j int) bool {
+		if boundaries[i].Kind != boundaries[j].Kind {
+			return boundaries[i].Kind < boundaries[j].Kind
+		}
+		return boundaries[i].RefID < boundaries[j].RefID
+	})
+	return boundaries
+}
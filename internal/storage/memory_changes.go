@@ -0,0 +1,41 @@
+package storage
+
+import (
+	"context"
+	"time"
+
+	"vn-admin-api/internal/models"
+)
+
+func (s *MemoryStore) SaveChanges(ctx context.Context, runID string, changes []models.Change) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.saveChanges(runID, changes, time.Now())
+	return nil
+}
+
+// saveChanges appends changes to the log. Caller must hold s.mu.
+func (s *MemoryStore) saveChanges(runID string, changes []models.Change, now time.Time) {
+	for _, c := range changes {
+		c.ID = int64(len(s.changes)) + 1
+		c.RunID, c.CreatedAt = runID, now
+		s.changes = append(s.changes, c)
+	}
+}
+
+func (s *MemoryStore) GetChanges(ctx context.Context, q models.ChangeQuery) ([]models.Change, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	changes := make([]models.Change, 0)
+	for _, c := range s.changes[min(int(max(q.AfterID, 0)), len(s.changes)):] { // IDs are positions + 1
+		if len(changes) == q.Limit {
+			break
+		}
+		if (q.Since.IsZero() || c.CreatedAt.After(q.Since)) && (q.RunID == "" || c.RunID == q.RunID) {
+			changes = append(changes, c)
+		}
+	}
+	return changes, nil
+}
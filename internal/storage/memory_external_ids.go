@@ -0,0 +1,93 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"vn-admin-api/internal/models"
+)
+
+// externalKey identifies one external ID in MemoryStore.externalIDs
+type externalKey struct {
+	system string
+	id     string
+}
+
+func (s *MemoryStore) SaveExternalIDs(ctx context.Context, system string, ids []models.ExternalID, replace bool) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	// Validate everything first so a failed import changes nothing, like the Postgres transaction
+	for _, id := range ids {
+		if id.System != system {
+			return fmt.Errorf("external ID %q belongs to system %q, not %q", id.ExternalID, id.System, system)
+		}
+		if err := s.checkRef(id.Kind, id.RefID); err != nil {
+			return fmt.Errorf("failed to save %s external ID %q: %w", system, id.ExternalID, err)
+		}
+	}
+	if replace {
+		for k := range s.externalIDs {
+			if k.system == system {
+				delete(s.externalIDs, k)
+			}
+		}
+	}
+	for _, id := range ids {
+		s.externalIDs[externalKey{system, id.ExternalID}] = id
+	}
+	return nil
+}
+
+func (s *MemoryStore) GetExternalIDs(ctx context.Context, kind string, refID int) ([]models.ExternalID, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return s.filterExternalIDs(func(id models.ExternalID) bool { return id.Kind == kind && id.RefID == refID }), nil
+}
+
+func (s *MemoryStore) GetExternalID(ctx context.Context, system, externalID string) (*models.ExternalID, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	id, ok := s.externalIDs[externalKey{system, externalID}]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return &id, nil
+}
+
+// checkRef verifies a province or unit exists. Caller must hold s.mu.
+func (s *MemoryStore) checkRef(kind string, refID int) error {
+	switch kind {
+	case models.RefKindProvince:
+		if _, ok := s.provinces[refID]; !ok {
+			return fmt.Errorf("unknown province %d", refID)
+		}
+	case models.RefKindUnit:
+		if _, ok := s.units[refID]; !ok {
+			return fmt.Errorf("unknown unit %d", refID)
+		}
+	default:
+		return fmt.Errorf("unknown record kind %q", kind)
+	}
+	return nil
+}
+
+// filterExternalIDs returns matching IDs ordered by system and ID. Caller must hold s.mu.
+func (s *MemoryStore) filterExternalIDs(keep func(models.ExternalID) bool) []models.ExternalID {
+	ids := make([]models.ExternalID, 0)
+	for _, id := range s.externalIDs {
+		if keep(id) {
+			ids = append(ids, id)
+		}
+	}
+	sort.Slice(ids, func(i, j int) bool {
+		if ids[i].System != ids[j].System {
+			return ids[i].System < ids[j].System
+		}
+		return ids[i].ExternalID < ids[j].ExternalID
+	})
+	return ids
+}
@@ -0,0 +1,133 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"vn-admin-api/internal/database"
+	"vn-admin-api/internal/models"
+)
+
+// memoryJob holds a job and its payloads. Jobs are not part of the snapshot file.
+type memoryJob struct {
+	job       models.Job
+	input     []byte
+	result    []byte
+	heartbeat time.Time
+}
+
+func (s *MemoryStore) CreateJob(ctx context.Context, job models.Job, input []byte) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.jobs[job.ID]; ok {
+		return fmt.Errorf("failed to create job %s: already exists", job.ID)
+	}
+	s.jobs[job.ID] = &memoryJob{job: job, input: input}
+	return nil
+}
+
+func (s *MemoryStore) ClaimJob(ctx context.Context, staleAfter time.Duration, maxAttempts int) (*models.Job, []byte, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	var next *memoryJob
+	for _, j := range s.jobs {
+		stale := j.job.Status == models.JobRunning && now.Sub(j.heartbeat) > staleAfter
+		if stale && j.job.Attempt >= maxAttempts {
+			j.job.Status = models.JobFailed
+			j.job.Error = database.AbandonedJobError(maxAttempts)
+			j.job.FinishedAt = &now
+			continue
+		}
+		claimable := j.job.Status == models.JobQueued || stale
+		if claimable && (next == nil || j.job.CreatedAt.Before(next.job.CreatedAt)) {
+			next = j
+		}
+	}
+	if next == nil {
+		return nil, nil, ErrNotFound
+	}
+
+	next.job.Status = models.JobRunning
+	next.job.Processed, next.job.Matched = 0, 0
+	next.job.Attempt++
+	next.job.StartedAt = &now
+	next.heartbeat = now
+	job := next.job
+	return &job, next.input, nil
+}
+
+func (s *MemoryStore) UpdateJobProgress(ctx context.Context, id string, attempt, processed, matched int) error {
+	return s.updateJob(id, attempt, func(j *memoryJob) {
+		j.job.Processed, j.job.Matched = processed, matched
+		j.heartbeat = time.Now()
+	})
+}
+
+func (s *MemoryStore) CompleteJob(ctx context.Context, id string, attempt, processed, matched int, result []byte) error {
+	return s.updateJob(id, attempt, func(j *memoryJob) {
+		now := time.Now()
+		j.job.Status = models.JobCompleted
+		j.job.Processed, j.job.Matched = processed, matched
+		j.job.FinishedAt = &now
+		j.result = result
+	})
+}
+
+func (s *MemoryStore) FailJob(ctx context.Context, id string, attempt int, message string) error {
+	return s.updateJob(id, attempt, func(j *memoryJob) {
+		now := time.Now()
+		j.job.Status = models.JobFailed
+		j.job.Error = message
+		j.job.FinishedAt = &now
+	})
+}
+
+func (s *MemoryStore) ReleaseJob(ctx context.Context, id string, attempt int) error {
+	return s.updateJob(id, attempt, func(j *memoryJob) {
+		j.job.Status = models.JobQueued
+		j.job.Processed, j.job.Matched = 0, 0
+	})
+}
+
+func (s *MemoryStore) GetJob(ctx context.Context, id string) (*models.Job, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	j, ok := s.jobs[id]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	job := j.job
+	return &job, nil
+}
+
+func (s *MemoryStore) GetJobResult(ctx context.Context, id string) ([]byte, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	j, ok := s.jobs[id]
+	if !ok || j.job.Status != models.JobCompleted {
+		return nil, ErrNotFound
+	}
+	return j.result, nil
+}
+
+// updateJob applies fn to a running job still held by the claim that returned attempt
+func (s *MemoryStore) updateJob(id string, attempt int, fn func(j *memoryJob)) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	j, ok := s.jobs[id]
+	if !ok {
+		return fmt.Errorf("failed to update job %s: %w", id, ErrNotFound)
+	}
+	if j.job.Attempt != attempt || j.job.Status != models.JobRunning {
+		return fmt.Errorf("failed to update job %s: %w", id, ErrJobLost)
+	}
+	fn(j)
+	return nil
+}
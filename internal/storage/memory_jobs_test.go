@@ -0,0 +1,43 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"vn-admin-api/internal/database"
+	"vn-admin-api/internal/models"
+)
+
+func TestClaimJob_FailsAbandonedJobs(t *testing.T) {
+	ctx := context.Background()
+	s := NewMemoryStore()
+	if err := s.CreateJob(ctx, models.Job{ID: "j1", Status: models.JobQueued, CreatedAt: time.Now()}, nil); err != nil {
+		t.Fatalf("CreateJob: %v", err)
+	}
+
+	// Every worker that claims the job stalls, so its heartbeat goes stale at once
+	const maxAttempts = 3
+	for attempt := 1; attempt <= maxAttempts; attempt++ {
+		job, _, err := s.ClaimJob(ctx, 0, maxAttempts)
+		if err != nil {
+			t.Fatalf("claim %d: %v", attempt, err)
+		}
+		if job.Attempt != attempt {
+			t.Fatalf("claim %d: got attempt %d", attempt, job.Attempt)
+		}
+		time.Sleep(time.Millisecond)
+	}
+
+	if _, _, err := s.ClaimJob(ctx, 0, maxAttempts); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("claim after %d attempts: got %v, want ErrNotFound", maxAttempts, err)
+	}
+	job, err := s.GetJob(ctx, "j1")
+	if err != nil {
+		t.Fatalf("GetJob: %v", err)
+	}
+	if job.Status != models.JobFailed || job.Error != database.AbandonedJobError(maxAttempts) || job.FinishedAt == nil {
+		t.Errorf("got status %q, error %q, finished %v; want a failed abandoned job", job.Status, job.Error, job.FinishedAt)
+	}
+}
@@ -0,0 +1,105 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"vn-admin-api/internal/changelog"
+	"vn-admin-api/internal/models"
+)
+
+// memoryStaging is one staged crawl run
+type memoryStaging struct {
+	provinces    map[int]models.Province
+	units        map[int]models.AdminUnit
+	predecessors map[int][]models.Predecessor
+}
+
+func (s *MemoryStore) BeginStaging(ctx context.Context) (func(), error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.stagingBusy {
+		return nil, ErrStagingBusy
+	}
+	s.stagingBusy = true
+	s.staging = make(map[string]*memoryStaging)
+	return func() {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		s.stagingBusy = false
+	}, nil
+}
+
+func (s *MemoryStore) StageProvince(ctx context.Context, runID string, p models.Province, units []models.AdminUnit, preds []models.Predecessor) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	run, ok := s.staging[runID]
+	if !ok {
+		run = &memoryStaging{
+			provinces:    make(map[int]models.Province),
+			units:        make(map[int]models.AdminUnit),
+			predecessors: make(map[int][]models.Predecessor),
+		}
+		s.staging[runID] = run
+	}
+	run.provinces[p.ID] = p
+	for _, u := range units {
+		run.units[u.ID] = u
+		delete(run.predecessors, u.ID)
+	}
+	for _, pred := range preds {
+		run.predecessors[pred.UnitID] = append(run.predecessors[pred.UnitID], pred)
+	}
+	return nil
+}
+
+// Publish applies a staged run under one lock, so readers see the data before or after it, never
+// in between. Like the Postgres transaction, it changes nothing when it fails.
+func (s *MemoryStore) Publish(ctx context.Context, runID string, changes []models.Change) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	run, ok := s.staging[runID]
+	if !ok {
+		return fmt.Errorf("failed to publish run %s: nothing staged", runID)
+	}
+	for _, u := range run.units {
+		if _, ok := run.provinces[u.ProvinceID]; !ok {
+			if _, ok := s.provinces[u.ProvinceID]; !ok {
+				return fmt.Errorf("failed to publish unit %d: unknown province %d", u.ID, u.ProvinceID)
+			}
+		}
+	}
+
+	now := time.Now()
+	provinceIDs := make([]int, 0, len(run.provinces))
+	for id := range run.provinces {
+		provinceIDs = append(provinceIDs, id)
+	}
+	sort.Ints(provinceIDs)
+	for _, id := range provinceIDs {
+		s.upsertProvince(run.provinces[id], now)
+	}
+
+	unitIDs := make([]int, 0, len(run.units))
+	for id := range run.units {
+		unitIDs = append(unitIDs, id)
+	}
+	sort.Ints(unitIDs)
+	for _, id := range unitIDs {
+		if err := s.upsertUnit(run.units[id], now); err != nil {
+			return err // Unreachable: provinces were checked above
+		}
+		s.replacePredecessors(id, run.predecessors[id])
+	}
+
+	s.saveChanges(runID, changes, now)
+	retiredProvinces, retiredUnits := changelog.Removals(changes)
+	s.retire(retiredProvinces, retiredUnits, now)
+	delete(s.staging, runID)
+	return nil
+}
@@ -0,0 +1,105 @@
+package storage
+
+import (
+	"context"
+	"time"
+
+	"vn-admin-api/internal/models"
+)
+
+// Retire marks provinces and units as retired; unknown or already retired IDs are skipped
+func (s *MemoryStore) Retire(ctx context.Context, provinceIDs, unitIDs []int) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.retire(provinceIDs, unitIDs, time.Now())
+	return nil
+}
+
+// retire marks records as retired at now. Caller must hold s.mu.
+func (s *MemoryStore) retire(provinceIDs, unitIDs []int, now time.Time) {
+	for _, id := range unitIDs {
+		if u, ok := s.units[id]; ok && u.RetiredAt == nil {
+			u.RetiredAt = &now
+			s.units[id] = u
+			s.trackUnit(u)
+		}
+	}
+	for _, id := range provinceIDs {
+		if p, ok := s.provinces[id]; ok && p.RetiredAt == nil {
+			p.RetiredAt = &now
+			s.provinces[id] = p
+			s.trackProvince(p)
+		}
+	}
+}
+
+func (s *MemoryStore) GetRetired(ctx context.Context) ([]models.Province, []models.AdminUnit, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	provinces := make([]models.Province, 0)
+	for _, p := range sortedProvinces(s.provinces) {
+		if p.RetiredAt != nil {
+			provinces = append(provinces, p)
+		}
+	}
+	return provinces, s.filterUnits(func(u models.AdminUnit) bool { return u.RetiredAt != nil }), nil
+}
+
+// GetSyncVersion returns the latest updated_at or retired_at, as a sync version
+func (s *MemoryStore) GetSyncVersion(ctx context.Context) (int64, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var latest int64
+	stamp := func(updated time.Time, retired *time.Time) {
+		latest = max(latest, updated.UnixNano())
+		if retired != nil {
+			latest = max(latest, retired.UnixNano())
+		}
+	}
+	for _, p := range s.provinces {
+		stamp(p.UpdatedAt, p.RetiredAt)
+	}
+	for _, u := range s.units {
+		stamp(u.UpdatedAt, u.RetiredAt)
+	}
+	return latest, nil
+}
+
+// GetSyncDelta returns what changed after the sync version since. Versions are the UnixNano of
+// updated_at / retired_at: writes hold s.mu, so none can be in flight and time order is commit order.
+func (s *MemoryStore) GetSyncDelta(ctx context.Context, since int64) (*models.SyncDelta, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	delta := &models.SyncDelta{
+		Provinces:        make([]models.Province, 0),
+		Units:            make([]models.AdminUnit, 0),
+		DeletedProvinces: make([]models.Tombstone, 0),
+		DeletedUnits:     make([]models.Tombstone, 0),
+		Version:          time.Now().UnixNano(),
+	}
+	inWindow := func(t time.Time) bool {
+		return since == 0 || t.UnixNano() > since && t.UnixNano() <= delta.Version
+	}
+
+	for _, p := range sortedProvinces(s.provinces) {
+		switch {
+		case p.RetiredAt == nil && inWindow(p.UpdatedAt):
+			delta.Provinces = append(delta.Provinces, p)
+		case p.RetiredAt != nil && since > 0 && inWindow(*p.RetiredAt):
+			delta.DeletedProvinces = append(delta.DeletedProvinces, models.Tombstone{ID: p.ID, RetiredAt: *p.RetiredAt})
+		}
+	}
+	for _, u := range s.filterUnits(func(models.AdminUnit) bool { return true }) {
+		switch {
+		case u.RetiredAt == nil && inWindow(u.UpdatedAt):
+			delta.Units = append(delta.Units, u)
+		case u.RetiredAt != nil && since > 0 && inWindow(*u.RetiredAt):
+			delta.DeletedUnits = append(delta.DeletedUnits, models.Tombstone{ID: u.ID, RetiredAt: *u.RetiredAt})
+		}
+	}
+	return delta, nil
+}
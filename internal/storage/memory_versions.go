@@ -0,0 +1,154 @@
+package storage
+
+import (
+	"context"
+	"slices"
+	"sort"
+	"time"
+
+	"vn-admin-api/internal/models"
+)
+
+func (s *MemoryStore) GetProvincesAsOf(ctx context.Context, asOf time.Time) ([]models.Province, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	provinces := make([]models.Province, 0)
+	for _, versions := range s.provinceVersions {
+		for _, v := range versions {
+			if v.ValidAt(asOf) {
+				provinces = append(provinces, v.Province)
+				break
+			}
+		}
+	}
+	sort.Slice(provinces, func(i, j int) bool { return provinces[i].ID < provinces[j].ID })
+	return provinces, nil
+}
+
+func (s *MemoryStore) GetUnitsAsOf(ctx context.Context, asOf time.Time, provinceID int) ([]models.AdminUnit, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	units := make([]models.AdminUnit, 0)
+	for _, versions := range s.unitVersions {
+		for _, v := range versions {
+			if v.ValidAt(asOf) {
+				if provinceID == 0 || v.ProvinceID == provinceID {
+					units = append(units, v.AdminUnit)
+				}
+				break
+			}
+		}
+	}
+	sort.Slice(units, func(i, j int) bool { return units[i].ID < units[j].ID })
+	return units, nil
+}
+
+func (s *MemoryStore) GetUnitHistory(ctx context.Context, id int) ([]models.UnitVersion, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	versions := slices.Clone(s.unitVersions[id])
+	if versions == nil {
+		versions = make([]models.UnitVersion, 0)
+	}
+	return versions, nil
+}
+
+// sameProvince reports whether two states of a province agree on every versioned field
+func sameProvince(a, b models.Province) bool {
+	a.UpdatedAt, b.UpdatedAt = time.Time{}, time.Time{}
+	return a == b
+}
+
+// sameUnit reports whether two states of a unit agree on every versioned field
+func sameUnit(a, b models.AdminUnit) bool {
+	a.UpdatedAt, b.UpdatedAt = time.Time{}, time.Time{}
+	return a == b
+}
+
+// trackProvince closes the open version of p and opens one valid from p.UpdatedAt. A version opened
+// at the same instant is replaced instead, as the Postgres trigger does. A retired p only has its
+// open version closed, at p.RetiredAt. Caller must hold s.mu.
+func (s *MemoryStore) trackProvince(p models.Province) {
+	versions, now := s.provinceVersions[p.ID], p.UpdatedAt
+	if p.RetiredAt != nil {
+		if n := len(versions); n > 0 && versions[n-1].ValidTo == nil {
+			retiredAt := *p.RetiredAt
+			versions[n-1].ValidTo = &retiredAt
+		}
+		return
+	}
+	if n := len(versions); n > 0 {
+		if last := &versions[n-1]; last.ValidFrom.Equal(now) {
+			versions = versions[:n-1]
+		} else if last.ValidTo == nil {
+			last.ValidTo = &now
+		}
+	}
+	s.provinceVersions[p.ID] = append(versions, models.ProvinceVersion{Province: p, ValidFrom: now})
+}
+
+// trackUnit is trackProvince for units. Caller must hold s.mu.
+func (s *MemoryStore) trackUnit(u models.AdminUnit) {
+	versions, now := s.unitVersions[u.ID], u.UpdatedAt
+	if u.RetiredAt != nil {
+		if n := len(versions); n > 0 && versions[n-1].ValidTo == nil {
+			retiredAt := *u.RetiredAt
+			versions[n-1].ValidTo = &retiredAt
+		}
+		return
+	}
+	if n := len(versions); n > 0 {
+		if last := &versions[n-1]; last.ValidFrom.Equal(now) {
+			versions = versions[:n-1]
+		} else if last.ValidTo == nil {
+			last.ValidTo = &now
+		}
+	}
+	s.unitVersions[u.ID] = append(versions, models.UnitVersion{AdminUnit: u, ValidFrom: now})
+}
+
+// loadVersions restores the history of a snapshot. Records without any version (snapshots written
+// before versioning) start their history at their updated_at. Caller must hold s.mu.
+func (s *MemoryStore) loadVersions(snap snapshot) {
+	s.provinceVersions = make(map[int][]models.ProvinceVersion)
+	for _, v := range snap.ProvinceVersions {
+		s.provinceVersions[v.ID] = append(s.provinceVersions[v.ID], v)
+	}
+	for id, p := range s.provinces {
+		if _, ok := s.provinceVersions[id]; !ok {
+			s.trackProvince(p)
+		}
+	}
+
+	s.unitVersions = make(map[int][]models.UnitVersion)
+	for _, v := range snap.UnitVersions {
+		s.unitVersions[v.ID] = append(s.unitVersions[v.ID], v)
+	}
+	for id, u := range s.units {
+		if _, ok := s.unitVersions[id]; !ok {
+			s.trackUnit(u)
+		}
+	}
+	for _, versions := range s.unitVersions {
+		sort.SliceStable(versions, func(i, j int) bool { return versions[i].ValidFrom.Before(versions[j].ValidFrom) })
+	}
+	for _, versions := range s.provinceVersions {
+		sort.SliceStable(versions, func(i, j int) bool { return versions[i].ValidFrom.Before(versions[j].ValidFrom) })
+	}
+}
+
+// allVersions returns the whole history ordered by ID, oldest version first. Caller must hold s.mu.
+func (s *MemoryStore) allVersions() ([]models.ProvinceVersion, []models.UnitVersion) {
+	var provinces []models.ProvinceVersion
+	for _, p := range sortedProvinces(s.provinces) {
+		provinces = append(provinces, s.provinceVersions[p.ID]...)
+	}
+	var units []models.UnitVersion
+	for _, u := range s.filterUnits(func(models.AdminUnit) bool { return true }) {
+		units = append(units, s.unitVersions[u.ID]...)
+	}
+	return provinces, units
+}
@@ -0,0 +1,124 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"vn-admin-api/internal/config"
+	"vn-admin-api/internal/database"
+	"vn-admin-api/internal/models"
+)
+
+// Store is the interface for persistence - implemented by Postgres or in-memory
+type Store interface {
+	GetProvinces(ctx context.Context) ([]models.Province, error)
+	GetUnitsByProvince(ctx context.Context, provinceID int) ([]models.AdminUnit, error)
+	ListAdminUnits(ctx context.Context, q models.UnitQuery) ([]models.AdminUnit, int, error)
+	GetAdminUnit(ctx context.Context, id int) (*models.AdminUnit, error)
+	GetAdminUnitByCode(ctx context.Context, code string) (*models.AdminUnit, error)
+	GetPredecessors(ctx context.Context, unitID int) ([]models.Predecessor, error)
+	FindPredecessorsByName(ctx context.Context, name string) ([]models.Predecessor, error)
+	SearchAdminUnits(ctx context.Context, params models.SearchParams) ([]models.SearchResult, error)
+	SuggestUnitName(ctx context.Context, query string) (string, error)
+	UpsertProvince(ctx context.Context, p models.Province) error
+	UpsertAdminUnit(ctx context.Context, u models.AdminUnit) error
+	ReplacePredecessors(ctx context.Context, unitID int, preds []models.Predecessor) error
+	SetGSOCodes(ctx context.Context, provinces, units map[int]string) error
+
+	// Version history: the state at a past instant, and every version of a unit
+	GetProvincesAsOf(ctx context.Context, asOf time.Time) ([]models.Province, error)
+	GetUnitsAsOf(ctx context.Context, asOf time.Time, provinceID int) ([]models.AdminUnit, error)
+	GetUnitHistory(ctx context.Context, id int) ([]models.UnitVersion, error)
+
+	// Change log of crawl runs
+	SaveChanges(ctx context.Context, runID string, changes []models.Change) error
+	GetChanges(ctx context.Context, q models.ChangeQuery) ([]models.Change, error)
+
+	// Retirement of records gone upstream, and delta sync. Other reads skip retired records,
+	// except lookups by ID or code and queries with IncludeRetired.
+	Retire(ctx context.Context, provinceIDs, unitIDs []int) error
+	GetRetired(ctx context.Context) ([]models.Province, []models.AdminUnit, error)
+	GetSyncDelta(ctx context.Context, since int64) (*models.SyncDelta, error)
+	GetSyncVersion(ctx context.Context) (int64, error)
+
+	// Staged crawl runs: a crawl takes the staging area, stages provinces one at a time, then
+	// publishes them to the live data together with the run's changes (removals are retired) in
+	// one atomic step. BeginStaging fails with ErrStagingBusy while another crawl holds it.
+	BeginStaging(ctx context.Context) (release func(), err error)
+	StageProvince(ctx context.Context, runID string, p models.Province, units []models.AdminUnit, preds []models.Predecessor) error
+	Publish(ctx context.Context, runID string, changes []models.Change) error
+
+	// Boundary polygons
+	UpsertBoundary(ctx context.Context, b models.Boundary) error
+	GetBoundaries(ctx context.Context, kind string) ([]models.Boundary, error)
+	GetBoundariesByIDs(ctx context.Context, kind string, ids []int) ([]models.Boundary, error)
+
+	// External ID crosswalk
+	SaveExternalIDs(ctx context.Context, system string, ids []models.ExternalID, replace bool) error
+	GetExternalIDs(ctx context.Context, kind string, refID int) ([]models.ExternalID, error)
+	GetExternalID(ctx context.Context, system, externalID string) (*models.ExternalID, error)
+
+	// Bulk conversion jobs
+	CreateJob(ctx context.Context, job models.Job, input []byte) error
+	ClaimJob(ctx context.Context, staleAfter time.Duration, maxAttempts int) (*models.Job, []byte, error)
+	UpdateJobProgress(ctx context.Context, id string, attempt, processed, matched int) error
+	CompleteJob(ctx context.Context, id string, attempt, processed, matched int, result []byte) error
+	FailJob(ctx context.Context, id string, attempt int, message string) error
+	ReleaseJob(ctx context.Context, id string, attempt int) error
+	GetJob(ctx context.Context, id string) (*models.Job, error)
+	GetJobResult(ctx context.Context, id string) ([]byte, error)
+
+	Ping(ctx context.Context) error
+	Close() error
+}
+
+var _ Store = (*database.Repository)(nil)
+
+// ErrNotFound is returned by every backend when a requested record does not exist
+var ErrNotFound = database.ErrNotFound
+
+// ErrJobLost is returned by job updates from a worker whose claim is no longer current
+var ErrJobLost = database.ErrJobLost
+
+// ErrStagingBusy is returned by BeginStaging while another crawl holds the staging area
+var ErrStagingBusy = database.ErrStagingBusy
+
+// Supported values for STORAGE_DRIVER
+const (
+	DriverPostgres = "postgres"
+	DriverMemory   = "memory"
+)
+
+// Open creates the Store selected by cfg.StorageDriver.
+// Postgres connections are verified against the embedded schema version;
+// the memory store is seeded from cfg.DataFile when that file exists.
+func Open(ctx context.Context, cfg *config.Config) (Store, error) {
+	switch cfg.StorageDriver {
+	case DriverPostgres:
+		repo, err := database.Connect(cfg)
+		if err != nil {
+			return nil, err
+		}
+		if err := repo.CheckSchemaVersion(ctx); err != nil {
+			_ = repo.Close()
+			return nil, err
+		}
+		return repo, nil
+
+	case DriverMemory:
+		store := NewMemoryStore()
+		if cfg.DataFile == "" {
+			return store, nil
+		}
+		if err := store.LoadFile(cfg.DataFile); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return nil, err
+		}
+		return store, nil
+
+	default:
+		return nil, fmt.Errorf("unknown storage driver %q", cfg.StorageDriver)
+	}
+}
@@ -0,0 +1,184 @@
+package tiles
+
+// Minimal encoder for the Mapbox Vector Tile format (spec v2.1). Only the message fields
+// the renderer writes are supported, so no protobuf dependency is needed.
+
+// Protobuf wire types
+const (
+	wireVarint = 0
+	wireBytes  = 2
+)
+
+// Geometry types (Tile.GeomType)
+const (
+	geomPoint   = 1
+	geomPolygon = 3
+)
+
+// Geometry commands
+const (
+	cmdMoveTo    = 1
+	cmdLineTo    = 2
+	cmdClosePath = 7
+)
+
+type pbuf []byte
+
+func (b *pbuf) varint(v uint64) {
+	for v >= 0x80 {
+		*b = append(*b, byte(v)|0x80)
+		v >>= 7
+	}
+	*b = append(*b, byte(v))
+}
+
+func (b *pbuf) key(field, wire int) {
+	b.varint(uint64(field<<3 | wire))
+}
+
+func (b *pbuf) uint(field int, v uint64) {
+	b.key(field, wireVarint)
+	b.varint(v)
+}
+
+func (b *pbuf) bytes(field int, data []byte) {
+	b.key(field, wireBytes)
+	b.varint(uint64(len(data)))
+	*b = append(*b, data...)
+}
+
+func (b *pbuf) string(field int, s string) {
+	b.bytes(field, []byte(s))
+}
+
+func (b *pbuf) packed(field int, vs []uint32) {
+	var inner pbuf
+	for _, v := range vs {
+		inner.varint(uint64(v))
+	}
+	b.bytes(field, inner)
+}
+
+// value is a Tile.Value; only strings and unsigned integers are used
+type value struct {
+	s     string
+	u     uint64
+	isInt bool
+}
+
+func stringValue(s string) value { return value{s: s} }
+func intValue(u uint64) value    { return value{u: u, isInt: true} }
+
+type feature struct {
+	id       uint64
+	geomType int
+	geometry []uint32
+	tags     []uint32
+}
+
+// layer accumulates features and dedupes their property keys and values
+type layer struct {
+	name     string
+	features []feature
+	keys     []string
+	keyIdx   map[string]uint32
+	values   []value
+	valueIdx map[value]uint32
+}
+
+func newLayer(name string) *layer {
+	return &layer{name: name, keyIdx: make(map[string]uint32), valueIdx: make(map[value]uint32)}
+}
+
+// add appends a feature; keys[i] is the property name of values[i]
+func (l *layer) add(id uint64, geomType int, geometry []uint32, keys []string, values []value) {
+	f := feature{id: id, geomType: geomType, geometry: geometry, tags: make([]uint32, 0, 2*len(keys))}
+	for i, k := range keys {
+		ki, ok := l.keyIdx[k]
+		if !ok {
+			ki = uint32(len(l.keys))
+			l.keyIdx[k] = ki
+			l.keys = append(l.keys, k)
+		}
+		vi, ok := l.valueIdx[values[i]]
+		if !ok {
+			vi = uint32(len(l.values))
+			l.valueIdx[values[i]] = vi
+			l.values = append(l.values, values[i])
+		}
+		f.tags = append(f.tags, ki, vi)
+	}
+	l.features = append(l.features, f)
+}
+
+func (l *layer) encode() []byte {
+	var b pbuf
+	b.uint(15, 2) // version
+	b.string(1, l.name)
+	for _, f := range l.features {
+		var fb pbuf
+		fb.uint(1, f.id)
+		if len(f.tags) > 0 {
+			fb.packed(2, f.tags)
+		}
+		fb.uint(3, uint64(f.geomType))
+		fb.packed(4, f.geometry)
+		b.bytes(2, fb)
+	}
+	for _, k := range l.keys {
+		b.string(3, k)
+	}
+	for _, v := range l.values {
+		var vb pbuf
+		if v.isInt {
+			vb.uint(5, v.u)
+		} else {
+			vb.string(1, v.s)
+		}
+		b.bytes(4, vb)
+	}
+	b.uint(5, Extent)
+	return b
+}
+
+// encodeTile writes the non-empty layers as a Tile message
+func encodeTile(layers ...*layer) []byte {
+	var b pbuf
+	for _, l := range layers {
+		if len(l.features) > 0 {
+			b.bytes(3, l.encode())
+		}
+	}
+	return b
+}
+
+func command(id, count int) uint32 {
+	return uint32(id&0x7) | uint32(count)<<3
+}
+
+func zigzag(n int) uint32 {
+	v := int32(n)
+	return uint32((v << 1) ^ (v >> 31))
+}
+
+// geometryWriter encodes commands with positions relative to the previous one
+type geometryWriter struct {
+	cmds   []uint32
+	cx, cy int
+}
+
+func (g *geometryWriter) point(x, y int) {
+	g.cmds = append(g.cmds, command(cmdMoveTo, 1), zigzag(x-g.cx), zigzag(y-g.cy))
+	g.cx, g.cy = x, y
+}
+
+// ring writes a closed ring without its repeated closing position
+func (g *geometryWriter) ring(pts [][2]int) {
+	g.point(pts[0][0], pts[0][1])
+	g.cmds = append(g.cmds, command(cmdLineTo, len(pts)-1))
+	for _, p := range pts[1:] {
+		g.cmds = append(g.cmds, zigzag(p[0]-g.cx), zigzag(p[1]-g.cy))
+		g.cx, g.cy = p[0], p[1]
+	}
+	g.cmds = append(g.cmds, command(cmdClosePath, 1))
+}
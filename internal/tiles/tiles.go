@@ -0,0 +1,310 @@
+// Package tiles renders Mapbox Vector Tiles from the in-memory spatial indexes:
+// unit centroids from geo.Index and imported outlines from geo.BoundaryIndex.
+package tiles
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+
+	"vn-admin-api/internal/geo"
+	"vn-admin-api/internal/models"
+)
+
+const (
+	Extent  = 4096 // tile coordinate space
+	Buffer  = 64   // extra tile units rendered around each edge so strokes join across tiles
+	MaxZoom = 18
+
+	// MinUnitZoom is the lowest zoom at which ward centroids and outlines are drawn;
+	// below it a tile covering the whole country would hold every ward.
+	MinUnitZoom = 6
+
+	// simplifyTolerance is half a pixel of a 512px tile. It is applied in tile space,
+	// so lower zoom levels drop proportionally more detail.
+	simplifyTolerance = Extent / 512 / 2
+
+	maxMercatorLat = 85.05112878
+)
+
+// Layer names
+const (
+	LayerProvinces     = "provinces"       // province outlines
+	LayerUnitOutlines  = "unit_boundaries" // ward/commune outlines
+	LayerUnitCentroids = "units"           // ward/commune centroids with name and level
+)
+
+// Tile addresses an XYZ (slippy map) tile
+type Tile struct {
+	Z, X, Y int
+}
+
+// Valid reports whether the tile exists at its zoom level
+func (t Tile) Valid() bool {
+	if t.Z < 0 || t.Z > MaxZoom {
+		return false
+	}
+	n := 1 << t.Z
+	return t.X >= 0 && t.X < n && t.Y >= 0 && t.Y < n
+}
+
+func (t Tile) String() string {
+	return fmt.Sprintf("%d/%d/%d", t.Z, t.X, t.Y)
+}
+
+// Points and Outlines are the index lookups a tile is rendered from
+type Points interface {
+	InBBox(box geo.BBox) []models.AdminUnit
+}
+
+type Outlines interface {
+	Intersecting(box geo.BBox) (provinces, units []geo.Outline)
+}
+
+// Render encodes the tile. An empty result means nothing falls inside it.
+func Render(t Tile, points Points, outlines Outlines) []byte {
+	p := newProjection(t)
+	box := p.bounds()
+
+	provinceLayer := newLayer(LayerProvinces)
+	unitOutlineLayer := newLayer(LayerUnitOutlines)
+	unitLayer := newLayer(LayerUnitCentroids)
+
+	provinces, units := outlines.Intersecting(box)
+	for _, o := range provinces {
+		addOutline(provinceLayer, p, o)
+	}
+	if t.Z >= MinUnitZoom {
+		for _, o := range units {
+			addOutline(unitOutlineLayer, p, o)
+		}
+		for _, u := range points.InBBox(box) {
+			x, y := p.project(u.Long, u.Lat)
+			var g geometryWriter
+			g.point(int(math.Round(x)), int(math.Round(y)))
+			unitLayer.add(uint64(u.ID), geomPoint, g.cmds,
+				[]string{"id", "name", "level", "level_code", "code", "province_id"},
+				[]value{intValue(uint64(u.ID)), stringValue(u.Name), stringValue(u.Level), stringValue(u.LevelCode), stringValue(u.Code), intValue(uint64(u.ProvinceID))},
+			)
+		}
+	}
+	return encodeTile(provinceLayer, unitOutlineLayer, unitLayer)
+}
+
+// Key identifies a rendered tile in the cache. version must change whenever the indexed data
+// does, so stale tiles are never served after a rebuild.
+func Key(t Tile, version string) string {
+	return t.String() + "@" + version
+}
+
+func addOutline(l *layer, p projection, o geo.Outline) {
+	var g geometryWriter
+	for _, polygon := range o.Shape.Polygons {
+		for i, ring := range polygon {
+			pts := p.ring(ring)
+			if pts == nil {
+				if i == 0 {
+					break // holes of a dropped exterior are dropped with it
+				}
+				continue
+			}
+			// Exterior rings wind clockwise in tile space (positive area), holes the other way
+			if exterior := i == 0; (area(pts) > 0) != exterior {
+				reverse(pts)
+			}
+			g.ring(pts)
+		}
+	}
+	if len(g.cmds) > 0 {
+		l.add(uint64(o.ID), geomPolygon, g.cmds, []string{"id"}, []value{intValue(uint64(o.ID))})
+	}
+}
+
+// projection maps WGS84 to the tile's coordinate space (Web Mercator)
+type projection struct {
+	tile Tile
+	n    float64 // tiles per axis at this zoom
+}
+
+func newProjection(t Tile) projection {
+	return projection{tile: t, n: float64(int(1) << t.Z)}
+}
+
+func (p projection) project(lng, lat float64) (x, y float64) {
+	lat = math.Max(-maxMercatorLat, math.Min(maxMercatorLat, lat))
+	rad := lat * math.Pi / 180
+	wx := (lng + 180) / 360 * p.n
+	wy := (1 - math.Log(math.Tan(rad)+1/math.Cos(rad))/math.Pi) / 2 * p.n
+	return (wx - float64(p.tile.X)) * Extent, (wy - float64(p.tile.Y)) * Extent
+}
+
+// bounds returns the WGS84 box of the tile including its buffer
+func (p projection) bounds() geo.BBox {
+	b := float64(Buffer) / Extent
+	unproject := func(tx, ty float64) (lng, lat float64) {
+		lng = tx/p.n*360 - 180
+		lat = math.Atan(math.Sinh(math.Pi*(1-2*ty/p.n))) * 180 / math.Pi
+		return lng, lat
+	}
+	minLng, maxLat := unproject(float64(p.tile.X)-b, float64(p.tile.Y)-b)
+	maxLng, minLat := unproject(float64(p.tile.X+1)+b, float64(p.tile.Y+1)+b)
+	return geo.BBox{MinLng: minLng, MinLat: minLat, MaxLng: maxLng, MaxLat: maxLat}
+}
+
+// ring projects, clips and simplifies a GeoJSON ring. It returns the integer positions without
+// the closing one, or nil when fewer than three distinct positions remain.
+func (p projection) ring(r geo.Ring) [][2]int {
+	pts := make([][2]float64, len(r))
+	for i, pos := range r {
+		pts[i][0], pts[i][1] = p.project(pos[0], pos[1])
+	}
+	pts = clip(pts, -Buffer, Extent+Buffer)
+	if len(pts) < 3 {
+		return nil
+	}
+	pts = append(pts, pts[0])
+	pts = simplify(pts, simplifyTolerance)
+
+	out := make([][2]int, 0, len(pts))
+	for _, pt := range pts {
+		q := [2]int{int(math.Round(pt[0])), int(math.Round(pt[1]))}
+		if len(out) > 0 && out[len(out)-1] == q {
+			continue
+		}
+		out = append(out, q)
+	}
+	if len(out) > 1 && out[0] == out[len(out)-1] {
+		out = out[:len(out)-1]
+	}
+	if len(out) < 3 || area(out) == 0 {
+		return nil
+	}
+	return out
+}
+
+// clip cuts a ring to the square [lo, hi]² (Sutherland–Hodgman). The result is open:
+// the closing position is not repeated.
+func clip(pts [][2]float64, lo, hi float64) [][2]float64 {
+	if len(pts) > 1 && pts[0] == pts[len(pts)-1] {
+		pts = pts[:len(pts)-1]
+	}
+	type edge struct {
+		axis  int
+		bound float64
+		keep  func(v, bound float64) bool
+	}
+	ge := func(v, bound float64) bool { return v >= bound }
+	le := func(v, bound float64) bool { return v <= bound }
+	edges := []edge{{0, lo, ge}, {0, hi, le}, {1, lo, ge}, {1, hi, le}}
+
+	for _, e := range edges {
+		if len(pts) == 0 {
+			return nil
+		}
+		in := pts
+		pts = make([][2]float64, 0, len(in)+4)
+		prev := in[len(in)-1]
+		for _, cur := range in {
+			curIn, prevIn := e.keep(cur[e.axis], e.bound), e.keep(prev[e.axis], e.bound)
+			if curIn != prevIn {
+				pts = append(pts, intersect(prev, cur, e.axis, e.bound))
+			}
+			if curIn {
+				pts = append(pts, cur)
+			}
+			prev = cur
+		}
+	}
+	return pts
+}
+
+// intersect returns the point where segment a-b crosses the line pos[axis] = bound
+func intersect(a, b [2]float64, axis int, bound float64) [2]float64 {
+	t := (bound - a[axis]) / (b[axis] - a[axis])
+	var p [2]float64
+	p[axis] = bound
+	other := 1 - axis
+	p[other] = a[other] + t*(b[other]-a[other])
+	return p
+}
+
+// simplify is Douglas–Peucker; the first and last positions are always kept
+func simplify(pts [][2]float64, tolerance float64) [][2]float64 {
+	if len(pts) < 3 {
+		return pts
+	}
+	keep := make([]bool, len(pts))
+	keep[0], keep[len(pts)-1] = true, true
+
+	type span struct{ first, last int }
+	stack := []span{{0, len(pts) - 1}}
+	for len(stack) > 0 {
+		s := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+
+		maxDist, index := 0.0, 0
+		for i := s.first + 1; i < s.last; i++ {
+			if d := segmentDistance(pts[i], pts[s.first], pts[s.last]); d > maxDist {
+				maxDist, index = d, i
+			}
+		}
+		if maxDist > tolerance {
+			keep[index] = true
+			stack = append(stack, span{s.first, index}, span{index, s.last})
+		}
+	}
+
+	out := make([][2]float64, 0, len(pts))
+	for i, k := range keep {
+		if k {
+			out = append(out, pts[i])
+		}
+	}
+	return out
+}
+
+// segmentDistance is the distance from p to the segment a-b
+func segmentDistance(p, a, b [2]float64) float64 {
+	dx, dy := b[0]-a[0], b[1]-a[1]
+	if dx == 0 && dy == 0 {
+		return math.Hypot(p[0]-a[0], p[1]-a[1])
+	}
+	t := ((p[0]-a[0])*dx + (p[1]-a[1])*dy) / (dx*dx + dy*dy)
+	t = math.Max(0, math.Min(1, t))
+	return math.Hypot(p[0]-(a[0]+t*dx), p[1]-(a[1]+t*dy))
+}
+
+// area is twice the signed area of an open ring (positive when clockwise with y pointing down)
+func area(pts [][2]int) int {
+	sum := 0
+	for i := range pts {
+		j := (i + 1) % len(pts)
+		sum += pts[i][0]*pts[j][1] - pts[j][0]*pts[i][1]
+	}
+	return sum
+}
+
+func reverse(pts [][2]int) {
+	for i, j := 0, len(pts)-1; i < j; i, j = i+1, j-1 {
+		pts[i], pts[j] = pts[j], pts[i]
+	}
+}
+
+// ParseTile reads the z/x/y path segments
+func ParseTile(z, x, y string) (Tile, error) {
+	var t Tile
+	var err error
+	if t.Z, err = strconv.Atoi(z); err != nil {
+		return t, fmt.Errorf("invalid zoom %q", z)
+	}
+	if t.X, err = strconv.Atoi(x); err != nil {
+		return t, fmt.Errorf("invalid x %q", x)
+	}
+	if t.Y, err = strconv.Atoi(y); err != nil {
+		return t, fmt.Errorf("invalid y %q", y)
+	}
+	if !t.Valid() {
+		return t, fmt.Errorf("tile %s does not exist (zoom 0-%d)", t, MaxZoom)
+	}
+	return t, nil
+}
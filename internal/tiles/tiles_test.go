@@ -0,0 +1,188 @@
+package tiles
+
+import (
+	"encoding/json"
+	"slices"
+	"testing"
+
+	"vn-admin-api/internal/geo"
+	"vn-admin-api/internal/models"
+)
+
+type fakeIndex struct {
+	units      []models.AdminUnit
+	provinces  []geo.Outline
+	unitShapes []geo.Outline
+}
+
+func (f fakeIndex) InBBox(box geo.BBox) []models.AdminUnit {
+	var found []models.AdminUnit
+	for _, u := range f.units {
+		if box.Contains(u.Lat, u.Long) {
+			found = append(found, u)
+		}
+	}
+	return found
+}
+
+func (f fakeIndex) Intersecting(box geo.BBox) (provinces, units []geo.Outline) {
+	return f.provinces, f.unitShapes
+}
+
+func outline(t *testing.T, id int, geometry string) geo.Outline {
+	t.Helper()
+	shape, err := geo.ParseGeometry(json.RawMessage(geometry))
+	if err != nil {
+		t.Fatalf("ParseGeometry: %v", err)
+	}
+	return geo.Outline{ID: id, Shape: shape}
+}
+
+// decoded holds the parts of a layer the tests check
+type decoded struct {
+	ids        []uint64
+	types      []uint64
+	geometries [][]uint32
+	keys       []string
+	strings    []string
+	extent     uint64
+}
+
+func readVarint(b []byte, i *int) uint64 {
+	var v uint64
+	for shift := 0; ; shift += 7 {
+		c := b[*i]
+		*i++
+		v |= uint64(c&0x7f) << shift
+		if c < 0x80 {
+			return v
+		}
+	}
+}
+
+// fields calls fn for each field of a message; bytes is nil for varints
+func fields(b []byte, fn func(field int, v uint64, bytes []byte)) {
+	for i := 0; i < len(b); {
+		key := readVarint(b, &i)
+		switch key & 7 {
+		case wireVarint:
+			fn(int(key>>3), readVarint(b, &i), nil)
+		case wireBytes:
+			n := int(readVarint(b, &i))
+			fn(int(key>>3), 0, b[i:i+n])
+			i += n
+		default:
+			panic("unexpected wire type")
+		}
+	}
+}
+
+func decodeTile(data []byte) map[string]*decoded {
+	layers := make(map[string]*decoded)
+	fields(data, func(_ int, _ uint64, layerBytes []byte) {
+		l := &decoded{}
+		var name string
+		fields(layerBytes, func(field int, v uint64, b []byte) {
+			switch field {
+			case 1:
+				name = string(b)
+			case 2:
+				fields(b, func(field int, v uint64, b []byte) {
+					switch field {
+					case 1:
+						l.ids = append(l.ids, v)
+					case 3:
+						l.types = append(l.types, v)
+					case 4:
+						var cmds []uint32
+						for i := 0; i < len(b); {
+							cmds = append(cmds, uint32(readVarint(b, &i)))
+						}
+						l.geometries = append(l.geometries, cmds)
+					}
+				})
+			case 3:
+				l.keys = append(l.keys, string(b))
+			case 4:
+				fields(b, func(field int, _ uint64, b []byte) {
+					if field == 1 {
+						l.strings = append(l.strings, string(b))
+					}
+				})
+			case 5:
+				l.extent = v
+			}
+		})
+		layers[name] = l
+	})
+	return layers
+}
+
+func TestRender(t *testing.T) {
+	idx := fakeIndex{
+		units: []models.AdminUnit{
+			{ID: 101, ProvinceID: 1, Name: "Phường Ba Đình", Level: "Phường", Code: "00004", Lat: 21.034, Long: 105.84},
+			{ID: 201, ProvinceID: 2, Name: "Phường Bến Thành", Level: "Phường", Code: "26740", Lat: 10.77, Long: 106.69},
+		},
+		// Covers the whole tile, so it is clipped to the buffered square
+		provinces: []geo.Outline{outline(t, 1, `{"type": "Polygon", "coordinates": [[[105, 20.5], [106.5, 20.5], [106.5, 21.5], [105, 21.5], [105, 20.5]]]}`)},
+	}
+
+	// Ba Đình lies at (229, 3190) in tile 10/813/450
+	layers := decodeTile(Render(Tile{Z: 10, X: 813, Y: 450}, idx, idx))
+
+	units := layers[LayerUnitCentroids]
+	if units == nil || len(units.ids) != 1 || units.ids[0] != 101 || units.types[0] != geomPoint {
+		t.Fatalf("units layer = %+v, want one point for unit 101", units)
+	}
+	if want := []uint32{command(cmdMoveTo, 1), zigzag(229), zigzag(3190)}; !slices.Equal(units.geometries[0], want) {
+		t.Errorf("point geometry = %v, want %v", units.geometries[0], want)
+	}
+	if units.extent != Extent || !slices.Contains(units.keys, "name") || !slices.Contains(units.strings, "Phường Ba Đình") {
+		t.Errorf("units layer properties = keys %v values %v extent %d", units.keys, units.strings, units.extent)
+	}
+
+	provinces := layers[LayerProvinces]
+	if provinces == nil || len(provinces.ids) != 1 || provinces.types[0] != geomPolygon {
+		t.Fatalf("provinces layer = %+v, want one polygon", provinces)
+	}
+	// The clipped exterior is the buffered square
+	g := provinces.geometries[0]
+	if len(g) != 11 || g[0] != command(cmdMoveTo, 1) || g[3] != command(cmdLineTo, 3) || g[10] != command(cmdClosePath, 1) {
+		t.Errorf("province geometry = %v, want a 4-corner ring", g)
+	}
+
+	// Below MinUnitZoom only provinces are drawn
+	layers = decodeTile(Render(Tile{Z: 4, X: 12, Y: 7}, idx, idx))
+	if layers[LayerUnitCentroids] != nil || layers[LayerProvinces] == nil {
+		t.Errorf("zoom 4 layers = %v, want provinces only", layers)
+	}
+
+	if data := Render(Tile{Z: 10, X: 0, Y: 0}, fakeIndex{}, fakeIndex{}); len(data) != 0 {
+		t.Errorf("empty tile encoded to %d bytes, want 0", len(data))
+	}
+}
+
+func TestRingSimplifiesAndOrients(t *testing.T) {
+	p := newProjection(Tile{Z: 0})
+	// Counter-clockwise as drawn (GeoJSON convention), with a collinear midpoint to drop
+	ring := geo.Ring{{-90, -45}, {0, -45}, {90, -45}, {90, 45}, {-90, 45}, {-90, -45}}
+	pts := p.ring(ring)
+	if len(pts) != 4 {
+		t.Fatalf("ring kept %d positions %v, want 4 corners", len(pts), pts)
+	}
+	if area(pts) >= 0 {
+		t.Errorf("area = %d, want negative before orientation", area(pts))
+	}
+}
+
+func TestParseTile(t *testing.T) {
+	if tile, err := ParseTile("10", "813", "450"); err != nil || tile != (Tile{10, 813, 450}) {
+		t.Errorf("ParseTile = %v, %v", tile, err)
+	}
+	for _, c := range [][3]string{{"2", "4", "0"}, {"19", "0", "0"}, {"-1", "0", "0"}, {"a", "0", "0"}} {
+		if _, err := ParseTile(c[0], c[1], c[2]); err == nil {
+			t.Errorf("ParseTile(%v) succeeded, want error", c)
+		}
+	}
+}
@@ -0,0 +1,170 @@
+// Package vn2000 converts between VN-2000 projected coordinates and WGS84 latitude/longitude.
+//
+// VN-2000 uses the WGS84 ellipsoid on a local datum, related to WGS84 by the official 7-parameter
+// Helmert transformation (Decision 05/2007/QĐ-BTNMT). Cadastral and provincial datasets are
+// projected with Transverse Mercator in 3° zones (TM3, scale factor 0.9999) on the province's
+// central meridian (Circular 973/2001/TT-TCĐC). Following Vietnamese survey convention, X is the
+// northing and Y the easting, both in metres. Heights are taken as 0 on the ellipsoid; the
+// horizontal error this introduces is below a millimetre for heights of a few hundred metres.
+package vn2000
+
+import (
+	"fmt"
+	"math"
+)
+
+// WGS84 ellipsoid, shared by VN-2000
+const (
+	semiMajor  = 6378137.0
+	flattening = 1 / 298.257223563
+)
+
+// TM3 projection constants
+const (
+	TM3ScaleFactor = 0.9999
+	FalseEasting   = 500000.0
+)
+
+// VN-2000 → WGS84 Helmert parameters (position vector convention): translations in metres,
+// rotations in arc seconds, scale in ppm
+const (
+	shiftX = -191.90441429
+	shiftY = -39.30318279
+	shiftZ = -111.45032835
+	rotX   = -0.00928836
+	rotY   = 0.01975479
+	rotZ   = -0.00427372
+	scale  = 0.252906278
+)
+
+var (
+	e2 = flattening * (2 - flattening) // first eccentricity squared
+	n  = flattening / (2 - flattening) // third flattening
+
+	// Rectifying radius and Krüger series coefficients (4th order in n, sub-millimetre within a zone)
+	rectifying = semiMajor / (1 + n) * (1 + n*n/4 + n*n*n*n/64)
+	alpha      = [4]float64{
+		n/2 - 2*n*n/3 + 5*n*n*n/16 + 41*n*n*n*n/180,
+		13*n*n/48 - 3*n*n*n/5 + 557*n*n*n*n/1440,
+		61*n*n*n/240 - 103*n*n*n*n/140,
+		49561 * n * n * n * n / 161280,
+	}
+	beta = [4]float64{
+		n/2 - 2*n*n/3 + 37*n*n*n/96 - n*n*n*n/360,
+		n*n/48 + n*n*n/15 - 437*n*n*n*n/1440,
+		17*n*n*n/480 - 37*n*n*n*n/840,
+		4397 * n * n * n * n / 161280,
+	}
+	delta = [4]float64{
+		2*n - 2*n*n/3 - 2*n*n*n + 116*n*n*n*n/45,
+		7*n*n/3 - 8*n*n*n/5 - 227*n*n*n*n/45,
+		56*n*n*n/15 - 136*n*n*n*n/35,
+		4279 * n * n * n * n / 630,
+	}
+)
+
+// Zone is a VN-2000 Transverse Mercator zone
+type Zone struct {
+	CentralMeridian float64 `json:"central_meridian"` // degrees east
+	ScaleFactor     float64 `json:"scale_factor"`
+}
+
+// TM3 returns the 3° zone on the given central meridian
+func TM3(centralMeridian float64) Zone {
+	return Zone{CentralMeridian: centralMeridian, ScaleFactor: TM3ScaleFactor}
+}
+
+// Validate checks the central meridian lies within Vietnam's zones
+func (z Zone) Validate() error {
+	if z.CentralMeridian < 102 || z.CentralMeridian > 112 {
+		return fmt.Errorf("central meridian %g is outside 102-112°E", z.CentralMeridian)
+	}
+	return nil
+}
+
+// ToWGS84 converts VN-2000 projected X (northing) / Y (easting) to WGS84 latitude/longitude
+func (z Zone) ToWGS84(x, y float64) (lat, lng float64) {
+	phi, lambda := z.inverse(x, y)
+	return transform(phi, lambda, 1)
+}
+
+// FromWGS84 converts WGS84 latitude/longitude to VN-2000 projected X (northing) / Y (easting)
+func (z Zone) FromWGS84(lat, lng float64) (x, y float64) {
+	phi, lambda := transform(lat, lng, -1)
+	return z.forward(phi, lambda)
+}
+
+// forward projects VN-2000 geodetic degrees (Krüger series)
+func (z Zone) forward(lat, lng float64) (x, y float64) {
+	phi := lat * math.Pi / 180
+	dLambda := (lng - z.CentralMeridian) * math.Pi / 180
+
+	e := math.Sqrt(e2)
+	t := math.Sinh(math.Atanh(math.Sin(phi)) - e*math.Atanh(e*math.Sin(phi)))
+	xi := math.Atan2(t, math.Cos(dLambda))
+	eta := math.Atanh(math.Sin(dLambda) / math.Sqrt(1+t*t))
+
+	northing, easting := xi, eta
+	for j, a := range alpha {
+		k := 2 * float64(j+1)
+		northing += a * math.Sin(k*xi) * math.Cosh(k*eta)
+		easting += a * math.Cos(k*xi) * math.Sinh(k*eta)
+	}
+	k0 := z.ScaleFactor * rectifying
+	return k0 * northing, FalseEasting + k0*easting
+}
+
+// inverse unprojects to VN-2000 geodetic degrees
+func (z Zone) inverse(x, y float64) (lat, lng float64) {
+	k0 := z.ScaleFactor * rectifying
+	xi := x / k0
+	eta := (y - FalseEasting) / k0
+
+	xiP, etaP := xi, eta
+	for j, b := range beta {
+		k := 2 * float64(j+1)
+		xiP -= b * math.Sin(k*xi) * math.Cosh(k*eta)
+		etaP -= b * math.Cos(k*xi) * math.Sinh(k*eta)
+	}
+	chi := math.Asin(math.Sin(xiP) / math.Cosh(etaP))
+	phi := chi
+	for j, d := range delta {
+		phi += d * math.Sin(2*float64(j+1)*chi)
+	}
+	lambda := math.Atan2(math.Sinh(etaP), math.Cos(xiP))
+	return phi * 180 / math.Pi, z.CentralMeridian + lambda*180/math.Pi
+}
+
+// transform applies the Helmert shift between datums: direction 1 is VN-2000 → WGS84,
+// -1 the reverse (parameters negated, exact to well below a millimetre for rotations this small)
+func transform(lat, lng, direction float64) (float64, float64) {
+	px, py, pz := toECEF(lat, lng)
+
+	arcsec := math.Pi / 180 / 3600
+	rx, ry, rz := direction*rotX*arcsec, direction*rotY*arcsec, direction*rotZ*arcsec
+	m := 1 + direction*scale*1e-6
+
+	qx := direction*shiftX + m*(px-rz*py+ry*pz)
+	qy := direction*shiftY + m*(rz*px+py-rx*pz)
+	qz := direction*shiftZ + m*(-ry*px+rx*py+pz)
+	return fromECEF(qx, qy, qz)
+}
+
+func toECEF(lat, lng float64) (x, y, z float64) {
+	phi, lambda := lat*math.Pi/180, lng*math.Pi/180
+	sin := math.Sin(phi)
+	nu := semiMajor / math.Sqrt(1-e2*sin*sin)
+	return nu * math.Cos(phi) * math.Cos(lambda), nu * math.Cos(phi) * math.Sin(lambda), nu * (1 - e2) * sin
+}
+
+// fromECEF returns geodetic degrees, iterating on latitude (converges in a few steps near the surface)
+func fromECEF(x, y, z float64) (lat, lng float64) {
+	p := math.Hypot(x, y)
+	phi := math.Atan2(z, p*(1-e2))
+	for range 5 {
+		sin := math.Sin(phi)
+		nu := semiMajor / math.Sqrt(1-e2*sin*sin)
+		phi = math.Atan2(z+e2*nu*sin, p)
+	}
+	return phi * 180 / math.Pi, math.Atan2(y, x) * 180 / math.Pi
+}
@@ -0,0 +1,86 @@
+package vn2000
+
+import (
+	"math"
+	"testing"
+)
+
+// meridianArc integrates the meridian radius of curvature from the equator (Simpson's rule)
+func meridianArc(lat float64) float64 {
+	const steps = 10000
+	phi := lat * math.Pi / 180
+	radius := func(p float64) float64 {
+		sin := math.Sin(p)
+		return semiMajor * (1 - e2) / math.Pow(1-e2*sin*sin, 1.5)
+	}
+	h := phi / steps
+	sum := radius(0) + radius(phi)
+	for i := 1; i < steps; i++ {
+		w := 2.0
+		if i%2 == 1 {
+			w = 4
+		}
+		sum += w * radius(float64(i)*h)
+	}
+	return sum * h / 3
+}
+
+func TestForwardOnCentralMeridian(t *testing.T) {
+	z := TM3(105)
+	for _, lat := range []float64{8.5, 16, 21.03, 23.4} {
+		x, y := z.forward(lat, 105)
+		if want := TM3ScaleFactor * meridianArc(lat); math.Abs(x-want) > 0.001 {
+			t.Errorf("X at %g° = %.4f, want %.4f", lat, x, want)
+		}
+		if math.Abs(y-FalseEasting) > 1e-6 {
+			t.Errorf("Y on the central meridian = %.6f, want %.0f", y, FalseEasting)
+		}
+	}
+}
+
+func TestRoundTrip(t *testing.T) {
+	z := TM3(105.75)
+	for _, p := range [][2]float64{{21.0285, 105.8542}, {10.7769, 106.7009}, {8.6, 104.3}, {23.36, 107.2}} {
+		x, y := z.FromWGS84(p[0], p[1])
+		lat, lng := z.ToWGS84(x, y)
+		// 1e-8° is about a millimetre
+		if math.Abs(lat-p[0]) > 1e-8 || math.Abs(lng-p[1]) > 1e-8 {
+			t.Errorf("round trip of %v = (%.10f, %.10f)", p, lat, lng)
+		}
+
+		// The datum shift moves positions by a few hundred metres at most
+		vlat, vlng := transform(p[0], p[1], -1)
+		if d := math.Hypot((vlat-p[0])*111320, (vlng-p[1])*111320*math.Cos(p[0]*math.Pi/180)); d == 0 || d > 500 {
+			t.Errorf("datum shift at %v = %.1f m, want (0, 500]", p, d)
+		}
+	}
+}
+
+func TestProvinceZone(t *testing.T) {
+	if z, ok := ProvinceZone(1); !ok || z.CentralMeridian != 105 || z.ScaleFactor != TM3ScaleFactor {
+		t.Errorf("ProvinceZone(1) = %+v, %v, want TM3 105°", z, ok)
+	}
+	if _, ok := ProvinceZone(3); ok {
+		t.Error("ProvinceZone(3) found a zone for an unused code")
+	}
+}
+
+func TestZoneOf(t *testing.T) {
+	tests := []struct {
+		gsoCode  string
+		code     int
+		meridian float64
+		ok       bool
+	}{
+		{"79", 1, 105.75, true}, // The GSO code wins over the upstream code
+		{"", 1, 105, true},      // Upstream code until the crosswalk sets one
+		{"03", 1, 0, false},     // A set GSO code is not second-guessed
+		{"x", 1, 0, false},
+	}
+	for _, tt := range tests {
+		z, ok := ZoneOf(tt.gsoCode, tt.code)
+		if ok != tt.ok || z.CentralMeridian != tt.meridian {
+			t.Errorf("ZoneOf(%q, %d) = %v°, %v; want %v°, %v", tt.gsoCode, tt.code, z.CentralMeridian, ok, tt.meridian, tt.ok)
+		}
+	}
+}
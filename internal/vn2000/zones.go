@@ -0,0 +1,95 @@
+package vn2000
+
+import "strconv"
+
+// centralMeridians maps GSO province codes (Province.GSOCode; see ZoneOf) to the TM3 central meridian
+// of Circular 973/2001/TT-TCĐC, in degrees east. The 2025 mergers kept one of the merged
+// provinces' codes, so the merged province uses that province's meridian; datasets surveyed in
+// another former province's zone need the meridian given explicitly.
+var centralMeridians = map[int]float64{
+	1:  105.00, // Hà Nội
+	2:  105.50, // Hà Giang
+	4:  105.75, // Cao Bằng
+	6:  106.50, // Bắc Kạn
+	8:  106.00, // Tuyên Quang
+	10: 104.75, // Lào Cai
+	11: 103.00, // Điện Biên
+	12: 103.00, // Lai Châu
+	14: 104.00, // Sơn La
+	15: 104.75, // Yên Bái
+	17: 106.00, // Hòa Bình
+	19: 106.50, // Thái Nguyên
+	20: 107.25, // Lạng Sơn
+	22: 107.75, // Quảng Ninh
+	24: 107.00, // Bắc Giang
+	25: 104.75, // Phú Thọ
+	26: 105.00, // Vĩnh Phúc
+	27: 105.50, // Bắc Ninh
+	30: 105.50, // Hải Dương
+	31: 105.75, // Hải Phòng
+	33: 105.50, // Hưng Yên
+	34: 105.50, // Thái Bình
+	35: 105.00, // Hà Nam
+	36: 105.50, // Nam Định
+	37: 105.00, // Ninh Bình
+	38: 105.00, // Thanh Hóa
+	40: 104.75, // Nghệ An
+	42: 105.50, // Hà Tĩnh
+	44: 106.00, // Quảng Bình
+	45: 106.25, // Quảng Trị
+	46: 107.00, // Thừa Thiên Huế
+	48: 107.75, // Đà Nẵng
+	49: 107.75, // Quảng Nam
+	51: 108.00, // Quảng Ngãi
+	52: 108.25, // Bình Định
+	54: 108.50, // Phú Yên
+	56: 108.25, // Khánh Hòa
+	58: 108.25, // Ninh Thuận
+	60: 108.50, // Bình Thuận
+	62: 107.50, // Kon Tum
+	64: 108.50, // Gia Lai
+	66: 108.50, // Đắk Lắk
+	67: 108.50, // Đắk Nông
+	68: 107.75, // Lâm Đồng
+	70: 106.25, // Bình Phước
+	72: 105.50, // Tây Ninh
+	74: 105.75, // Bình Dương
+	75: 107.75, // Đồng Nai
+	77: 107.75, // Bà Rịa - Vũng Tàu
+	79: 105.75, // TP. Hồ Chí Minh
+	80: 105.75, // Long An
+	82: 105.75, // Tiền Giang
+	83: 105.75, // Bến Tre
+	84: 105.50, // Trà Vinh
+	86: 105.50, // Vĩnh Long
+	87: 105.00, // Đồng Tháp
+	89: 104.75, // An Giang
+	91: 104.50, // Kiên Giang
+	92: 105.00, // Cần Thơ
+	93: 105.00, // Hậu Giang
+	94: 105.50, // Sóc Trăng
+	95: 105.00, // Bạc Liêu
+	96: 104.50, // Cà Mau
+}
+
+// ProvinceZone returns the TM3 zone of the province with the given GSO code
+func ProvinceZone(code int) (Zone, bool) {
+	meridian, ok := centralMeridians[code]
+	if !ok {
+		return Zone{}, false
+	}
+	return TM3(meridian), true
+}
+
+// ZoneOf returns the TM3 zone of a province by its official GSO code, falling back to its
+// upstream code (Province.Code) only while the crosswalk import has not set one
+func ZoneOf(gsoCode string, code int) (Zone, bool) {
+	if gsoCode != "" {
+		n, err := strconv.Atoi(gsoCode)
+		if err != nil {
+			return Zone{}, false
+		}
+		code = n
+	}
+	return ProvinceZone(code)
+}
@@ -0,0 +1,63 @@
+// Package vntext provides Vietnamese-aware text normalization shared by every
+// storage backend, so search behaves the same on Postgres and in memory.
+package vntext
+
+import (
+	"strings"
+	"unicode"
+)
+
+// foldGroups lists every precomposed Vietnamese letter by its base letter.
+// The Postgres vn_fold() function (migration 0002) is generated from the same table.
+var foldGroups = map[rune]string{
+	'a': "àáảãạăằắẳẵặâầấẩẫậ",
+	'e': "èéẻẽẹêềếểễệ",
+	'i': "ìíỉĩị",
+	'o': "òóỏõọôồốổỗộơờớởỡợ",
+	'u': "ùúủũụưừứửữự",
+	'y': "ỳýỷỹỵ",
+	'd': "đ",
+}
+
+var foldTable = buildFoldTable()
+
+func buildFoldTable() map[rune]rune {
+	table := make(map[rune]rune)
+	for base, letters := range foldGroups {
+		for _, r := range letters {
+			table[r] = base
+			table[unicode.ToUpper(r)] = base
+		}
+	}
+	return table
+}
+
+// Fold lowercases s and strips Vietnamese diacritics ("Hà Tây" -> "ha tay", "Đình" -> "dinh").
+// Decomposed input (base letter + combining marks) folds to the same result.
+func Fold(s string) string {
+	var b strings.Builder
+	b.Grow(len(s))
+	for _, r := range s {
+		if r >= 0x0300 && r <= 0x036F { // combining diacritical marks
+			continue
+		}
+		if base, ok := foldTable[r]; ok {
+			b.WriteRune(base)
+			continue
+		}
+		b.WriteRune(unicode.ToLower(r))
+	}
+	return b.String()
+}
+
+// Tokens folds s and splits it into alphanumeric words
+func Tokens(s string) []string {
+	return strings.FieldsFunc(Fold(s), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
+
+// HasDiacritics reports whether s contains any Vietnamese diacritic (including đ)
+func HasDiacritics(s string) bool {
+	return Fold(s) != strings.ToLower(s)
+}
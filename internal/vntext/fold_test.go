@@ -0,0 +1,49 @@
+package vntext
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestFold(t *testing.T) {
+	tests := []struct {
+		in, want string
+	}{
+		{"Hà Tây", "ha tay"},
+		{"Phường Ba Đình", "phuong ba dinh"},
+		{"ĐẶC KHU CÔN ĐẢO", "dac khu con dao"},
+		{"Thừa Thiên Huế", "thua thien hue"},
+		{"Hà Tây", "ha tay"}, // decomposed input
+		{"ha tay", "ha tay"},
+	}
+	for _, tt := range tests {
+		if got := Fold(tt.in); got != tt.want {
+			t.Errorf("Fold(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestTokens(t *testing.T) {
+	got := Tokens("Xã Đường Lâm, (tỉnh Hà Tây cũ) - 2025")
+	want := []string{"xa", "duong", "lam", "tinh", "ha", "tay", "cu", "2025"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Tokens() = %v, want %v", got, want)
+	}
+}
+
+func TestSimilarity(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want float64
+	}{
+		{"Hoàn Kiếm", "hoan kiem", 1},
+		{"Hoàn Kiếm", "Hoan Kiemm", 0.75},
+		{"Ba Đình", "Ba Dinh", 1},
+		{"Ba Đình", "Cầu Giấy", 0},
+	}
+	for _, tt := range tests {
+		if got := Similarity(tt.a, tt.b); got != tt.want {
+			t.Errorf("Similarity(%q, %q) = %v, want %v", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
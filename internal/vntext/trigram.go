@@ -0,0 +1,31 @@
+package vntext
+
+// Trigrams returns the trigram set of s the way pg_trgm builds it: s is folded and split
+// into words, each word is padded with two leading spaces and one trailing space.
+func Trigrams(s string) map[string]struct{} {
+	set := make(map[string]struct{})
+	for _, word := range Tokens(s) {
+		padded := []rune("  " + word + " ")
+		for i := 0; i+3 <= len(padded); i++ {
+			set[string(padded[i:i+3])] = struct{}{}
+		}
+	}
+	return set
+}
+
+// Similarity returns the pg_trgm similarity of the accent-folded strings:
+// shared trigrams divided by the size of the union, in [0, 1].
+// It matches similarity(vn_fold(a), vn_fold(b)) in Postgres.
+func Similarity(a, b string) float64 {
+	ta, tb := Trigrams(a), Trigrams(b)
+	if len(ta) == 0 || len(tb) == 0 {
+		return 0
+	}
+	shared := 0
+	for t := range ta {
+		if _, ok := tb[t]; ok {
+			shared++
+		}
+	}
+	return float64(shared) / float64(len(ta)+len(tb)-shared)
+}